@@ -0,0 +1,311 @@
+package main
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/vsl"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("vslctl "+name, flag.ContinueOnError)
+}
+
+func since(seconds uint64) abstract_types.Timestamp {
+	return abstract_types.Timestamp{Seconds: seconds}
+}
+
+// signAndSend calls send with the next nonce of the signing account
+func signAndSend(ctx context.Context, client *vsl.VSLRPCClient, send func(from string, nonce string) error) error {
+	from := client.Signer().Address().Hex()
+	return vsl.NewNonceManager(client).Do(ctx, from, func(nonce uint64) error {
+		return send(from, fmt.Sprintf("%d", nonce))
+	})
+}
+
+func createAccount(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("create-account")
+	owner := flags.String("owner", "", "owner address of the account, defaults to the signing account")
+	script := flags.String("script", "", "script of the account")
+	label := flags.String("label", "", "label of the account")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := c.signingClient(ctx)
+	if err != nil {
+		return err
+	}
+	if *owner == "" {
+		*owner = client.Signer().Address().Hex()
+	}
+	accountId, err := client.CreateAccount(ctx, vsl.CreateAccountParams{
+		OwnerAddress: *owner,
+		Script:       *script,
+		Label:        *label,
+	})
+	if err != nil {
+		return err
+	}
+	return c.printValue("account_id", *accountId)
+}
+
+func pay(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("pay")
+	to := flags.String("to", "", "receiving account")
+	amount := flags.String("amount", "", "amount to pay, decimal or 0x-prefixed hex")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *to == "" {
+		return errors.New("-to is required")
+	}
+	if _, err := vsl.ParseAmount(*amount); err != nil {
+		return err
+	}
+
+	client, err := c.signingClient(ctx)
+	if err != nil {
+		return err
+	}
+	var paymentId *string
+	err = signAndSend(ctx, client, func(from string, nonce string) error {
+		paymentId, err = client.Pay(ctx, vsl.PayParams{
+			From:   from,
+			To:     *to,
+			Amount: *amount,
+			Nonce:  nonce,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return c.printValue("payment_id", *paymentId)
+}
+
+func nonce(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("nonce")
+	account := flags.String("account", "", "account, defaults to the signing account")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	accountId, err := c.accountOrSigner(ctx, *account)
+	if err != nil {
+		return err
+	}
+	nonce, err := c.readClient().GetAccountNonce(ctx, vsl.GetAccountNonceParams{AccountId: accountId})
+	if err != nil {
+		return err
+	}
+	return c.printValue("nonce", *nonce)
+}
+
+func balance(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("balance")
+	account := flags.String("account", "", "account, defaults to the signing account")
+	genesisPath := flags.String("genesis", "", "show the balances of the accounts of a genesis.json file instead")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	accounts := []string{}
+	if *genesisPath != "" {
+		genesis, err := vsl.LoadGenesis(*genesisPath)
+		if err != nil {
+			return err
+		}
+		for _, genesisAccount := range genesis.Accounts {
+			accounts = append(accounts, genesisAccount.Id)
+		}
+	} else {
+		accountId, err := c.accountOrSigner(ctx, *account)
+		if err != nil {
+			return err
+		}
+		accounts = append(accounts, accountId)
+	}
+
+	type accountBalance struct {
+		Account string   `json:"account"`
+		Balance *big.Int `json:"balance"`
+	}
+	balances := []accountBalance{}
+	t := table{headers: []string{"ACCOUNT", "BALANCE"}}
+	for _, accountId := range accounts {
+		balance, err := c.readClient().GetBalance(ctx, vsl.GetBalanceParams{AccountId: accountId})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the balance of %s", accountId)
+		}
+		balances = append(balances, accountBalance{Account: accountId, Balance: balance})
+		t.rows = append(t.rows, []string{accountId, balance.String()})
+	}
+	return c.print(balances, t)
+}
+
+func submitClaim(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("submit-claim")
+	claimType := flags.String("type", "", "claim type")
+	claimFile := flags.String("claim-file", "", "file holding the claim")
+	proofFile := flags.String("proof-file", "", "file holding the proof")
+	to := flags.String("to", "", "comma-separated verifier addresses")
+	quorum := flags.Uint("quorum", 1, "number of verifiers that must settle the claim")
+	fee := flags.String("fee", vsl.DefaultClaimFee, "claim fee, decimal or 0x-prefixed hex")
+	expires := flags.Duration("expires", vsl.DefaultClaimExpiry, "how long the claim waits for settlement")
+	policyPath := flags.String("policy", "", "submission policy JSON file, replaces -to, -quorum, -fee and -expires")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *claimType == "" || *claimFile == "" {
+		return errors.New("-type and -claim-file are required")
+	}
+
+	claim, err := os.ReadFile(*claimFile)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var proof []byte
+	if *proofFile != "" {
+		proof, err = os.ReadFile(*proofFile)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	var policy *vsl.SubmissionPolicy
+	if *policyPath != "" {
+		policy, err = vsl.LoadSubmissionPolicy(*policyPath)
+	} else {
+		policy = &vsl.SubmissionPolicy{
+			DefaultFee:    vsl.FeeRule{Fixed: *fee},
+			ExpirySeconds: uint64(expires.Seconds()),
+			Verifiers:     splitList(*to),
+			Quorum:        uint16(*quorum),
+		}
+		err = policy.Validate()
+	}
+	if err != nil {
+		return err
+	}
+	terms, err := policy.Terms(*claimType, len(proof))
+	if err != nil {
+		return err
+	}
+
+	client, err := c.signingClient(ctx)
+	if err != nil {
+		return err
+	}
+	var claimId *string
+	err = signAndSend(ctx, client, func(from string, nonce string) error {
+		claimId, err = client.SubmitClaim(ctx, vsl.SubmitClaimParams{
+			Claim:     strings.TrimSpace(string(claim)),
+			ClaimType: *claimType,
+			Proof:     strings.TrimSpace(string(proof)),
+			Nonce:     nonce,
+			To:        terms.To,
+			Quorum:    terms.Quorum,
+			From:      from,
+			Expires:   terms.Expires,
+			Fee:       terms.Fee,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return c.printValue("claim_id", *claimId)
+}
+
+func settle(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("settle")
+	claimId := flags.String("claim-id", "", "id of the submitted claim")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *claimId == "" {
+		return errors.New("-claim-id is required")
+	}
+
+	client, err := c.signingClient(ctx)
+	if err != nil {
+		return err
+	}
+	var settledClaimId *string
+	err = signAndSend(ctx, client, func(from string, nonce string) error {
+		settledClaimId, err = client.SettleClaim(ctx, vsl.SettleClaimParams{
+			From:          from,
+			Nonce:         nonce,
+			TargetClaimId: *claimId,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return c.printValue("claim_id", *settledClaimId)
+}
+
+func listSubmitted(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("list-submitted")
+	receiver := flags.String("receiver", "", "receiving verifier, defaults to the signing account")
+	sinceSeconds := flags.Uint64("since", 0, "only list the claims submitted after this unix time")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	address, err := c.accountOrSigner(ctx, *receiver)
+	if err != nil {
+		return err
+	}
+	claims, err := c.readClient().ListSubmittedClaimsForReceiver(ctx, vsl.ListSubmittedClaimsForReceiverParams{
+		Address: address,
+		Since:   since(*sinceSeconds),
+	})
+	if err != nil {
+		return err
+	}
+	return c.print(claims, submittedClaimsTable(claims))
+}
+
+func listSettled(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("list-settled")
+	receiver := flags.String("receiver", "", "claim owner, lists the settled claims of every owner when empty")
+	sinceSeconds := flags.Uint64("since", 0, "only list the claims settled after this unix time")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	claims, err := c.readClient().ListSettledClaimsForReceiver(ctx, vsl.ListSettledClaimsForReceiverParams{
+		Address: *receiver,
+		Since:   since(*sinceSeconds),
+	})
+	if err != nil {
+		return err
+	}
+	return c.print(claims, settledClaimsTable(claims))
+}
+
+func showSettled(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("settled")
+	claimId := flags.String("claim-id", "", "id of the settled claim")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *claimId == "" {
+		return errors.New("-claim-id is required")
+	}
+
+	claim, err := c.readClient().GetSettledClaimById(ctx, vsl.GetSettledClaimByIdParams{ClaimId: *claimId})
+	if err != nil {
+		return err
+	}
+	return c.print(claim, settledClaimsTable([]vsl.TimestampedSettledClaim{*claim}))
+}
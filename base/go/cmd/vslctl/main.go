@@ -0,0 +1,170 @@
+// vslctl drives a VSL node from the terminal: accounts, payments, nonces, balances and claims.
+//
+// Usage:
+//
+//	vslctl [global flags] <command> [command flags]
+//
+// Messages are signed with the key configured by -keystore, or by the VSL_PRIVATE_KEY, VSL_KEYSTORE
+// or VSL_REMOTE_SIGNER_URL (with VSL_ADDRESS) environment variables. The keystore password is read
+// from VSL_KEYSTORE_PASSWORD, or prompted for on the terminal.
+package main
+
+import (
+	"base/pkg/evm"
+	"base/pkg/vsl"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const defaultRPC = "http://localhost:44444"
+
+type command struct {
+	summary string
+	run     func(ctx context.Context, c *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"create-account": {"Create an account owned by an address", createAccount},
+	"pay":            {"Pay an amount to an account", pay},
+	"nonce":          {"Show the nonce of an account", nonce},
+	"balance":        {"Show the balance of an account, or of the genesis accounts", balance},
+	"submit-claim":   {"Submit a claim and its proof read from files", submitClaim},
+	"settle":         {"Settle a submitted claim", settle},
+	"list-submitted": {"List the claims submitted to a receiver", listSubmitted},
+	"list-settled":   {"List the settled claims of a receiver", listSettled},
+	"settled":        {"Show a settled claim", showSettled},
+}
+
+// cli holds the global flags shared by the commands
+type cli struct {
+	rpc          string
+	output       string
+	signerConfig evm.SignerConfig
+	stdout       io.Writer
+
+	client *vsl.VSLRPCClient
+}
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "vslctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	c := &cli{
+		signerConfig: evm.SignerConfigFromEnv("VSL"),
+		stdout:       stdout,
+	}
+
+	flags := flag.NewFlagSet("vslctl", flag.ContinueOnError)
+	flags.StringVar(&c.rpc, "rpc", envOr("VSL_RPC", defaultRPC), "VSL node JSON-RPC endpoint (env VSL_RPC)")
+	flags.StringVar(&c.output, "output", "table", "output format, table or json")
+	flags.StringVar(&c.signerConfig.KeystorePath, "keystore", c.signerConfig.KeystorePath, "encrypted keystore file of the signing account (env VSL_KEYSTORE), unlocked with VSL_KEYSTORE_PASSWORD or a prompt")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: vslctl [global flags] <command> [command flags]\n\nCommands:\n")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(flags.Output(), "  %-16s %s\n", name, commands[name].summary)
+		}
+		fmt.Fprintf(flags.Output(), "\nGlobal flags:\n")
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if c.output != "table" && c.output != "json" {
+		return errors.Errorf("unknown output format %q", c.output)
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		flags.Usage()
+		return errors.Errorf("unknown command %q", name)
+	}
+	return cmd.run(ctx, c, flags.Args()[1:])
+}
+
+// readClient returns a client for commands which don't sign messages
+func (c *cli) readClient() *vsl.VSLRPCClient {
+	if c.client == nil {
+		c.client = vsl.NewVSLRPCClient(c.rpc, nil)
+	}
+	return c.client
+}
+
+// signingClient returns a client signing with the configured key
+func (c *cli) signingClient(ctx context.Context) (*vsl.VSLRPCClient, error) {
+	if c.client != nil && c.client.Signer() != nil {
+		return c.client, nil
+	}
+	// A keystore given on the command line takes precedence over a private key in the environment
+	config := c.signerConfig
+	if config.KeystorePath != "" {
+		config.PrivateKey = ""
+		if config.KeystorePassword == "" {
+			password, err := readPassword(fmt.Sprintf("Password of %s: ", config.KeystorePath))
+			if err != nil {
+				return nil, err
+			}
+			config.KeystorePassword = password
+		}
+	}
+	signer, err := evm.NewSigner(ctx, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the signing key")
+	}
+	c.client = vsl.NewVSLRPCClient(c.rpc, signer)
+	return c.client, nil
+}
+
+// accountOrSigner returns account, or the address of the signing account when empty
+func (c *cli) accountOrSigner(ctx context.Context, account string) (string, error) {
+	if account != "" {
+		return account, nil
+	}
+	client, err := c.signingClient(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "no account given")
+	}
+	return client.Signer().Address().Hex(), nil
+}
+
+func envOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"base/pkg/evm"
+	"base/pkg/vsl"
+	"base/pkg/vsl/vsltest"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func runCommand(t *testing.T, args ...string) string {
+	var stdout bytes.Buffer
+	err := run(context.Background(), args, &stdout)
+	if err != nil {
+		t.Fatalf("vslctl %s: %v", strings.Join(args, " "), err)
+	}
+	return stdout.String()
+}
+
+func TestCommands(t *testing.T) {
+	genesisPath := "../../../../genesis.json"
+	genesis, err := vsl.LoadGenesis(genesisPath)
+	if err != nil {
+		t.Fatalf("Failed to load genesis: %v", err)
+	}
+	node := vsltest.NewNode(t, genesis)
+
+	clientKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	verifierKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	client := evm.NewPrivateKeySigner(clientKey).Address().Hex()
+	verifier := evm.NewPrivateKeySigner(verifierKey).Address().Hex()
+	node.Fund(client, big.NewInt(1000))
+	t.Setenv("VSL_RPC", node.URL)
+	t.Setenv("VSL_PRIVATE_KEY", hexKey(clientKey))
+
+	// Genesis balances, as a table
+	output := runCommand(t, "balance", "-genesis", genesisPath)
+	if !strings.Contains(output, genesis.Accounts[0].Id) || !strings.Contains(output, genesis.Accounts[0].Balance) {
+		t.Fatalf("Unexpected genesis balances:\n%s", output)
+	}
+
+	// Pay from the signing account
+	runCommand(t, "pay", "-to", verifier, "-amount", "100")
+	output = runCommand(t, "-output", "json", "balance", "-account", verifier)
+	if !strings.Contains(output, `"balance": 100`) {
+		t.Fatalf("Unexpected balance:\n%s", output)
+	}
+
+	// Submit a claim from files, and list it for the verifier
+	claimPath := filepath.Join(t.TempDir(), "claim.json")
+	err = os.WriteFile(claimPath, []byte(`{"claim":true}`), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write claim: %v", err)
+	}
+	runCommand(t, "submit-claim", "-type", "Test", "-claim-file", claimPath, "-to", verifier)
+	output = runCommand(t, "-output", "json", "list-submitted", "-receiver", verifier)
+	var claims []vsl.TimestampedSubmittedClaim
+	err = json.Unmarshal([]byte(output), &claims)
+	if err != nil {
+		t.Fatalf("Failed to decode claims: %v\n%s", err, output)
+	}
+	if len(claims) != 1 || claims[0].Data.Claim != `{"claim":true}` || claims[0].Data.Nonce != "1" {
+		t.Fatalf("Unexpected claims %+v", claims)
+	}
+
+	// Settle it as the verifier
+	t.Setenv("VSL_PRIVATE_KEY", hexKey(verifierKey))
+	runCommand(t, "settle", "-claim-id", claims[0].Id)
+	output = runCommand(t, "settled", "-claim-id", claims[0].Id)
+	if !strings.Contains(output, verifier) {
+		t.Fatalf("Unexpected settled claim:\n%s", output)
+	}
+}
+
+func hexKey(key *ecdsa.PrivateKey) string {
+	return hex.EncodeToString(crypto.FromECDSA(key))
+}
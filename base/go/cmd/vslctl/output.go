@@ -0,0 +1,77 @@
+package main
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/vsl"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// table is the table rendering of a command result
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// print writes value as indented JSON, or its table rendering
+func (c *cli) print(value any, t table) error {
+	if c.output == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	writer := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// printValue prints a single named value
+func (c *cli) printValue(name string, value any) error {
+	return c.print(map[string]any{name: value}, table{
+		headers: []string{strings.ToUpper(name)},
+		rows:    [][]string{{fmt.Sprint(value)}},
+	})
+}
+
+func submittedClaimsTable(claims []vsl.TimestampedSubmittedClaim) table {
+	t := table{headers: []string{"ID", "TYPE", "FROM", "NONCE", "QUORUM", "FEE", "EXPIRES", "TIMESTAMP"}}
+	for _, claim := range claims {
+		t.rows = append(t.rows, []string{
+			claim.Id,
+			claim.Data.ClaimType,
+			claim.Data.From,
+			claim.Data.Nonce,
+			fmt.Sprintf("%d/%d", claim.Data.Quorum, len(claim.Data.To)),
+			claim.Data.Fee,
+			formatTimestamp(claim.Data.Expires),
+			formatTimestamp(claim.Timestamp),
+		})
+	}
+	return t
+}
+
+func settledClaimsTable(claims []vsl.TimestampedSettledClaim) table {
+	t := table{headers: []string{"ID", "CLAIM ID", "TYPE", "OWNER", "VERIFIERS", "TIMESTAMP"}}
+	for _, claim := range claims {
+		t.rows = append(t.rows, []string{
+			claim.Id,
+			claim.Data.VerifiedClaim.ClaimId,
+			claim.Data.VerifiedClaim.ClaimType,
+			claim.Data.VerifiedClaim.ClaimOwner,
+			strings.Join(claim.Data.Verifiers, ","),
+			formatTimestamp(claim.Timestamp),
+		})
+	}
+	return t
+}
+
+func formatTimestamp(timestamp abstract_types.Timestamp) string {
+	return time.Unix(int64(timestamp.Seconds), int64(timestamp.Nanos)).UTC().Format(time.RFC3339)
+}
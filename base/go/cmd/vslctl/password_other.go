@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package main
+
+import "github.com/pkg/errors"
+
+// readPassword can't prompt without echo on this platform
+func readPassword(prompt string) (string, error) {
+	return "", errors.New("set VSL_KEYSTORE_PASSWORD to unlock the keystore")
+}
@@ -0,0 +1,38 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// readPassword prompts for a password on the terminal without echoing it
+func readPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	termios, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return "", errors.New("no terminal to prompt for the keystore password, set VSL_KEYSTORE_PASSWORD")
+	}
+	noEcho := *termios
+	noEcho.Lflag &^= unix.ECHO
+	noEcho.Lflag |= unix.ICANON | unix.ISIG
+	err = unix.IoctlSetTermios(fd, ioctlSetTermios, &noEcho)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer unix.IoctlSetTermios(fd, ioctlSetTermios, termios)
+
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return strings.TrimRight(password, "\r\n"), nil
+}
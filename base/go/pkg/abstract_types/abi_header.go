@@ -0,0 +1,116 @@
+package abstract_types
+
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// AbiHeader is the ABI encoding of Header, where absent fields are zero. It packs into the header
+// tuple of the claim ABIs, whose components are the fields of Header in order, then the fields
+// added by later forks, then the hash.
+type AbiHeader struct {
+	Header
+	BaseFee          *big.Int
+	WithdrawalsHash  common.Hash
+	BlobGasUsed      *big.Int
+	ExcessBlobGas    *big.Int
+	ParentBeaconRoot common.Hash
+	RequestsHash     common.Hash
+}
+
+// NewAbiHeader returns the ABI encoding of a header
+func NewAbiHeader(header *Header) AbiHeader {
+	encoded := AbiHeader{
+		Header:        *header,
+		BaseFee:       big.NewInt(0),
+		BlobGasUsed:   big.NewInt(0),
+		ExcessBlobGas: big.NewInt(0),
+	}
+	if header.BaseFee != nil {
+		encoded.BaseFee = header.BaseFee
+	}
+	if header.WithdrawalsHash != nil {
+		encoded.WithdrawalsHash = *header.WithdrawalsHash
+	}
+	if header.BlobGasUsed != nil {
+		encoded.BlobGasUsed = header.BlobGasUsed
+	}
+	if header.ExcessBlobGas != nil {
+		encoded.ExcessBlobGas = header.ExcessBlobGas
+	}
+	if header.ParentBeaconRoot != nil {
+		encoded.ParentBeaconRoot = *header.ParentBeaconRoot
+	}
+	if header.RequestsHash != nil {
+		encoded.RequestsHash = *header.RequestsHash
+	}
+	return encoded
+}
+
+// AbiDecodeHeader converts an unpacked header tuple. The header fields added by later forks are
+// present from the fork of the block in the chain config.
+func AbiDecodeHeader(assumptions reflect.Value, chainConfig *params.ChainConfig) *Header {
+	parentHash := assumptions.FieldByName("ParentHash").Interface().([32]uint8)
+	uncleHash := assumptions.FieldByName("UncleHash").Interface().([32]uint8)
+	nonce := assumptions.FieldByName("Nonce").Interface().([8]uint8)
+	root := assumptions.FieldByName("Root").Interface().([32]uint8)
+	miner := assumptions.FieldByName("Coinbase").Interface().(common.Address)
+	txHash := assumptions.FieldByName("TxHash").Interface().([32]uint8)
+	receiptHash := assumptions.FieldByName("ReceiptHash").Interface().([32]uint8)
+	mixDigest := assumptions.FieldByName("MixDigest").Interface().([32]uint8)
+
+	number := assumptions.FieldByName("Number").Interface().(*big.Int)
+	time := assumptions.FieldByName("Time").Interface().(*big.Int)
+	var baseFee, blobGasUsed, excessBlobGas *big.Int
+	var withdrawalsHash, parentBeaconRoot, requestsHash *common.Hash
+	if chainConfig.IsLondon(number) {
+		baseFee = assumptions.FieldByName("BaseFee").Interface().(*big.Int)
+	}
+	if chainConfig.IsShanghai(number, time.Uint64()) {
+		withdrawalsHash = decodedHash(assumptions.FieldByName("WithdrawalsHash"))
+	}
+	if chainConfig.IsCancun(number, time.Uint64()) {
+		blobGasUsed = assumptions.FieldByName("BlobGasUsed").Interface().(*big.Int)
+		excessBlobGas = assumptions.FieldByName("ExcessBlobGas").Interface().(*big.Int)
+		parentBeaconRoot = decodedHash(assumptions.FieldByName("ParentBeaconRoot"))
+	}
+	if chainConfig.IsPrague(number, time.Uint64()) {
+		requestsHash = decodedHash(assumptions.FieldByName("RequestsHash"))
+	}
+	blockHash := assumptions.FieldByName("Hash").Interface().([32]uint8)
+
+	return &Header{
+		ParentHash:  common.BytesToHash(parentHash[:]),
+		UncleHash:   common.BytesToHash(uncleHash[:]),
+		Coinbase:    miner,
+		Root:        common.BytesToHash(root[:]),
+		TxHash:      common.BytesToHash(txHash[:]),
+		ReceiptHash: common.BytesToHash(receiptHash[:]),
+		Bloom:       assumptions.FieldByName("Bloom").Bytes(),
+		Difficulty:  assumptions.FieldByName("Difficulty").Interface().(*big.Int),
+		Number:      number,
+		GasLimit:    assumptions.FieldByName("GasLimit").Interface().(*big.Int),
+		GasUsed:     assumptions.FieldByName("GasUsed").Interface().(*big.Int),
+		Time:        time,
+		Extra:       assumptions.FieldByName("Extra").Bytes(),
+		MixDigest:   common.BytesToHash(mixDigest[:]),
+		Nonce:       nonce,
+
+		BaseFee:          baseFee,
+		WithdrawalsHash:  withdrawalsHash,
+		BlobGasUsed:      blobGasUsed,
+		ExcessBlobGas:    excessBlobGas,
+		ParentBeaconRoot: parentBeaconRoot,
+		RequestsHash:     requestsHash,
+
+		Hash: common.BytesToHash(blockHash[:]),
+	}
+}
+
+func decodedHash(value reflect.Value) *common.Hash {
+	hash := common.Hash(value.Interface().([32]uint8))
+	return &hash
+}
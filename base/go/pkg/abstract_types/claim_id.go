@@ -0,0 +1,19 @@
+package abstract_types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// JSONClaimId returns the id of a JSON encoded claim, the keccak256 hash of its JSON encoding
+func JSONClaimId(claim any) (*string, error) {
+	jsonBytes, err := json.Marshal(claim)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode claim to JSON")
+	}
+	hashString := hex.EncodeToString(crypto.Keccak256(jsonBytes))
+	return &hashString, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
 )
 
 type EVMCall struct {
@@ -57,6 +58,22 @@ type Header struct {
 	Extra       []byte         `json:"extraData"        gencodec:"required"`
 	MixDigest   common.Hash    `json:"mixHash"`
 	Nonce       [8]byte        `json:"nonce"`
+
+	// BaseFee was added by EIP-1559 and is nil in legacy headers
+	BaseFee *big.Int `json:"baseFeePerGas,omitempty"`
+	// WithdrawalsHash was added by EIP-4895 and is nil in pre-Shanghai headers
+	WithdrawalsHash *common.Hash `json:"withdrawalsRoot,omitempty"`
+	// BlobGasUsed was added by EIP-4844 and is nil in pre-Cancun headers
+	BlobGasUsed *big.Int `json:"blobGasUsed,omitempty"`
+	// ExcessBlobGas was added by EIP-4844 and is nil in pre-Cancun headers
+	ExcessBlobGas *big.Int `json:"excessBlobGas,omitempty"`
+	// ParentBeaconRoot was added by EIP-4788 and is nil in pre-Cancun headers
+	ParentBeaconRoot *common.Hash `json:"parentBeaconBlockRoot,omitempty"`
+	// RequestsHash was added by EIP-7685 and is nil in pre-Prague headers
+	RequestsHash *common.Hash `json:"requestsHash,omitempty"`
+
+	// Hash is the claimed hash of the block, which the other fields must hash to
+	Hash common.Hash `json:"hash"`
 }
 
 func (h *Header) ToGethHeader() *types.Header {
@@ -76,5 +93,66 @@ func (h *Header) ToGethHeader() *types.Header {
 		Extra:       h.Extra,
 		MixDigest:   h.MixDigest,
 		Nonce:       h.Nonce,
+
+		BaseFee:          h.BaseFee,
+		WithdrawalsHash:  h.WithdrawalsHash,
+		BlobGasUsed:      optionalUint64(h.BlobGasUsed),
+		ExcessBlobGas:    optionalUint64(h.ExcessBlobGas),
+		ParentBeaconRoot: h.ParentBeaconRoot,
+		RequestsHash:     h.RequestsHash,
+	}
+}
+
+// VerifyHash checks that the fields of the header hash to its claimed block hash
+func (h *Header) VerifyHash() error {
+	hash := h.ToGethHeader().Hash()
+	if hash != h.Hash {
+		return errors.Errorf("header hashes to %s instead of the claimed block hash %s", hash, h.Hash)
+	}
+	return nil
+}
+
+// NewHeader converts a geth header, along with its hash
+func NewHeader(header *types.Header) *Header {
+	return &Header{
+		ParentHash:  header.ParentHash,
+		UncleHash:   header.UncleHash,
+		Coinbase:    header.Coinbase,
+		Root:        header.Root,
+		TxHash:      header.TxHash,
+		ReceiptHash: header.ReceiptHash,
+		Bloom:       header.Bloom[:],
+		Difficulty:  header.Difficulty,
+		Number:      header.Number,
+		GasLimit:    new(big.Int).SetUint64(header.GasLimit),
+		GasUsed:     new(big.Int).SetUint64(header.GasUsed),
+		Time:        new(big.Int).SetUint64(header.Time),
+		Extra:       header.Extra,
+		MixDigest:   header.MixDigest,
+		Nonce:       header.Nonce,
+
+		BaseFee:          header.BaseFee,
+		WithdrawalsHash:  header.WithdrawalsHash,
+		BlobGasUsed:      optionalBigInt(header.BlobGasUsed),
+		ExcessBlobGas:    optionalBigInt(header.ExcessBlobGas),
+		ParentBeaconRoot: header.ParentBeaconRoot,
+		RequestsHash:     header.RequestsHash,
+
+		Hash: header.Hash(),
+	}
+}
+
+func optionalUint64(value *big.Int) *uint64 {
+	if value == nil {
+		return nil
+	}
+	v := value.Uint64()
+	return &v
+}
+
+func optionalBigInt(value *uint64) *big.Int {
+	if value == nil {
+		return nil
 	}
+	return new(big.Int).SetUint64(*value)
 }
@@ -0,0 +1,48 @@
+package abstract_types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MultiProof holds the proofs of several accounts, where every trie node of the account and
+// storage proofs is stored once in Nodes. The trie nodes reference their children by hash, so
+// the proofs are walked from the state root by looking the nodes up by their keccak hash.
+type MultiProof struct {
+	// Accounts are the proven accounts, without AccountProof and StorageProof.Proof nodes
+	Accounts []Account `json:"accounts"`
+	// Nodes are the trie nodes of all the proofs
+	Nodes [][]byte `json:"nodes"`
+}
+
+// NewMultiProof moves the trie nodes of the account and storage proofs into a deduplicated list
+func NewMultiProof(accounts []Account) *MultiProof {
+	multiProof := &MultiProof{
+		Accounts: make([]Account, len(accounts)),
+		Nodes:    [][]byte{},
+	}
+	seen := map[common.Hash]bool{}
+	addNodes := func(nodes [][]byte) {
+		for _, node := range nodes {
+			hash := crypto.Keccak256Hash(node)
+			if !seen[hash] {
+				seen[hash] = true
+				multiProof.Nodes = append(multiProof.Nodes, node)
+			}
+		}
+	}
+
+	for i, account := range accounts {
+		addNodes(account.Proof.AccountProof)
+		account.Proof.AccountProof = [][]byte{}
+		storageProofs := make([]StorageProof, len(account.Proof.StorageProof))
+		for j, storageProof := range account.Proof.StorageProof {
+			addNodes(storageProof.Proof)
+			storageProof.Proof = [][]byte{}
+			storageProofs[j] = storageProof
+		}
+		account.Proof.StorageProof = storageProofs
+		multiProof.Accounts[i] = account
+	}
+	return multiProof
+}
@@ -0,0 +1,33 @@
+package abstract_types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// OverrideAccount holds the fields of an account overridden during a call, in the format of the
+// eth_call override set. State replaces the whole storage of the account while StateDiff replaces
+// single slots, and they can't be set together.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code      *hexutil.Bytes              `json:"code,omitempty"`
+	Balance   *hexutil.Big                `json:"balance,omitempty"`
+	State     map[common.Hash]common.Hash `json:"state,omitempty"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+}
+
+// StateOverride is the set of accounts overridden during a call
+type StateOverride map[common.Address]OverrideAccount
+
+// BlockOverrides holds the fields of the block context overridden during a call, in the format of
+// the eth_call block overrides
+type BlockOverrides struct {
+	Number        *hexutil.Big    `json:"number,omitempty"`
+	Difficulty    *hexutil.Big    `json:"difficulty,omitempty"`
+	Time          *hexutil.Uint64 `json:"time,omitempty"`
+	GasLimit      *hexutil.Uint64 `json:"gasLimit,omitempty"`
+	FeeRecipient  *common.Address `json:"feeRecipient,omitempty"`
+	PrevRandao    *common.Hash    `json:"prevRandao,omitempty"`
+	BaseFeePerGas *hexutil.Big    `json:"baseFeePerGas,omitempty"`
+	BlobBaseFee   *hexutil.Big    `json:"blobBaseFee,omitempty"`
+}
@@ -0,0 +1,236 @@
+package ethrpc
+
+import (
+	"base/pkg/models"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultCacheMemoryMB is the default size of the in-memory part of a Cache
+	DefaultCacheMemoryMB = 256
+
+	cacheKindAccount = "account"
+	cacheKindStorage = "storage"
+	cacheKindCode    = "code"
+	cacheKindWitness = "witness"
+)
+
+// CacheConfig holds the sizes and location of a Cache
+type CacheConfig struct {
+	// MaxMemory is the number of bytes of entries kept in memory, least recently used first evicted
+	MaxMemory uint64
+	// Dir is the directory the entries are also written to, empty to keep them in memory only
+	Dir string
+}
+
+// CacheConfigFromEnv reads a cache config from the environment variables <prefix>_RPC_CACHE_MB and
+// <prefix>_RPC_CACHE_DIR
+func CacheConfigFromEnv(prefix string) (CacheConfig, error) {
+	config := CacheConfig{
+		MaxMemory: DefaultCacheMemoryMB << 20,
+		Dir:       os.Getenv(prefix + "_RPC_CACHE_DIR"),
+	}
+	if size := os.Getenv(prefix + "_RPC_CACHE_MB"); size != "" {
+		value, err := strconv.ParseUint(size, 10, 32)
+		if err != nil {
+			return config, errors.Wrapf(err, "invalid %s_RPC_CACHE_MB", prefix)
+		}
+		config.MaxMemory = value << 20
+	}
+	return config, nil
+}
+
+// Cache is a content-addressed cache of the chain data fetched to generate claims: account and
+// storage proofs keyed by block hash, address and slot, code keyed by code hash and execution
+// witnesses keyed by block hash. The data under a key never changes, so entries are never
+// invalidated. A nil cache caches nothing.
+type Cache struct {
+	memory *lru.SizeConstrainedCache[string, []byte]
+	dir    string
+}
+
+// NewCache creates a cache, with an on-disk store when a directory is configured
+func NewCache(config CacheConfig) (*Cache, error) {
+	if config.Dir != "" {
+		err := os.MkdirAll(config.Dir, 0o755)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return &Cache{
+		memory: lru.NewSizeConstrainedCache[string, []byte](config.MaxMemory),
+		dir:    config.Dir,
+	}, nil
+}
+
+// cacheKey joins the kind of an entry and the hex of its key parts, also its path in the store
+func cacheKey(kind string, parts ...[]byte) string {
+	hexParts := make([]string, len(parts))
+	for i, part := range parts {
+		hexParts[i] = common.Bytes2Hex(part)
+	}
+	return kind + "/" + strings.Join(hexParts, "-")
+}
+
+// get looks up an entry in memory, then on disk
+func (c *Cache) get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	value, ok := c.memory.Get(key)
+	if ok || c.dir == "" {
+		return value, ok
+	}
+	value, err := os.ReadFile(filepath.Join(c.dir, filepath.FromSlash(key)))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Failed to read cache entry %s: %v", key, err)
+		}
+		return nil, false
+	}
+	c.memory.Add(key, value)
+	return value, true
+}
+
+// put adds an entry in memory and on disk. The disk is written to a temporary file renamed into
+// place, so a concurrent reader never reads a partial entry
+func (c *Cache) put(key string, value []byte) {
+	if c == nil {
+		return
+	}
+	c.memory.Add(key, value)
+	if c.dir == "" {
+		return
+	}
+	path := filepath.Join(c.dir, filepath.FromSlash(key))
+	err := writeFileAtomic(path, value)
+	if err != nil {
+		log.Printf("Failed to write cache entry %s: %v", key, err)
+	}
+}
+
+func writeFileAtomic(path string, value []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(value)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (c *Cache) getJSON(key string, value interface{}) bool {
+	encoded, ok := c.get(key)
+	if !ok {
+		return false
+	}
+	err := json.Unmarshal(encoded, value)
+	if err != nil {
+		log.Printf("Failed to decode cache entry %s: %v", key, err)
+		return false
+	}
+	return true
+}
+
+func (c *Cache) putJSON(key string, value interface{}) {
+	if c == nil {
+		return
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Failed to encode cache entry %s: %v", key, err)
+		return
+	}
+	c.put(key, encoded)
+}
+
+// Code returns the code of a code hash
+func (c *Cache) Code(codeHash common.Hash) ([]byte, bool) {
+	code, ok := c.get(cacheKey(cacheKindCode, codeHash[:]))
+	return common.CopyBytes(code), ok
+}
+
+// PutCode adds code under its code hash
+func (c *Cache) PutCode(code []byte) {
+	codeHash := crypto.Keccak256Hash(code)
+	c.put(cacheKey(cacheKindCode, codeHash[:]), common.CopyBytes(code))
+}
+
+// Witness returns the execution witness of a block
+func (c *Cache) Witness(blockHash common.Hash) (*models.GethWitness, bool) {
+	var witness models.GethWitness
+	if !c.getJSON(cacheKey(cacheKindWitness, blockHash[:]), &witness) {
+		return nil, false
+	}
+	return &witness, true
+}
+
+// PutWitness adds the execution witness of a block
+func (c *Cache) PutWitness(blockHash common.Hash, witness *models.GethWitness) {
+	c.putJSON(cacheKey(cacheKindWitness, blockHash[:]), witness)
+}
+
+// atBlock returns the view of the proofs cached at a block, nil for a nil cache
+func (c *Cache) atBlock(blockHash common.Hash) *blockCache {
+	if c == nil {
+		return nil
+	}
+	return &blockCache{cache: c, blockHash: blockHash}
+}
+
+// blockCache is the view of the proofs cached at a block
+type blockCache struct {
+	cache     *Cache
+	blockHash common.Hash
+}
+
+// proof returns the cached proof of an account with the cached proofs of its storage keys, nil when
+// the account proof is not cached, and the storage keys which are not cached
+func (b *blockCache) proof(address common.Address, storageKeys []string) (*models.EVMProof, []string) {
+	var proof models.EVMProof
+	if !b.cache.getJSON(cacheKey(cacheKindAccount, b.blockHash[:], address[:]), &proof) {
+		return nil, storageKeys
+	}
+	missing := []string{}
+	for _, storageKey := range storageKeys {
+		slot := common.HexToHash(storageKey)
+		var storageProof models.EVMStorageProof
+		if b.cache.getJSON(cacheKey(cacheKindStorage, b.blockHash[:], address[:], slot[:]), &storageProof) {
+			proof.StorageProof = append(proof.StorageProof, storageProof)
+		} else {
+			missing = append(missing, storageKey)
+		}
+	}
+	return &proof, missing
+}
+
+// putProof adds the proof of an account and of each of its storage keys
+func (b *blockCache) putProof(address common.Address, proof *models.EVMProof) {
+	accountProof := *proof
+	accountProof.StorageProof = nil
+	b.cache.putJSON(cacheKey(cacheKindAccount, b.blockHash[:], address[:]), &accountProof)
+	for _, storageProof := range proof.StorageProof {
+		slot := common.HexToHash(storageProof.Key)
+		b.cache.putJSON(cacheKey(cacheKindStorage, b.blockHash[:], address[:], slot[:]), &storageProof)
+	}
+}
@@ -0,0 +1,63 @@
+package ethrpc
+
+import (
+	"base/pkg/models"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGetProofsByAccessListAtHashCached(t *testing.T) {
+	accessList := []models.EVMAccessList{}
+	for i := 1; i <= 4; i++ {
+		accessList = append(accessList, models.EVMAccessList{Address: common.BigToAddress(big.NewInt(int64(i))), StorageKeys: []string{"0x01"}})
+	}
+	client, service := newTestProofClient(t, nil)
+	cache, err := NewCache(CacheConfig{MaxMemory: 1 << 20, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	ctx := context.Background()
+	blockHash := common.HexToHash("0x01")
+
+	proofs, err := GetProofsByAccessListAtHash(client, ctx, accessList, blockHash, DefaultProofFetchConfig(), cache)
+	if err != nil {
+		t.Fatalf("Failed to get proofs: %v", err)
+	}
+	if service.calls["eth_getProof"] != len(accessList) || service.calls["eth_getCode"] != len(accessList) {
+		t.Fatalf("Expected every account to be fetched, got %v", service.calls)
+	}
+
+	// The same accounts at the same block are read from the cache, a new storage key is fetched
+	accessList[0].StorageKeys = append(accessList[0].StorageKeys, "0x02")
+	cached, err := GetProofsByAccessListAtHash(client, ctx, accessList, blockHash, DefaultProofFetchConfig(), cache)
+	if err != nil {
+		t.Fatalf("Failed to get cached proofs: %v", err)
+	}
+	if service.calls["eth_getProof"] != len(accessList)+1 || service.calls["eth_getCode"] != len(accessList) {
+		t.Fatalf("Expected only the new storage key to be fetched, got %v", service.calls)
+	}
+	for i, proof := range cached {
+		if proof.Proof.Addr != proofs[i].Proof.Addr || common.BytesToAddress(proof.Code) != accessList[i].Address || len(proof.Proof.StorageProof) != len(accessList[i].StorageKeys) {
+			t.Fatalf("Unexpected cached proof %d: %+v", i, proof)
+		}
+	}
+	if proof := cached[0].Proof.StorageProof; proof[0].Key != common.HexToHash("0x01") || proof[1].Key != common.HexToHash("0x02") {
+		t.Fatalf("Expected the storage proofs in the order of the access list, got %+v", proof)
+	}
+
+	// At another block only the proofs are fetched, the code is read from the disk by a new cache
+	cache, err = NewCache(CacheConfig{MaxMemory: 1 << 20, Dir: cache.dir})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	_, err = GetProofsByAccessListAtHash(client, ctx, accessList, common.HexToHash("0x02"), DefaultProofFetchConfig(), cache)
+	if err != nil {
+		t.Fatalf("Failed to get proofs: %v", err)
+	}
+	if service.calls["eth_getProof"] != 2*len(accessList)+1 || service.calls["eth_getCode"] != len(accessList) {
+		t.Fatalf("Expected the code to be read from the disk, got %v", service.calls)
+	}
+}
@@ -5,17 +5,21 @@ import (
 	"base/pkg/models"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/pkg/errors"
 	"github.com/tidwall/gjson"
 )
 
+// errorCodeMethodNotFound is the JSON-RPC error code of a method the node doesn't serve
+const errorCodeMethodNotFound = -32601
+
 func GetTransactionByHash(client *rpc.Client, ctx context.Context, txHash string) (*models.EVMTransaction, error) {
 	resultJSON, err := CallContextWithJSONResponse(client, ctx, "eth_getTransactionByHash", txHash)
 	if err != nil {
@@ -31,17 +35,68 @@ func GetTransactionByHash(client *rpc.Client, ctx context.Context, txHash string
 }
 
 func GetTransactionReceiptByHash(client *rpc.Client, ctx context.Context, txHash string) (*models.EVMTransactionReceipt, error) {
-	resultJSON, err := CallContextWithJSONResponse(client, ctx, "eth_getTransactionReceipt", txHash)
+	var txReceipt *models.EVMTransactionReceipt
+	err := client.CallContext(ctx, &txReceipt, "eth_getTransactionReceipt", txHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction receipt by hash: %v", err)
 	}
+	if txReceipt == nil {
+		return nil, fmt.Errorf("transaction receipt of %s not found", txHash)
+	}
+	return txReceipt, nil
+}
+
+// GetBlockReceipts gets the receipts of a block by number, tag or hash with eth_getBlockReceipts,
+// falling back to eth_getTransactionReceipt calls for the transactions of the block on nodes
+// without eth_getBlockReceipts, which answer it with a method not found error
+func GetBlockReceipts(client *rpc.Client, ctx context.Context, blockNumberOrHash string) ([]*models.EVMTransactionReceipt, error) {
+	var receipts []*models.EVMTransactionReceipt
+	err := client.CallContext(ctx, &receipts, "eth_getBlockReceipts", blockNumberOrHash)
+	if err == nil {
+		if receipts == nil {
+			return nil, fmt.Errorf("block %s not found", blockNumberOrHash)
+		}
+		return receipts, nil
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errorCodeMethodNotFound {
+		return nil, fmt.Errorf("failed to get block receipts: %v", err)
+	}
+
+	// The node doesn't serve eth_getBlockReceipts, get the receipts of the transactions of the block
+	method := "eth_getBlockByNumber"
+	if len(blockNumberOrHash) == 2+2*common.HashLength {
+		method = "eth_getBlockByHash"
+	}
+	var block *struct {
+		Transactions []common.Hash `json:"transactions"`
+	}
+	err = client.CallContext(ctx, &block, method, blockNumberOrHash, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block transactions: %v", err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockNumberOrHash)
+	}
 
-	var txReceipt models.EVMTransactionReceipt
-	err = json.Unmarshal([]byte(*resultJSON), &txReceipt)
+	receipts = make([]*models.EVMTransactionReceipt, len(block.Transactions))
+	batch := make([]rpc.BatchElem, len(block.Transactions))
+	for i, txHash := range block.Transactions {
+		batch[i] = rpc.BatchElem{Method: "eth_getTransactionReceipt", Args: []interface{}{txHash}, Result: &receipts[i]}
+	}
+	err = client.BatchCallContext(ctx, batch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal transaction receipt: %v", err)
+		return nil, fmt.Errorf("failed to get transaction receipts: %v", err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("failed to get transaction receipt of %s: %v", block.Transactions[i], elem.Error)
+		}
+		if receipts[i] == nil {
+			return nil, fmt.Errorf("transaction receipt of %s not found", block.Transactions[i])
+		}
 	}
-	return &txReceipt, nil
+	return receipts, nil
 }
 
 func CreateAccessList(client *rpc.Client, ctx context.Context, tx map[string]interface{}, blockNumber *big.Int) ([]models.EVMAccessList, *string, error) {
@@ -60,6 +115,46 @@ func CreateAccessList(client *rpc.Client, ctx context.Context, tx map[string]int
 	return accessList, &gasUnsigned, nil
 }
 
+// CallWithOverrides calls a contract at a block under an eth_call state and block override set
+func CallWithOverrides(client *rpc.Client, ctx context.Context, tx map[string]interface{}, blockNumber *big.Int, stateOverrides abstract_types.StateOverride, blockOverrides *abstract_types.BlockOverrides) ([]byte, error) {
+	var result hexutil.Bytes
+	err := client.CallContext(ctx, &result, "eth_call", tx, hexutil.EncodeBig(blockNumber), stateOverrides, blockOverrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call with overrides: %v", err)
+	}
+	return result, nil
+}
+
+// CreateAccessListWithOverrides returns the accounts and storage slots touched by a call under an
+// eth_call override set, from the prestate tracer of debug_traceCall
+func CreateAccessListWithOverrides(client *rpc.Client, ctx context.Context, tx map[string]interface{}, blockNumber *big.Int, stateOverrides abstract_types.StateOverride, blockOverrides *abstract_types.BlockOverrides) ([]models.EVMAccessList, error) {
+	var prestate map[common.Address]struct {
+		Storage map[common.Hash]common.Hash `json:"storage"`
+	}
+	err := client.CallContext(ctx, &prestate, "debug_traceCall", tx, hexutil.EncodeBig(blockNumber), map[string]interface{}{
+		"tracer":         "prestateTracer",
+		"stateOverrides": stateOverrides,
+		"blockOverrides": blockOverrides,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace call with overrides: %v", err)
+	}
+
+	accessList := make([]models.EVMAccessList, 0, len(prestate))
+	for address, account := range prestate {
+		storageKeys := make([]string, 0, len(account.Storage))
+		for slot := range account.Storage {
+			storageKeys = append(storageKeys, slot.Hex())
+		}
+		sort.Strings(storageKeys)
+		accessList = append(accessList, models.EVMAccessList{Address: address, StorageKeys: storageKeys})
+	}
+	sort.Slice(accessList, func(i, j int) bool {
+		return accessList[i].Address.Cmp(accessList[j].Address) < 0
+	})
+	return accessList, nil
+}
+
 func GetBlockByNumber(client *rpc.Client, ctx context.Context, blockNumber string) (*models.EVMBlock, *types.Header, error) {
 	resultJSON, err := CallContextWithJSONResponse(client, ctx, "eth_getBlockByNumber", blockNumber, false)
 	if err != nil {
@@ -90,6 +185,11 @@ func GetProof(client *rpc.Client, ctx context.Context, address common.Address, s
 		return nil, fmt.Errorf("failed to unmarshal proof: %v", err)
 	}
 
+	return newAccountProof(&proof)
+}
+
+// newAccountProof decodes the result of eth_getProof
+func newAccountProof(proof *models.EVMProof) (*abstract_types.AccountProof, error) {
 	storageProof := make([]abstract_types.StorageProof, len(proof.StorageProof))
 	for j, storage := range proof.StorageProof {
 		proofBytes := make([][]byte, len(storage.Proof))
@@ -108,10 +208,19 @@ func GetProof(client *rpc.Client, ctx context.Context, address common.Address, s
 		accountProof[j] = common.FromHex(proof)
 	}
 
+	balance, err := hexutil.DecodeBig(proof.Balance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance %s of %s: %v", proof.Balance, proof.Address, err)
+	}
+	nonce, err := hexutil.DecodeBig(proof.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce %s of %s: %v", proof.Nonce, proof.Address, err)
+	}
+
 	return &abstract_types.AccountProof{
 		Addr:         common.HexToAddress(proof.Address),
-		Balance:      hexutil.MustDecodeBig(proof.Balance),
-		Nonce:        hexutil.MustDecodeBig(proof.Nonce),
+		Balance:      balance,
+		Nonce:        nonce,
 		CodeHash:     common.HexToHash(proof.CodeHash),
 		AccountProof: accountProof,
 		StorageProof: storageProof,
@@ -154,25 +263,43 @@ func Call(client *rpc.Client, ctx context.Context, tx map[string]interface{}, bl
 	return result, nil
 }
 
-// GetProofsByAccessList gets the proofs for a list of access lists
+// GetProofsByAccessList gets the proofs for a list of access lists, see GetProofsByAccessListWithConfig
 func GetProofsByAccessList(client *rpc.Client, ctx context.Context, accessList []models.EVMAccessList, blockNumber *big.Int) ([]abstract_types.Account, error) {
-	proofs := make([]abstract_types.Account, len(accessList))
-	for i, v := range accessList {
-		blockNumberHex := hexutil.EncodeBig(blockNumber)
-		proof, err := GetProof(client, ctx, v.Address, v.StorageKeys, blockNumberHex)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
+	return GetProofsByAccessListWithConfig(client, ctx, accessList, blockNumber, DefaultProofFetchConfig())
+}
 
-		code, err := GetCode(client, ctx, v.Address, blockNumberHex)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
+// GetMultiProofByAccessList gets the proofs for a list of access lists, with every trie node stored once
+func GetMultiProofByAccessList(client *rpc.Client, ctx context.Context, accessList []models.EVMAccessList, blockNumber *big.Int) (*abstract_types.MultiProof, error) {
+	accounts, err := GetProofsByAccessList(client, ctx, accessList, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return abstract_types.NewMultiProof(accounts), nil
+}
 
-		proofs[i] = abstract_types.Account{
-			Proof: *proof,
-			Code:  common.FromHex(code),
-		}
+// GetMultiProofByAccessListAtHash gets the proofs for a list of access lists at a block by hash,
+// with every trie node stored once, see GetProofsByAccessListAtHash
+func GetMultiProofByAccessListAtHash(client *rpc.Client, ctx context.Context, accessList []models.EVMAccessList, blockHash common.Hash, cache *Cache) (*abstract_types.MultiProof, error) {
+	accounts, err := GetProofsByAccessListAtHash(client, ctx, accessList, blockHash, DefaultProofFetchConfig(), cache)
+	if err != nil {
+		return nil, err
+	}
+	return abstract_types.NewMultiProof(accounts), nil
+}
+
+// GetExecutionWitness gets the geth execution witness of a block, from the cache when it holds it
+func GetExecutionWitness(client *rpc.Client, ctx context.Context, blockHash common.Hash, cache *Cache) (*models.GethWitness, error) {
+	witness, ok := cache.Witness(blockHash)
+	if ok {
+		return witness, nil
+	}
+	err := client.CallContext(ctx, &witness, "debug_executionWitness", blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution witness: %v", err)
+	}
+	if witness == nil {
+		return nil, fmt.Errorf("no execution witness of block %s", blockHash)
 	}
-	return proofs, nil
+	cache.PutWitness(blockHash, witness)
+	return witness, nil
 }
@@ -0,0 +1,526 @@
+package ethrpc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultHealthCheckInterval is the default interval between two health checks of the endpoints
+	DefaultHealthCheckInterval = 15 * time.Second
+	// DefaultMaxBlockLag is the default number of blocks an endpoint may lag behind the highest head
+	DefaultMaxBlockLag = 8
+
+	healthCheckTimeout = 10 * time.Second
+	// A block not found by the endpoints is asked again this many times, waiting for them to reach it
+	notFoundRetries       = 5
+	notFoundRetryInterval = 2 * time.Second
+)
+
+var (
+	// ErrNoHealthyEndpoint is returned when every endpoint of a MultiClient is down
+	ErrNoHealthyEndpoint = errors.New("no healthy RPC endpoint")
+	// ErrNoQuorum is returned when fewer endpoints than the quorum of a MultiClient agree on a result
+	ErrNoQuorum = errors.New("RPC endpoints do not agree")
+)
+
+// MultiClientConfig holds the endpoints of a MultiClient and how far it trusts them
+type MultiClientConfig struct {
+	// Endpoints are the RPC URLs of the same chain, in order of preference
+	Endpoints []string
+	// Quorum is the number of endpoints that must agree on the chain ID and block headers, 0 or 1
+	// to trust the first healthy endpoint
+	Quorum int
+	// HealthCheckInterval is the interval between two health checks run by Run
+	HealthCheckInterval time.Duration
+	// MaxBlockLag is the number of blocks an endpoint may lag behind the highest head before it is
+	// considered down
+	MaxBlockLag uint64
+}
+
+// MultiClientConfigFromEnv reads a multi client config from the environment variables
+// <prefix>_RPC_ENDPOINTS (comma separated, or a single <prefix>_RPC_ENDPOINT), <prefix>_RPC_QUORUM,
+// <prefix>_RPC_HEALTH_CHECK_SECONDS and <prefix>_RPC_MAX_BLOCK_LAG
+func MultiClientConfigFromEnv(prefix string) (MultiClientConfig, error) {
+	config := MultiClientConfig{
+		HealthCheckInterval: DefaultHealthCheckInterval,
+		MaxBlockLag:         DefaultMaxBlockLag,
+	}
+	config.Endpoints = endpointsFromEnv(prefix + "_RPC_ENDPOINT")
+
+	if quorum := os.Getenv(prefix + "_RPC_QUORUM"); quorum != "" {
+		value, err := strconv.Atoi(quorum)
+		if err != nil {
+			return config, errors.Wrapf(err, "invalid %s_RPC_QUORUM", prefix)
+		}
+		config.Quorum = value
+	}
+	if seconds := os.Getenv(prefix + "_RPC_HEALTH_CHECK_SECONDS"); seconds != "" {
+		value, err := strconv.ParseUint(seconds, 10, 32)
+		if err != nil {
+			return config, errors.Wrapf(err, "invalid %s_RPC_HEALTH_CHECK_SECONDS", prefix)
+		}
+		config.HealthCheckInterval = time.Duration(value) * time.Second
+	}
+	if lag := os.Getenv(prefix + "_RPC_MAX_BLOCK_LAG"); lag != "" {
+		value, err := strconv.ParseUint(lag, 10, 64)
+		if err != nil {
+			return config, errors.Wrapf(err, "invalid %s_RPC_MAX_BLOCK_LAG", prefix)
+		}
+		config.MaxBlockLag = value
+	}
+	return config, nil
+}
+
+// endpointsFromEnv reads the comma separated endpoints of the variable <name>S, or the single endpoint of <name>
+func endpointsFromEnv(name string) []string {
+	value := os.Getenv(name + "S")
+	if value == "" {
+		value = os.Getenv(name)
+	}
+	var endpoints []string
+	for _, endpoint := range strings.Split(value, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+func (config MultiClientConfig) validate() error {
+	if len(config.Endpoints) == 0 {
+		return errors.New("no RPC endpoint configured")
+	}
+	if config.Quorum > len(config.Endpoints) {
+		return errors.Errorf("quorum %d is over the %d RPC endpoints", config.Quorum, len(config.Endpoints))
+	}
+	return nil
+}
+
+// endpoint is an RPC endpoint of a MultiClient and its last health check
+type endpoint struct {
+	url     string
+	client  *rpc.Client
+	healthy bool
+	head    uint64
+}
+
+// name returns the host of the endpoint, leaving out the paths and queries which often hold API keys
+func (e *endpoint) name() string {
+	parsed, err := url.Parse(e.url)
+	if err != nil || parsed.Host == "" {
+		return "<invalid URL>"
+	}
+	return parsed.Host
+}
+
+// MultiClient is an Ethereum RPC client over several endpoints of the same chain.
+//
+// Calls go to the first healthy endpoint and fail over to the next ones when an endpoint can't be
+// reached, the endpoint being considered down until the next health check. The chain ID and block
+// headers can be cross-checked: they are only returned when at least the quorum of endpoints agree
+// on them, so that one lying or lagging endpoint can't make claims about a non-canonical block.
+type MultiClient struct {
+	config MultiClientConfig
+
+	mu        sync.RWMutex
+	endpoints []*endpoint
+
+	notFoundRetries       int
+	notFoundRetryInterval time.Duration
+}
+
+// DialMultiClient connects to the endpoints of the config and checks their health. It only fails
+// when no endpoint is healthy, the endpoints which are down being dialed again by the health checks.
+func DialMultiClient(ctx context.Context, config MultiClientConfig) (*MultiClient, error) {
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+	clients := make([]*rpc.Client, len(config.Endpoints))
+	for i, endpointURL := range config.Endpoints {
+		client, err := rpc.DialContext(ctx, endpointURL)
+		if err != nil {
+			log.Printf("Failed to dial RPC endpoint %s: %v", (&endpoint{url: endpointURL}).name(), err)
+			continue
+		}
+		clients[i] = client
+	}
+	c, err := newMultiClient(config, clients)
+	if err != nil {
+		return nil, err
+	}
+
+	c.CheckHealth(ctx)
+	if c.healthyEndpoints() == nil {
+		c.Close()
+		return nil, errors.WithStack(ErrNoHealthyEndpoint)
+	}
+	return c, nil
+}
+
+// newMultiClient creates a multi client over connected clients, which are all assumed healthy
+func newMultiClient(config MultiClientConfig, clients []*rpc.Client) (*MultiClient, error) {
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+	if config.HealthCheckInterval <= 0 {
+		config.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+
+	c := &MultiClient{
+		config:                config,
+		notFoundRetries:       notFoundRetries,
+		notFoundRetryInterval: notFoundRetryInterval,
+	}
+	for i, endpointURL := range config.Endpoints {
+		c.endpoints = append(c.endpoints, &endpoint{
+			url:     endpointURL,
+			client:  clients[i],
+			healthy: clients[i] != nil,
+		})
+	}
+	return c, nil
+}
+
+// Close closes the connections to all the endpoints
+func (c *MultiClient) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range c.endpoints {
+		if e.client != nil {
+			e.client.Close()
+		}
+	}
+}
+
+// Run checks the health of the endpoints until the context is done
+func (c *MultiClient) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.config.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			c.CheckHealth(ctx)
+		}
+	}
+}
+
+// CheckHealth gets the head of every endpoint, dialing again the endpoints which failed to connect.
+// An endpoint is healthy when it answers and its head is at most MaxBlockLag blocks behind the
+// highest head.
+func (c *MultiClient) CheckHealth(ctx context.Context) {
+	c.mu.RLock()
+	endpoints := append([]*endpoint{}, c.endpoints...)
+	c.mu.RUnlock()
+
+	clients := make([]*rpc.Client, len(endpoints))
+	heads := make([]uint64, len(endpoints))
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			c.mu.RLock()
+			client := e.client
+			c.mu.RUnlock()
+			if client == nil {
+				client, errs[i] = rpc.DialContext(ctx, e.url)
+				if errs[i] != nil {
+					return
+				}
+			}
+			clients[i] = client
+			var head hexutil.Uint64
+			errs[i] = client.CallContext(ctx, &head, "eth_blockNumber")
+			heads[i] = uint64(head)
+		}(i, e)
+	}
+	wg.Wait()
+
+	highestHead := uint64(0)
+	for i := range endpoints {
+		if errs[i] == nil {
+			highestHead = max(highestHead, heads[i])
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, e := range endpoints {
+		if e.client == nil {
+			e.client = clients[i]
+		}
+		healthy := errs[i] == nil && heads[i]+c.config.MaxBlockLag >= highestHead
+		switch {
+		case healthy && !e.healthy:
+			log.Printf("RPC endpoint %s is up at block %d", e.name(), heads[i])
+		case !healthy && e.healthy && errs[i] != nil:
+			log.Printf("RPC endpoint %s is down: %v", e.name(), errs[i])
+		case !healthy && e.healthy:
+			log.Printf("RPC endpoint %s is down: head %d lags behind block %d", e.name(), heads[i], highestHead)
+		}
+		e.healthy = healthy
+		if errs[i] == nil {
+			e.head = heads[i]
+		}
+	}
+}
+
+// healthyEndpoints returns the healthy endpoints in order of preference, nil if there are none
+func (c *MultiClient) healthyEndpoints() []*endpoint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var endpoints []*endpoint
+	for _, e := range c.endpoints {
+		if e.healthy && e.client != nil {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// lowestHead returns the lowest head of the healthy endpoints at the last health check
+func (c *MultiClient) lowestHead() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	lowest := uint64(math.MaxUint64)
+	for _, e := range c.endpoints {
+		if e.healthy {
+			lowest = min(lowest, e.head)
+		}
+	}
+	return lowest
+}
+
+// markDown marks an endpoint down until the next health check
+func (c *MultiClient) markDown(e *endpoint, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.healthy {
+		log.Printf("RPC endpoint %s is down, failing over: %v", e.name(), err)
+	}
+	e.healthy = false
+}
+
+// Client returns the client of the first healthy endpoint, or of the first connected endpoint when
+// none is healthy. It is meant for the functions taking a single client, the calls of which don't
+// fail over.
+func (c *MultiClient) Client() *rpc.Client {
+	if endpoints := c.healthyEndpoints(); endpoints != nil {
+		return endpoints[0].client
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, e := range c.endpoints {
+		if e.client != nil {
+			return e.client
+		}
+	}
+	return nil
+}
+
+// EthClient returns an eth client of the first healthy endpoint, see Client
+func (c *MultiClient) EthClient() *ethclient.Client {
+	return ethclient.NewClient(c.Client())
+}
+
+// CallContext calls a method on the first healthy endpoint, failing over to the next endpoints while
+// the call can't reach them. Errors returned by an endpoint, like reverted calls, don't fail over.
+func (c *MultiClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return c.failover(ctx, func(client *rpc.Client) error {
+		return client.CallContext(ctx, result, method, args...)
+	})
+}
+
+// BatchCallContext sends a batch to the first healthy endpoint, failing over like CallContext
+func (c *MultiClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	return c.failover(ctx, func(client *rpc.Client) error {
+		return client.BatchCallContext(ctx, b)
+	})
+}
+
+func (c *MultiClient) failover(ctx context.Context, call func(client *rpc.Client) error) error {
+	err := error(ErrNoHealthyEndpoint)
+	for _, e := range c.healthyEndpoints() {
+		err = call(e.client)
+		if err == nil || isEndpointAnswer(err) || ctx.Err() != nil {
+			return err
+		}
+		c.markDown(e, err)
+	}
+	return errors.WithStack(err)
+}
+
+// isEndpointAnswer tells whether an error was returned by the endpoint, rather than by failing to reach it.
+// A block or transaction not found is an answer, the endpoint may not have reached it yet.
+func isEndpointAnswer(err error) bool {
+	var rpcErr rpc.Error
+	var httpErr rpc.HTTPError
+	if errors.Is(err, ethereum.NotFound) {
+		return true
+	}
+	if errors.As(err, &httpErr) {
+		// The endpoint is overloaded or rejects us
+		return false
+	}
+	return errors.As(err, &rpcErr)
+}
+
+// ChainID returns the chain ID agreed on by the quorum of healthy endpoints
+func (c *MultiClient) ChainID(ctx context.Context) (*big.Int, error) {
+	chainId, err := c.agree(ctx, "chain ID", func(client *ethclient.Client) (string, interface{}, error) {
+		chainId, err := client.ChainID(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		return chainId.String(), chainId, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chainId.(*big.Int), nil
+}
+
+// HeaderByNumber returns the header of a block agreed on by the quorum of healthy endpoints, the
+// latest header when number is nil. Headers are compared by their hash computed locally. While the
+// endpoints have not reached the block, it is asked again a few times before returning an error
+// wrapping ethereum.NotFound.
+func (c *MultiClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil && c.config.Quorum > 1 {
+		// The endpoints may be at different heads, agree on the lowest head of the last health check
+		number = new(big.Int).SetUint64(c.lowestHead())
+	}
+	for attempt := 0; ; attempt++ {
+		header, err := c.agree(ctx, fmt.Sprintf("header %d", number), func(client *ethclient.Client) (string, interface{}, error) {
+			header, err := client.HeaderByNumber(ctx, number)
+			if err != nil {
+				return "", nil, err
+			}
+			return header.Hash().Hex(), header, nil
+		})
+		if err == nil {
+			return header.(*types.Header), nil
+		}
+		if number == nil || !errors.Is(err, ethereum.NotFound) || attempt >= c.notFoundRetries {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.WithStack(ctx.Err())
+		case <-time.After(c.notFoundRetryInterval):
+		}
+	}
+}
+
+// VerifyBlockHash checks that the quorum of healthy endpoints agree on hash being the canonical block
+// at number, before making a claim about the block
+func (c *MultiClient) VerifyBlockHash(ctx context.Context, number *big.Int, hash common.Hash) error {
+	header, err := c.HeaderByNumber(ctx, number)
+	if err != nil {
+		return err
+	}
+	if header.Hash() != hash {
+		return errors.Errorf("block %s is not canonical, the RPC endpoints agree on block %s at height %s", hash, header.Hash(), number)
+	}
+	return nil
+}
+
+// agree calls every healthy endpoint and returns the result returned by most of them, if at least
+// the quorum of them returned it. With a quorum of 1, only the first answering endpoint is called.
+// When the quorum is only missed by endpoints which did not find the result, the error wraps
+// ethereum.NotFound: they may not have reached it yet.
+func (c *MultiClient) agree(ctx context.Context, what string, call func(client *ethclient.Client) (string, interface{}, error)) (interface{}, error) {
+	endpoints := c.healthyEndpoints()
+	if c.config.Quorum <= 1 {
+		var result interface{}
+		err := c.failover(ctx, func(client *rpc.Client) error {
+			var err error
+			_, result, err = call(ethclient.NewClient(client))
+			return err
+		})
+		return result, err
+	}
+	if len(endpoints) < c.config.Quorum {
+		return nil, errors.Wrapf(ErrNoQuorum, "%d healthy endpoints for a quorum of %d", len(endpoints), c.config.Quorum)
+	}
+
+	keys := make([]string, len(endpoints))
+	results := make([]interface{}, len(endpoints))
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			keys[i], results[i], errs[i] = call(ethclient.NewClient(e.client))
+		}(i, e)
+	}
+	wg.Wait()
+
+	votes := map[string]int{}
+	var best string
+	notFound := 0
+	for i, e := range endpoints {
+		if errors.Is(errs[i], ethereum.NotFound) {
+			notFound++
+		}
+		if errs[i] != nil {
+			if !isEndpointAnswer(errs[i]) && ctx.Err() == nil {
+				c.markDown(e, errs[i])
+			}
+			continue
+		}
+		votes[keys[i]]++
+		if votes[keys[i]] > votes[best] {
+			best = keys[i]
+		}
+	}
+	if votes[best] >= c.config.Quorum {
+		for i := range endpoints {
+			if errs[i] == nil && keys[i] == best {
+				return results[i], nil
+			}
+		}
+	}
+
+	// Report the answers, for finding the lying or lagging endpoints
+	answers := []string{}
+	for i, e := range endpoints {
+		if errs[i] != nil {
+			answers = append(answers, fmt.Sprintf("%s: %v", e.name(), errs[i]))
+		} else {
+			answers = append(answers, fmt.Sprintf("%s: %s", e.name(), keys[i]))
+		}
+	}
+	sort.Strings(answers)
+	if votes[best]+notFound >= c.config.Quorum {
+		return nil, errors.Wrapf(ethereum.NotFound, "%s has %d of the %d required votes, not found by %d endpoints (%s)", what, votes[best], c.config.Quorum, notFound, strings.Join(answers, ", "))
+	}
+	return nil, errors.Wrapf(ErrNoQuorum, "%s has %d of the %d required votes (%s)", what, votes[best], c.config.Quorum, strings.Join(answers, ", "))
+}
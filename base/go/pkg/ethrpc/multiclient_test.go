@@ -0,0 +1,187 @@
+package ethrpc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testChainService serves the chain ID and the headers up to its head, a lying service serving
+// headers of another extra data
+type testChainService struct {
+	chainId uint64
+	head    uint64
+	extra   []byte
+}
+
+func (s *testChainService) ChainId() hexutil.Uint64 {
+	return hexutil.Uint64(s.chainId)
+}
+
+func (s *testChainService) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(s.head)
+}
+
+func (s *testChainService) GetBlockByNumber(number rpc.BlockNumber, fullTransactions bool) (*types.Header, error) {
+	if number < 0 {
+		number = rpc.BlockNumber(s.head)
+	}
+	if uint64(number) > s.head {
+		return nil, nil
+	}
+	return &types.Header{Number: big.NewInt(number.Int64()), Difficulty: big.NewInt(0), Extra: s.extra}, nil
+}
+
+func newTestMultiClient(t *testing.T, quorum int, services ...*testChainService) (*MultiClient, []*rpc.Client) {
+	endpoints := []string{}
+	clients := []*rpc.Client{}
+	for _, service := range services {
+		server := rpc.NewServer()
+		err := server.RegisterName("eth", service)
+		if err != nil {
+			t.Fatalf("Failed to register service: %v", err)
+		}
+		t.Cleanup(server.Stop)
+		endpoints = append(endpoints, "http://endpoint.invalid")
+		clients = append(clients, rpc.DialInProc(server))
+	}
+	client, err := newMultiClient(MultiClientConfig{Endpoints: endpoints, Quorum: quorum, MaxBlockLag: 2}, clients)
+	if err != nil {
+		t.Fatalf("Failed to create multi client: %v", err)
+	}
+	return client, clients
+}
+
+func TestMultiClientQuorum(t *testing.T) {
+	honest := &testChainService{chainId: 1, head: 10}
+	liar := &testChainService{chainId: 1, head: 10, extra: []byte("forked")}
+	client, _ := newTestMultiClient(t, 2, liar, honest, honest)
+	ctx := context.Background()
+
+	expected, _ := honest.GetBlockByNumber(5, false)
+	header, err := client.HeaderByNumber(ctx, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("Failed to get header: %v", err)
+	}
+	if header.Hash() != expected.Hash() {
+		t.Fatalf("Expected the header agreed on by the quorum, got %s", header.Hash())
+	}
+	forked, _ := liar.GetBlockByNumber(5, false)
+	err = client.VerifyBlockHash(ctx, big.NewInt(5), forked.Hash())
+	if err == nil {
+		t.Fatalf("Expected the block of the lying endpoint to be rejected")
+	}
+	err = client.VerifyBlockHash(ctx, big.NewInt(5), expected.Hash())
+	if err != nil {
+		t.Fatalf("Failed to verify canonical block: %v", err)
+	}
+
+	// The honest endpoints alone don't reach a quorum of 3
+	client, _ = newTestMultiClient(t, 3, liar, honest, honest)
+	_, err = client.HeaderByNumber(ctx, big.NewInt(5))
+	if !errors.Is(err, ErrNoQuorum) {
+		t.Fatalf("Expected no quorum, got %v", err)
+	}
+	chainId, err := client.ChainID(ctx)
+	if err != nil || chainId.Uint64() != 1 {
+		t.Fatalf("Expected the agreed chain ID, got %v, %v", chainId, err)
+	}
+}
+
+func TestMultiClientFailover(t *testing.T) {
+	first := &testChainService{chainId: 1, head: 10}
+	second := &testChainService{chainId: 1, head: 10}
+	client, clients := newTestMultiClient(t, 1, first, second)
+	ctx := context.Background()
+
+	clients[0].Close()
+	var chainId hexutil.Uint64
+	err := client.CallContext(ctx, &chainId, "eth_chainId")
+	if err != nil {
+		t.Fatalf("Expected the call to fail over, got %v", err)
+	}
+	if client.Client() != clients[1] {
+		t.Fatalf("Expected the closed endpoint to be down")
+	}
+
+	// Errors answered by the endpoint don't fail over
+	err = client.CallContext(ctx, nil, "eth_unknownMethod")
+	if err == nil || client.Client() != clients[1] {
+		t.Fatalf("Expected the answered error to be returned, got %v", err)
+	}
+
+	clients[1].Close()
+	err = client.CallContext(ctx, &chainId, "eth_chainId")
+	if err == nil {
+		t.Fatalf("Expected the call to fail on the last endpoint")
+	}
+	err = client.CallContext(ctx, &chainId, "eth_chainId")
+	if !errors.Is(err, ErrNoHealthyEndpoint) {
+		t.Fatalf("Expected no healthy endpoint, got %v", err)
+	}
+}
+
+func TestMultiClientCheckHealth(t *testing.T) {
+	lagging := &testChainService{chainId: 1, head: 5}
+	synced := &testChainService{chainId: 1, head: 10}
+	client, clients := newTestMultiClient(t, 1, lagging, synced)
+
+	client.CheckHealth(context.Background())
+	if client.Client() != clients[1] {
+		t.Fatalf("Expected the lagging endpoint to be down")
+	}
+	lagging.head = 9
+	client.CheckHealth(context.Background())
+	if client.Client() != clients[0] {
+		t.Fatalf("Expected the endpoint to be up once synced")
+	}
+
+	header, err := client.HeaderByNumber(context.Background(), nil)
+	if err != nil || header.Number.Uint64() != 9 {
+		t.Fatalf("Expected the head of the first endpoint, got %v, %v", header, err)
+	}
+}
+
+func TestMultiClientNotFound(t *testing.T) {
+	lagging := &testChainService{chainId: 1, head: 4}
+	synced := &testChainService{chainId: 1, head: 5}
+	client, clients := newTestMultiClient(t, 2, lagging, synced, synced)
+	client.notFoundRetries = 1
+	client.notFoundRetryInterval = time.Millisecond
+	ctx := context.Background()
+
+	// The quorum is still reached without the endpoint which did not reach the block
+	_, err := client.HeaderByNumber(ctx, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("Failed to get header: %v", err)
+	}
+
+	// A block the endpoints did not reach is not found, rather than failing over
+	_, err = client.HeaderByNumber(ctx, big.NewInt(6))
+	if !errors.Is(err, ethereum.NotFound) || errors.Is(err, ErrNoQuorum) {
+		t.Fatalf("Expected the block not to be found, got %v", err)
+	}
+	if endpoints := client.healthyEndpoints(); len(endpoints) != len(clients) {
+		t.Fatalf("Expected the endpoints which did not find the block to stay up, got %d healthy", len(endpoints))
+	}
+	lagging.head, synced.head = 6, 6
+	_, err = client.HeaderByNumber(ctx, big.NewInt(6))
+	if err != nil {
+		t.Fatalf("Expected the block to be found once reached, got %v", err)
+	}
+
+	// With a quorum of 1, not found is answered by the first endpoint
+	client, _ = newTestMultiClient(t, 1, lagging, synced)
+	client.notFoundRetries = 0
+	_, err = client.HeaderByNumber(ctx, big.NewInt(7))
+	if !errors.Is(err, ethereum.NotFound) || client.Client() != client.endpoints[0].client {
+		t.Fatalf("Expected the block not to be found by the first endpoint, got %v", err)
+	}
+}
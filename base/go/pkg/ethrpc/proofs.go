@@ -0,0 +1,278 @@
+package ethrpc
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/models"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultProofBatchSize is the default number of accounts fetched in one JSON-RPC batch
+	DefaultProofBatchSize = 16
+	// DefaultProofConcurrency is the default number of batches in flight
+	DefaultProofConcurrency = 4
+	// DefaultProofMaxRetries is the default number of retries of a failed call
+	DefaultProofMaxRetries = 5
+	// DefaultProofRetryBackoff is the default wait before the first retry, doubled on every retry
+	DefaultProofRetryBackoff = 250 * time.Millisecond
+
+	maxProofRetryBackoff = 10 * time.Second
+)
+
+// ProofFetchConfig holds the batching, concurrency and retry settings of fetching account proofs
+type ProofFetchConfig struct {
+	// BatchSize is the number of accounts whose eth_getProof and eth_getCode calls are sent in one batch
+	BatchSize int
+	// Concurrency is the number of batches in flight
+	Concurrency int
+	// MaxRetries is the number of retries of a failed call, only the failed calls of a batch are retried
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled on every retry
+	RetryBackoff time.Duration
+}
+
+// DefaultProofFetchConfig returns the config used by GetProofsByAccessList
+func DefaultProofFetchConfig() ProofFetchConfig {
+	return ProofFetchConfig{
+		BatchSize:    DefaultProofBatchSize,
+		Concurrency:  DefaultProofConcurrency,
+		MaxRetries:   DefaultProofMaxRetries,
+		RetryBackoff: DefaultProofRetryBackoff,
+	}
+}
+
+// GetProofsByAccessListWithConfig gets the proofs and code of the accounts of an access list. The
+// eth_getProof and eth_getCode calls are sent in JSON-RPC batches, several batches at a time, and
+// every failed call is retried on its own with exponential backoff.
+func GetProofsByAccessListWithConfig(client *rpc.Client, ctx context.Context, accessList []models.EVMAccessList, blockNumber *big.Int, config ProofFetchConfig) ([]abstract_types.Account, error) {
+	return getProofs(client, ctx, accessList, hexutil.EncodeBig(blockNumber), config, nil)
+}
+
+// GetProofsByAccessListAtHash gets the proofs and code of the accounts of an access list at a block
+// by hash, like GetProofsByAccessListWithConfig. The account and storage proofs and the code found
+// in the cache are not fetched again, and the fetched ones are added to it.
+func GetProofsByAccessListAtHash(client *rpc.Client, ctx context.Context, accessList []models.EVMAccessList, blockHash common.Hash, config ProofFetchConfig, cache *Cache) ([]abstract_types.Account, error) {
+	return getProofs(client, ctx, accessList, rpc.BlockNumberOrHashWithHash(blockHash, false), config, cache.atBlock(blockHash))
+}
+
+func getProofs(client *rpc.Client, ctx context.Context, accessList []models.EVMAccessList, block interface{}, config ProofFetchConfig, cache *blockCache) ([]abstract_types.Account, error) {
+	batchSize := max(config.BatchSize, 1)
+	concurrency := max(config.Concurrency, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	proofs := make([]abstract_types.Account, len(accessList))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	for start := 0; start < len(accessList); start += batchSize {
+		end := min(start+batchSize, len(accessList))
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			var err error
+			if cache == nil {
+				err = fetchProofBatch(client, ctx, accessList[start:end], block, config, proofs[start:end])
+			} else {
+				err = fetchCachedProofBatch(client, ctx, accessList[start:end], block, config, cache, proofs[start:end])
+			}
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if ctx.Err() != nil {
+		return nil, errors.WithStack(ctx.Err())
+	}
+	return proofs, nil
+}
+
+// fetchProofBatch fetches the proofs and code of a batch of accounts into proofs
+func fetchProofBatch(client *rpc.Client, ctx context.Context, accessList []models.EVMAccessList, block interface{}, config ProofFetchConfig, proofs []abstract_types.Account) error {
+	evmProofs := make([]models.EVMProof, len(accessList))
+	codes := make([]hexutil.Bytes, len(accessList))
+	pending := make([]rpc.BatchElem, 0, 2*len(accessList))
+	for i, access := range accessList {
+		pending = append(pending,
+			rpc.BatchElem{Method: "eth_getProof", Args: []interface{}{access.Address, nonNilStorageKeys(access.StorageKeys), block}, Result: &evmProofs[i]},
+			rpc.BatchElem{Method: "eth_getCode", Args: []interface{}{access.Address, block}, Result: &codes[i]},
+		)
+	}
+	err := batchCallWithRetries(client, ctx, pending, "proofs", config)
+	if err != nil {
+		return err
+	}
+
+	for i := range accessList {
+		proof, err := newAccountProof(&evmProofs[i])
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		proofs[i] = abstract_types.Account{
+			Proof: *proof,
+			Code:  codes[i],
+		}
+	}
+	return nil
+}
+
+// fetchCachedProofBatch fetches the proofs and code of a batch of accounts into proofs, only
+// calling eth_getProof for the accounts and storage keys missing from the cache, then eth_getCode
+// for the code hashes missing from it
+func fetchCachedProofBatch(client *rpc.Client, ctx context.Context, accessList []models.EVMAccessList, block interface{}, config ProofFetchConfig, cache *blockCache, proofs []abstract_types.Account) error {
+	cached := make([]*models.EVMProof, len(accessList))
+	fetched := make([]models.EVMProof, len(accessList))
+	pending := []rpc.BatchElem{}
+	for i, access := range accessList {
+		storageKeys := nonNilStorageKeys(access.StorageKeys)
+		var missing []string
+		cached[i], missing = cache.proof(access.Address, storageKeys)
+		if cached[i] == nil || len(missing) > 0 {
+			pending = append(pending, rpc.BatchElem{Method: "eth_getProof", Args: []interface{}{access.Address, missing, block}, Result: &fetched[i]})
+		}
+	}
+	err := batchCallWithRetries(client, ctx, pending, "proofs", config)
+	if err != nil {
+		return err
+	}
+
+	// Merge the cached and fetched proofs, the storage proofs in the order of the access list
+	evmProofs := make([]models.EVMProof, len(accessList))
+	for i, access := range accessList {
+		storageProofs := map[common.Hash]models.EVMStorageProof{}
+		if cached[i] != nil {
+			evmProofs[i] = *cached[i]
+			for _, storageProof := range cached[i].StorageProof {
+				storageProofs[common.HexToHash(storageProof.Key)] = storageProof
+			}
+		}
+		if fetched[i].Address != "" {
+			cache.putProof(access.Address, &fetched[i])
+			evmProofs[i] = fetched[i]
+			for _, storageProof := range fetched[i].StorageProof {
+				storageProofs[common.HexToHash(storageProof.Key)] = storageProof
+			}
+		}
+		evmProofs[i].StorageProof = []models.EVMStorageProof{}
+		for _, storageKey := range access.StorageKeys {
+			storageProof, ok := storageProofs[common.HexToHash(storageKey)]
+			if !ok {
+				return errors.Errorf("no proof of storage key %s of %s", storageKey, access.Address)
+			}
+			evmProofs[i].StorageProof = append(evmProofs[i].StorageProof, storageProof)
+		}
+	}
+
+	codes := make([]hexutil.Bytes, len(accessList))
+	pending = pending[:0]
+	for i, access := range accessList {
+		codeHash := common.HexToHash(evmProofs[i].CodeHash)
+		if codeHash == (common.Hash{}) || codeHash == types.EmptyCodeHash {
+			codes[i] = []byte{}
+		} else if code, ok := cache.cache.Code(codeHash); ok {
+			codes[i] = code
+		} else {
+			pending = append(pending, rpc.BatchElem{Method: "eth_getCode", Args: []interface{}{access.Address, block}, Result: &codes[i]})
+		}
+	}
+	err = batchCallWithRetries(client, ctx, pending, "code", config)
+	if err != nil {
+		return err
+	}
+
+	for i, access := range accessList {
+		proof, err := newAccountProof(&evmProofs[i])
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(codes[i]) > 0 {
+			codeHash := crypto.Keccak256Hash(codes[i])
+			if codeHash != proof.CodeHash {
+				return errors.Errorf("code of %s hashes to %s instead of its code hash %s", access.Address, codeHash, proof.CodeHash)
+			}
+			cache.cache.PutCode(codes[i])
+		}
+		proofs[i] = abstract_types.Account{
+			Proof: *proof,
+			Code:  codes[i],
+		}
+	}
+	return nil
+}
+
+// batchCallWithRetries sends a batch of calls, retrying the failed calls until they all succeed or
+// the retries are exhausted. Only the batches and calls which failed to get an answer are retried,
+// a call answered with an error, like a missing trie node on a pruned node, fails right away.
+func batchCallWithRetries(client *rpc.Client, ctx context.Context, pending []rpc.BatchElem, what string, config ProofFetchConfig) error {
+	backoff := config.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= config.MaxRetries && len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return errors.Wrapf(ctx.Err(), "failed to get %s: %v", what, err)
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxProofRetryBackoff)
+		}
+
+		err = client.BatchCallContext(ctx, pending)
+		if err != nil {
+			// The whole batch failed, retry all of it
+			continue
+		}
+		failed := pending[:0]
+		for _, elem := range pending {
+			var rpcErr rpc.Error
+			if errors.As(elem.Error, &rpcErr) {
+				return errors.Wrapf(elem.Error, "failed to get %s, %s of %v", what, elem.Method, elem.Args[0])
+			}
+			if elem.Error != nil {
+				err = fmt.Errorf("%s of %v: %v", elem.Method, elem.Args[0], elem.Error)
+				elem.Error = nil
+				failed = append(failed, elem)
+			}
+		}
+		pending = failed
+	}
+	if len(pending) > 0 {
+		return errors.Wrapf(err, "failed to get %s after %d attempts", what, config.MaxRetries+1)
+	}
+	return nil
+}
+
+func nonNilStorageKeys(storageKeys []string) []string {
+	if storageKeys == nil {
+		return []string{}
+	}
+	return storageKeys
+}
@@ -0,0 +1,167 @@
+package ethrpc
+
+import (
+	"base/pkg/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testProofService serves eth_getProof and eth_getCode, answering the first calls of some accounts
+// with an error and leaving the first calls of other accounts out of the batch responses
+type testProofService struct {
+	mu       sync.Mutex
+	calls    map[string]int
+	failures map[common.Address]int
+	dropped  map[common.Address]int
+}
+
+func (s *testProofService) call(method string, address common.Address) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[method]++
+	if s.failures[address] > 0 {
+		s.failures[address]--
+		return errors.New("missing trie node")
+	}
+	return nil
+}
+
+func (s *testProofService) GetProof(address common.Address, storageKeys []string, block rpc.BlockNumberOrHash) (*models.EVMProof, error) {
+	err := s.call("eth_getProof", address)
+	if err != nil {
+		return nil, err
+	}
+	storageProof := []models.EVMStorageProof{}
+	for _, key := range storageKeys {
+		storageProof = append(storageProof, models.EVMStorageProof{Key: key, Value: "0x1", Proof: []string{"0xc0"}})
+	}
+	return &models.EVMProof{
+		Address:      address.Hex(),
+		AccountProof: []string{"0xc0"},
+		Balance:      hexutil.EncodeBig(address.Big()),
+		Nonce:        "0x1",
+		CodeHash:     crypto.Keccak256Hash(address.Bytes()).Hex(),
+		StorageProof: storageProof,
+	}, nil
+}
+
+func (s *testProofService) GetCode(address common.Address, block rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	err := s.call("eth_getCode", address)
+	if err != nil {
+		return nil, err
+	}
+	return address.Bytes(), nil
+}
+
+// serveHTTP serves the batches over HTTP, leaving the dropped calls out of the responses
+func (s *testProofService) serveHTTP(server *rpc.Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var calls []struct {
+			Id     json.RawMessage   `json:"id"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.Unmarshal(body, &calls)
+		dropped := map[string]bool{}
+		s.mu.Lock()
+		for _, call := range calls {
+			var address common.Address
+			if len(call.Params) > 0 && json.Unmarshal(call.Params[0], &address) == nil && s.dropped[address] > 0 {
+				s.dropped[address]--
+				dropped[string(call.Id)] = true
+			}
+		}
+		s.mu.Unlock()
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, r)
+		var responses []map[string]json.RawMessage
+		if len(dropped) == 0 || json.Unmarshal(recorder.Body.Bytes(), &responses) != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(recorder.Body.Bytes())
+			return
+		}
+		kept := []map[string]json.RawMessage{}
+		for _, response := range responses {
+			if !dropped[string(response["id"])] {
+				kept = append(kept, response)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(kept)
+	})
+}
+
+func newTestProofClient(t *testing.T, failures map[common.Address]int) (*rpc.Client, *testProofService) {
+	service := &testProofService{calls: map[string]int{}, failures: failures, dropped: map[common.Address]int{}}
+	server := rpc.NewServer()
+	err := server.RegisterName("eth", service)
+	if err != nil {
+		t.Fatalf("Failed to register service: %v", err)
+	}
+	t.Cleanup(server.Stop)
+	httpServer := httptest.NewServer(service.serveHTTP(server))
+	t.Cleanup(httpServer.Close)
+	client, err := rpc.Dial(httpServer.URL)
+	if err != nil {
+		t.Fatalf("Failed to dial server: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client, service
+}
+
+func TestGetProofsByAccessListWithConfig(t *testing.T) {
+	accessList := []models.EVMAccessList{}
+	for i := 1; i <= 10; i++ {
+		accessList = append(accessList, models.EVMAccessList{Address: common.BigToAddress(big.NewInt(int64(i))), StorageKeys: []string{"0x01"}})
+	}
+	flaky := accessList[6].Address
+	client, service := newTestProofClient(t, nil)
+	service.dropped[flaky] = 2
+	config := ProofFetchConfig{BatchSize: 3, Concurrency: 2, MaxRetries: 2, RetryBackoff: time.Millisecond}
+
+	proofs, err := GetProofsByAccessListWithConfig(client, context.Background(), accessList, big.NewInt(1), config)
+	if err != nil {
+		t.Fatalf("Failed to get proofs: %v", err)
+	}
+	for i, proof := range proofs {
+		address := accessList[i].Address
+		if proof.Proof.Addr != address || proof.Proof.Balance.Cmp(address.Big()) != 0 || common.BytesToAddress(proof.Code) != address || len(proof.Proof.StorageProof) != 1 {
+			t.Fatalf("Unexpected proof %d: %+v", i, proof)
+		}
+	}
+	// Only the two unanswered calls of the flaky account are retried
+	if service.calls["eth_getProof"]+service.calls["eth_getCode"] != 2*len(accessList)+2 {
+		t.Fatalf("Expected only the failed calls to be retried, got %v", service.calls)
+	}
+
+	client, service = newTestProofClient(t, nil)
+	service.dropped[flaky] = 10
+	_, err = GetProofsByAccessListWithConfig(client, context.Background(), accessList, big.NewInt(1), config)
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Fatalf("Expected the retries to be exhausted, got %v", err)
+	}
+
+	// A call answered with an error fails without being retried
+	client, service = newTestProofClient(t, map[common.Address]int{flaky: 1})
+	_, err = GetProofsByAccessListWithConfig(client, context.Background(), accessList, big.NewInt(1), config)
+	if err == nil || !strings.Contains(err.Error(), "missing trie node") || service.failures[flaky] != 0 {
+		t.Fatalf("Expected the answered error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package ethrpc
+
+import (
+	"base/pkg/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testReceiptService serves the receipts of a block without eth_getBlockReceipts, like older nodes
+type testReceiptService struct {
+	receipts map[common.Hash]*types.Receipt
+	txHashes []common.Hash
+	// fallbackCalls counts the calls of the methods of the fallback
+	fallbackCalls int
+}
+
+func (s *testReceiptService) GetBlockByNumber(blockNumber string, fullTransactions bool) (map[string]interface{}, error) {
+	s.fallbackCalls++
+	return map[string]interface{}{"transactions": s.txHashes}, nil
+}
+
+func (s *testReceiptService) GetTransactionReceipt(txHash common.Hash) (*types.Receipt, error) {
+	s.fallbackCalls++
+	return s.receipts[txHash], nil
+}
+
+// testBlockReceiptsService also serves eth_getBlockReceipts, or fails it with err
+type testBlockReceiptsService struct {
+	*testReceiptService
+	err error
+}
+
+func (s *testBlockReceiptsService) GetBlockReceipts(blockNumber string) ([]*types.Receipt, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	receipts := []*types.Receipt{}
+	for _, txHash := range s.txHashes {
+		receipts = append(receipts, s.receipts[txHash])
+	}
+	return receipts, nil
+}
+
+func testReceipts() []*types.Receipt {
+	blockHash := common.HexToHash("0xb1")
+	contract := common.HexToAddress("0xc0")
+	return []*types.Receipt{
+		{
+			Type:              types.DynamicFeeTxType,
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 50000,
+			Logs: []*types.Log{{
+				Address:     contract,
+				Topics:      []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")},
+				Data:        []byte{1, 2, 3},
+				BlockNumber: 7,
+				TxHash:      common.HexToHash("0xa1"),
+				BlockHash:   blockHash,
+			}},
+			TxHash:            common.HexToHash("0xa1"),
+			GasUsed:           50000,
+			EffectiveGasPrice: big.NewInt(1000),
+			BlockHash:         blockHash,
+			BlockNumber:       big.NewInt(7),
+		},
+		{
+			Type:              types.BlobTxType,
+			Status:            types.ReceiptStatusFailed,
+			CumulativeGasUsed: 71000,
+			Logs:              []*types.Log{},
+			TxHash:            common.HexToHash("0xa2"),
+			ContractAddress:   contract,
+			GasUsed:           21000,
+			EffectiveGasPrice: big.NewInt(1000),
+			BlobGasUsed:       131072,
+			BlobGasPrice:      big.NewInt(3),
+			BlockHash:         blockHash,
+			BlockNumber:       big.NewInt(7),
+			TransactionIndex:  1,
+		},
+	}
+}
+
+func newTestReceiptService(expected []*types.Receipt) *testReceiptService {
+	service := &testReceiptService{receipts: map[common.Hash]*types.Receipt{}}
+	for _, receipt := range expected {
+		receipt.Bloom = types.CreateBloom(receipt)
+		service.receipts[receipt.TxHash] = receipt
+		service.txHashes = append(service.txHashes, receipt.TxHash)
+	}
+	return service
+}
+
+func newTestReceiptClient(t *testing.T, service interface{}) *rpc.Client {
+	server := rpc.NewServer()
+	err := server.RegisterName("eth", service)
+	if err != nil {
+		t.Fatalf("Failed to register service: %v", err)
+	}
+	t.Cleanup(server.Stop)
+	return rpc.DialInProc(server)
+}
+
+func checkReceipts(t *testing.T, receipts []*models.EVMTransactionReceipt, expected []*types.Receipt) {
+	if len(receipts) != len(expected) {
+		t.Fatalf("Expected %d receipts, got %d", len(expected), len(receipts))
+	}
+	for i, receipt := range models.ToGethReceipts(receipts) {
+		expectedJSON, _ := json.Marshal(expected[i])
+		actualJSON, err := json.Marshal(receipt)
+		if err != nil {
+			t.Fatalf("Failed to encode receipt %d: %v", i, err)
+		}
+		if !bytes.Equal(expectedJSON, actualJSON) {
+			t.Errorf("Receipt %d does not round trip:\nexpected %s\nactual   %s", i, expectedJSON, actualJSON)
+		}
+	}
+}
+
+func TestGetBlockReceipts(t *testing.T) {
+	expected := testReceipts()
+	service := newTestReceiptService(expected)
+	client := newTestReceiptClient(t, &testBlockReceiptsService{testReceiptService: service})
+
+	receipts, err := GetBlockReceipts(client, context.Background(), "0x7")
+	if err != nil {
+		t.Fatalf("Failed to get block receipts: %v", err)
+	}
+	checkReceipts(t, receipts, expected)
+	if service.fallbackCalls != 0 {
+		t.Fatalf("Expected eth_getBlockReceipts alone to be called, got %d fallback calls", service.fallbackCalls)
+	}
+
+	// Other errors than a method not found are returned, without falling back
+	client = newTestReceiptClient(t, &testBlockReceiptsService{testReceiptService: service, err: errors.New("header not found")})
+	_, err = GetBlockReceipts(client, context.Background(), "0x7")
+	if err == nil || !strings.Contains(err.Error(), "header not found") {
+		t.Fatalf("Expected the eth_getBlockReceipts error, got %v", err)
+	}
+	if service.fallbackCalls != 0 {
+		t.Fatalf("Expected no fallback after an eth_getBlockReceipts error, got %d fallback calls", service.fallbackCalls)
+	}
+}
+
+func TestGetBlockReceiptsFallback(t *testing.T) {
+	expected := testReceipts()
+	client := newTestReceiptClient(t, newTestReceiptService(expected))
+
+	receipts, err := GetBlockReceipts(client, context.Background(), "0x7")
+	if err != nil {
+		t.Fatalf("Failed to get block receipts: %v", err)
+	}
+	checkReceipts(t, receipts, expected)
+}
@@ -0,0 +1,253 @@
+package ethrpc
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultWSMaxBackoff is the default longest wait between two attempts to resubscribe
+	DefaultWSMaxBackoff = 30 * time.Second
+
+	// At most this many heads missed while resubscribing are fetched again, older ones are skipped
+	maxMissedHeads = 256
+	wsFetchTimeout = 30 * time.Second
+)
+
+var errSubscriptionEnded = errors.New("subscription ended")
+
+// WSEndpointsFromEnv reads websocket endpoints from the environment variables
+// <prefix>_WEBSOCKET_ENDPOINTS (comma separated) or <prefix>_WEBSOCKET_ENDPOINT
+func WSEndpointsFromEnv(prefix string) []string {
+	return endpointsFromEnv(prefix + "_WEBSOCKET_ENDPOINT")
+}
+
+// WSClient subscribes to a chain over websocket endpoints of the same chain.
+//
+// Subscriptions go to the first reachable endpoint. When a subscription fails, it is resubscribed
+// with a backoff over the next endpoint, which stays in use until it fails in turn. The heads and
+// logs missed meanwhile are fetched again, so that subscribers see a continuous stream.
+type WSClient struct {
+	endpoints  []string
+	maxBackoff time.Duration
+
+	mu      sync.Mutex
+	current int
+	client  *ethclient.Client
+}
+
+// DialWSClient connects to the first reachable endpoint. It only fails when no endpoint is reachable.
+func DialWSClient(ctx context.Context, endpoints []string) (*WSClient, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no websocket endpoint configured")
+	}
+	c := &WSClient{endpoints: endpoints, maxBackoff: DefaultWSMaxBackoff}
+	_, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close closes the connection to the current endpoint
+func (c *WSClient) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+}
+
+// dial returns the client of the current endpoint, dialing the endpoints in turn from it when not connected
+func (c *WSClient) dial(ctx context.Context) (*ethclient.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	var err error
+	for range c.endpoints {
+		e := &endpoint{url: c.endpoints[c.current]}
+		c.client, err = ethclient.DialContext(ctx, e.url)
+		if err == nil {
+			return c.client, nil
+		}
+		log.Printf("Failed to dial websocket endpoint %s: %v", e.name(), err)
+		c.current = (c.current + 1) % len(c.endpoints)
+	}
+	return nil, errors.Wrap(err, "no reachable websocket endpoint")
+}
+
+// failover closes the connection of a failed subscription, the next subscriptions going to the next endpoint
+func (c *WSClient) failover(client *ethclient.Client, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != client {
+		// Already failed over by another subscription
+		return
+	}
+	log.Printf("Websocket endpoint %s failed, failing over: %v", (&endpoint{url: c.endpoints[c.current]}).name(), err)
+	c.client.Close()
+	c.client = nil
+	c.current = (c.current + 1) % len(c.endpoints)
+}
+
+// resubscribe keeps a subscription established over the endpoints until it is unsubscribed
+func (c *WSClient) resubscribe(subscribe func(ctx context.Context, client *ethclient.Client) (event.Subscription, error)) event.Subscription {
+	return event.ResubscribeErr(c.maxBackoff, func(ctx context.Context, _ error) (event.Subscription, error) {
+		client, err := c.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sub, err := subscribe(ctx, client)
+		if err != nil {
+			if ctx.Err() == nil {
+				c.failover(client, err)
+			}
+			return nil, err
+		}
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			defer sub.Unsubscribe()
+			select {
+			case err := <-sub.Err():
+				if err == nil {
+					err = errSubscriptionEnded
+				}
+				c.failover(client, err)
+				return err
+			case <-quit:
+				return nil
+			}
+		}), nil
+	})
+}
+
+// SubscribeNewHead sends the new heads to ch until unsubscribed, the heads missed while resubscribing
+// being fetched from the next endpoint. Unlike ethclient, a failing endpoint doesn't end the
+// subscription, so its Err channel only closes once unsubscribed.
+func (c *WSClient) SubscribeNewHead(ch chan<- *types.Header) event.Subscription {
+	var last *big.Int
+	return c.resubscribe(func(ctx context.Context, client *ethclient.Client) (event.Subscription, error) {
+		heads := make(chan *types.Header)
+		sub, err := client.SubscribeNewHead(ctx, heads)
+		if err != nil {
+			return nil, err
+		}
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			defer sub.Unsubscribe()
+			for {
+				var head *types.Header
+				select {
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				case head = <-heads:
+				}
+
+				// Send the heads missed since the last one first
+				if last != nil && head.Number.Cmp(last) > 0 {
+					number := new(big.Int).Add(last, big.NewInt(1))
+					if missed := new(big.Int).Sub(head.Number, number); missed.Cmp(big.NewInt(maxMissedHeads)) > 0 {
+						log.Printf("Skipping %s heads missed while resubscribing", new(big.Int).Sub(missed, big.NewInt(maxMissedHeads)))
+						number.Sub(head.Number, big.NewInt(maxMissedHeads))
+					}
+					for ; number.Cmp(head.Number) < 0; number.Add(number, big.NewInt(1)) {
+						ctx, cancel := context.WithTimeout(context.Background(), wsFetchTimeout)
+						missed, err := client.HeaderByNumber(ctx, number)
+						cancel()
+						if err != nil {
+							return errors.Wrapf(err, "failed to fetch missed head %s", number)
+						}
+						select {
+						case ch <- missed:
+						case <-quit:
+							return nil
+						}
+					}
+				}
+				select {
+				case ch <- head:
+					last = head.Number
+				case <-quit:
+					return nil
+				}
+			}
+		}), nil
+	})
+}
+
+// SubscribeFilterLogs sends the logs matching the query to ch until unsubscribed, the logs missed
+// while resubscribing being fetched from the next endpoint. Logs are sent once and in chain order,
+// except the removed logs of reorgs. The Err channel only closes once unsubscribed, see SubscribeNewHead.
+func (c *WSClient) SubscribeFilterLogs(q ethereum.FilterQuery, ch chan<- types.Log) event.Subscription {
+	var last *types.Log
+	after := func(l *types.Log) bool {
+		return last == nil || l.BlockNumber > last.BlockNumber || (l.BlockNumber == last.BlockNumber && l.Index > last.Index)
+	}
+	return c.resubscribe(func(ctx context.Context, client *ethclient.Client) (event.Subscription, error) {
+		logs := make(chan types.Log)
+		sub, err := client.SubscribeFilterLogs(ctx, q, logs)
+		if err != nil {
+			return nil, err
+		}
+
+		// Fetch the logs since the last one sent, the logs of the subscription buffering meanwhile
+		var missed []types.Log
+		if last != nil {
+			query := q
+			query.FromBlock = new(big.Int).SetUint64(last.BlockNumber)
+			query.ToBlock = nil
+			missed, err = client.FilterLogs(ctx, query)
+			if err != nil {
+				sub.Unsubscribe()
+				return nil, errors.Wrap(err, "failed to fetch missed logs")
+			}
+		}
+
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			defer sub.Unsubscribe()
+			send := func(l types.Log) bool {
+				if !l.Removed && !after(&l) {
+					return true
+				}
+				select {
+				case ch <- l:
+					if !l.Removed {
+						last = &l
+					}
+					return true
+				case <-quit:
+					return false
+				}
+			}
+			for _, l := range missed {
+				if !send(l) {
+					return nil
+				}
+			}
+			for {
+				select {
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				case l := <-logs:
+					if !send(l) {
+						return nil
+					}
+				}
+			}
+		}), nil
+	})
+}
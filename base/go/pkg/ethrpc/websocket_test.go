@@ -0,0 +1,172 @@
+package ethrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testWSService notifies its heads and logs to the subscribers, and serves them as a chain up to its head
+type testWSService struct {
+	*testChainService
+	heads []uint64
+	logs  []types.Log
+}
+
+func (s *testWSService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	for _, number := range s.heads {
+		header, _ := s.GetBlockByNumber(rpc.BlockNumber(number), false)
+		notifier.Notify(sub.ID, header)
+	}
+	return sub, nil
+}
+
+func (s *testWSService) Logs(ctx context.Context, query map[string]interface{}) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	for _, l := range s.logs {
+		if l.BlockNumber > s.head {
+			notifier.Notify(sub.ID, l)
+		}
+	}
+	return sub, nil
+}
+
+func (s *testWSService) GetLogs(query map[string]interface{}) []types.Log {
+	logs := []types.Log{}
+	for _, l := range s.logs {
+		if l.BlockNumber <= s.head {
+			logs = append(logs, l)
+		}
+	}
+	return logs
+}
+
+func testLog(number uint64, index uint) types.Log {
+	return types.Log{BlockNumber: number, Index: index, Topics: []common.Hash{}}
+}
+
+// newTestWSServer serves a service over websocket, stopping the returned server closes the connections
+func newTestWSServer(t *testing.T, service *testWSService) (*rpc.Server, string) {
+	server := rpc.NewServer()
+	err := server.RegisterName("eth", service)
+	if err != nil {
+		t.Fatalf("Failed to register service: %v", err)
+	}
+	t.Cleanup(server.Stop)
+	httpServer := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	t.Cleanup(httpServer.Close)
+	return server, "ws" + strings.TrimPrefix(httpServer.URL, "http")
+}
+
+func newTestWSClient(t *testing.T, services ...*testWSService) (*WSClient, []*rpc.Server) {
+	servers := []*rpc.Server{}
+	endpoints := []string{}
+	for _, service := range services {
+		server, endpoint := newTestWSServer(t, service)
+		servers = append(servers, server)
+		endpoints = append(endpoints, endpoint)
+	}
+	client, err := DialWSClient(context.Background(), endpoints)
+	if err != nil {
+		t.Fatalf("Failed to dial websocket client: %v", err)
+	}
+	client.maxBackoff = 10 * time.Millisecond
+	t.Cleanup(client.Close)
+	return client, servers
+}
+
+func receive[T any](t *testing.T, ch <-chan T, count int) []T {
+	received := []T{}
+	for range count {
+		select {
+		case value := <-ch:
+			received = append(received, value)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out after receiving %d of %d values", len(received), count)
+		}
+	}
+	return received
+}
+
+func TestWSClientSubscribeNewHead(t *testing.T) {
+	first := &testWSService{testChainService: &testChainService{head: 2}, heads: []uint64{1, 2}}
+	second := &testWSService{testChainService: &testChainService{head: 5}, heads: []uint64{5}}
+	client, servers := newTestWSClient(t, first, second)
+
+	heads := make(chan *types.Header)
+	sub := client.SubscribeNewHead(heads)
+	defer sub.Unsubscribe()
+	received := receive(t, heads, 2)
+
+	// The heads missed while failing over are fetched from the next endpoint
+	servers[0].Stop()
+	received = append(received, receive(t, heads, 3)...)
+	for i, head := range received {
+		if head.Number.Uint64() != uint64(i+1) {
+			t.Fatalf("Expected the heads 1 to 5 in order, got %d at %d", head.Number, i)
+		}
+	}
+}
+
+func TestWSClientSubscribeFilterLogs(t *testing.T) {
+	first := &testWSService{testChainService: &testChainService{}, logs: []types.Log{testLog(1, 0), testLog(2, 0)}}
+	second := &testWSService{testChainService: &testChainService{head: 3}, logs: []types.Log{
+		testLog(2, 0),
+		testLog(3, 0),
+		testLog(3, 1),
+		testLog(3, 1),
+		testLog(4, 0),
+	}}
+	client, servers := newTestWSClient(t, first, second)
+
+	logs := make(chan types.Log)
+	sub := client.SubscribeFilterLogs(ethereum.FilterQuery{}, logs)
+	defer sub.Unsubscribe()
+	received := receive(t, logs, 2)
+
+	// The logs missed while failing over are fetched from the next endpoint, the logs already sent are not sent again
+	servers[0].Stop()
+	received = append(received, receive(t, logs, 3)...)
+	expected := [][2]uint{{1, 0}, {2, 0}, {3, 0}, {3, 1}, {4, 0}}
+	for i, l := range received {
+		if l.BlockNumber != uint64(expected[i][0]) || l.Index != expected[i][1] {
+			t.Fatalf("Expected the logs %v in order, got %d/%d at %d", expected, l.BlockNumber, l.Index, i)
+		}
+	}
+	select {
+	case l := <-logs:
+		t.Fatalf("Unexpected log %d/%d", l.BlockNumber, l.Index)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDialWSClient(t *testing.T) {
+	_, err := DialWSClient(context.Background(), nil)
+	if err == nil {
+		t.Fatalf("Expected no endpoint to fail")
+	}
+
+	// Unreachable endpoints are skipped
+	_, endpoint := newTestWSServer(t, &testWSService{testChainService: &testChainService{}})
+	client, err := DialWSClient(context.Background(), []string{"ws://127.0.0.1:1", endpoint})
+	if err != nil {
+		t.Fatalf("Expected the reachable endpoint to be dialed, got %v", err)
+	}
+	client.Close()
+}
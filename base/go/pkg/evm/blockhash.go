@@ -0,0 +1,62 @@
+package evm
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// AncestorHashes serves BLOCKHASH from the parent hash of a block and a chain of its ancestor
+// headers, ordered from the parent down
+type AncestorHashes struct {
+	hashes  map[uint64]common.Hash
+	missing []uint64
+}
+
+// NewAncestorHashes checks that the ancestors are linked by their parent hashes to the block of
+// the given number and parent hash
+func NewAncestorHashes(number uint64, parentHash common.Hash, ancestors []*types.Header) (*AncestorHashes, error) {
+	hashes := map[uint64]common.Hash{}
+	if number == 0 {
+		if len(ancestors) > 0 {
+			return nil, errors.New("genesis block has no ancestors")
+		}
+		return &AncestorHashes{hashes: hashes}, nil
+	}
+
+	hashes[number-1] = parentHash
+	for i, ancestor := range ancestors {
+		expectedNumber := number - 1 - uint64(i)
+		if ancestor == nil || ancestor.Number == nil || !ancestor.Number.IsUint64() || ancestor.Number.Uint64() != expectedNumber {
+			return nil, errors.Errorf("ancestor %d is not block %d", i, expectedNumber)
+		}
+		if ancestor.Hash() != hashes[expectedNumber] {
+			return nil, errors.Errorf("ancestor block %d does not match the parent hash of its child", expectedNumber)
+		}
+		if expectedNumber == 0 {
+			if i != len(ancestors)-1 {
+				return nil, errors.New("ancestors go past the genesis block")
+			}
+			break
+		}
+		hashes[expectedNumber-1] = ancestor.ParentHash
+	}
+	return &AncestorHashes{hashes: hashes}, nil
+}
+
+// GetHash returns the hash of a block, or records the block as missing and returns the zero hash
+func (a *AncestorHashes) GetHash(number uint64) common.Hash {
+	hash, ok := a.hashes[number]
+	if !ok {
+		a.missing = append(a.missing, number)
+	}
+	return hash
+}
+
+// Err returns an error if the hash of a block not covered by the ancestors was requested
+func (a *AncestorHashes) Err() error {
+	if len(a.missing) > 0 {
+		return errors.Errorf("hash of block %d requested but not covered by the ancestor headers", a.missing[0])
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package evm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testAncestors returns count linked headers before the block of the given number, from the
+// parent down, and the parent hash of that block
+func testAncestors(number uint64, count int) ([]*types.Header, common.Hash) {
+	headers := make([]*types.Header, count)
+	parentHash := common.HexToHash("0x01")
+	for i := count - 1; i >= 0; i-- {
+		headers[i] = &types.Header{
+			ParentHash: parentHash,
+			Number:     new(big.Int).SetUint64(number - 1 - uint64(i)),
+			Difficulty: big.NewInt(0),
+		}
+		parentHash = headers[i].Hash()
+	}
+	return headers, parentHash
+}
+
+func TestAncestorHashes(t *testing.T) {
+	ancestors, parentHash := testAncestors(100, 3)
+	hashes, err := NewAncestorHashes(100, parentHash, ancestors)
+	if err != nil {
+		t.Fatalf("Failed to link ancestors: %v", err)
+	}
+	if hashes.GetHash(99) != parentHash || hashes.GetHash(98) != ancestors[0].ParentHash || hashes.GetHash(96) != ancestors[2].ParentHash {
+		t.Fatalf("Unexpected ancestor hashes")
+	}
+	if hashes.Err() != nil {
+		t.Fatalf("Unexpected error: %v", hashes.Err())
+	}
+	if hashes.GetHash(95) != (common.Hash{}) || hashes.Err() == nil {
+		t.Fatalf("Expected a block before the ancestors to be reported missing")
+	}
+
+	// Broken linkage
+	_, err = NewAncestorHashes(100, common.HexToHash("0x02"), ancestors)
+	if err == nil {
+		t.Fatalf("Expected ancestors not matching the parent hash to be rejected")
+	}
+	_, err = NewAncestorHashes(100, parentHash, []*types.Header{ancestors[0], ancestors[2]})
+	if err == nil {
+		t.Fatalf("Expected a gap in the ancestors to be rejected")
+	}
+	_, err = NewAncestorHashes(101, parentHash, ancestors)
+	if err == nil {
+		t.Fatalf("Expected ancestors of another block to be rejected")
+	}
+}
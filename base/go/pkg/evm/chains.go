@@ -0,0 +1,81 @@
+package evm
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+// DevnetChainIds are the chain ids of local devnets (geth --dev, anvil and hardhat), which run
+// every fork from genesis
+var DevnetChainIds = []uint64{1337, 31337, 31338}
+
+// ErrUnknownChain is returned for a chain id without a registered chain config
+var ErrUnknownChain = errors.New("unknown chain")
+
+var chainConfigs = struct {
+	sync.RWMutex
+	configs map[string]*params.ChainConfig
+}{configs: map[string]*params.ChainConfig{}}
+
+func init() {
+	RegisterChainConfig(params.MainnetChainConfig)
+	RegisterChainConfig(params.SepoliaChainConfig)
+	RegisterChainConfig(params.HoleskyChainConfig)
+	for _, chainId := range DevnetChainIds {
+		RegisterChainConfig(CreateConfig(new(big.Int).SetUint64(chainId)))
+	}
+}
+
+// RegisterChainConfig registers the fork schedule of a chain, replacing any config already
+// registered for its chain id
+func RegisterChainConfig(config *params.ChainConfig) error {
+	if config == nil || config.ChainID == nil {
+		return errors.New("chain config has no chain id")
+	}
+	chainConfigs.Lock()
+	defer chainConfigs.Unlock()
+	chainConfigs.configs[config.ChainID.String()] = config
+	return nil
+}
+
+// RegisterGenesis registers the chain config of a genesis, for private chains and custom devnets
+func RegisterGenesis(genesis *core.Genesis) error {
+	if genesis == nil || genesis.Config == nil {
+		return errors.New("genesis has no chain config")
+	}
+	return RegisterChainConfig(genesis.Config)
+}
+
+// RegisterGenesisFile registers the chain config of a geth genesis.json file
+func RegisterGenesisFile(path string) error {
+	genesisJSON, err := os.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var genesis core.Genesis
+	err = json.Unmarshal(genesisJSON, &genesis)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode genesis %s", path)
+	}
+	return RegisterGenesis(&genesis)
+}
+
+// ChainConfig returns the registered chain config of a chain id
+func ChainConfig(chainId *big.Int) (*params.ChainConfig, error) {
+	if chainId == nil {
+		return nil, errors.Wrap(ErrUnknownChain, "no chain id")
+	}
+	chainConfigs.RLock()
+	defer chainConfigs.RUnlock()
+	config, ok := chainConfigs.configs[chainId.String()]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownChain, "chain id %s", chainId)
+	}
+	return config, nil
+}
@@ -0,0 +1,63 @@
+package evm
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+func TestChainConfig(t *testing.T) {
+	for _, expected := range []*params.ChainConfig{params.MainnetChainConfig, params.SepoliaChainConfig, params.HoleskyChainConfig} {
+		config, err := ChainConfig(expected.ChainID)
+		if err != nil {
+			t.Fatalf("Failed to get the config of chain %s: %v", expected.ChainID, err)
+		}
+		if config != expected {
+			t.Fatalf("Unexpected config for chain %s", expected.ChainID)
+		}
+	}
+
+	// Mainnet blocks before Cancun run under the earlier rules
+	mainnet, _ := ChainConfig(big.NewInt(1))
+	if mainnet.IsCancun(big.NewInt(19000000), 1700000000) || !mainnet.IsShanghai(big.NewInt(19000000), 1700000000) {
+		t.Fatalf("Expected a mainnet block of November 2023 to be Shanghai")
+	}
+	devnet, err := ChainConfig(big.NewInt(31337))
+	if err != nil || !devnet.IsCancun(big.NewInt(0), 0) {
+		t.Fatalf("Expected devnets to run Cancun from genesis: %v", err)
+	}
+
+	_, err = ChainConfig(big.NewInt(424242))
+	if !errors.Is(err, ErrUnknownChain) {
+		t.Fatalf("Expected an unknown chain error, got %v", err)
+	}
+	_, _, err = CreateEVM(big.NewInt(424242), common.Hash{}, &types.Header{Number: big.NewInt(0)}, nil, nil, nil)
+	if !errors.Is(err, ErrUnknownChain) {
+		t.Fatalf("Expected CreateEVM to reject an unknown chain, got %v", err)
+	}
+}
+
+func TestRegisterGenesisFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "genesis.json")
+	genesis := `{"config": {"chainId": 424243, "homesteadBlock": 0, "londonBlock": 0, "shanghaiTime": 100}, "gasLimit": "0x1c9c380", "difficulty": "0x0", "alloc": {}}`
+	if err := os.WriteFile(path, []byte(genesis), 0o600); err != nil {
+		t.Fatalf("Failed to write genesis: %v", err)
+	}
+	if err := RegisterGenesisFile(path); err != nil {
+		t.Fatalf("Failed to register genesis: %v", err)
+	}
+
+	config, err := ChainConfig(big.NewInt(424243))
+	if err != nil {
+		t.Fatalf("Failed to get the registered config: %v", err)
+	}
+	if config.IsShanghai(big.NewInt(1), 99) || !config.IsShanghai(big.NewInt(1), 100) {
+		t.Fatalf("Unexpected Shanghai activation in %+v", config)
+	}
+}
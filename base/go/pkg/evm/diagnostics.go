@@ -0,0 +1,301 @@
+package evm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// diagnosticTailLength is the number of last opcode steps kept in a trace summary
+const diagnosticTailLength = 32
+
+// Diagnostics is the report of a diagnostic re-run of a claim that failed verification
+type Diagnostics struct {
+	// Error is the error of the verification
+	Error string `json:"error"`
+	// ExecutionError is the error of the diagnostic re-run, if it failed before producing a result
+	ExecutionError string `json:"executionError,omitempty"`
+
+	// ExpectedOutput and ActualOutput are the claimed and computed outputs of a view function call
+	ExpectedOutput hexutil.Bytes `json:"expectedOutput,omitempty"`
+	ActualOutput   hexutil.Bytes `json:"actualOutput,omitempty"`
+
+	// ExpectedStateRoot, ActualStateRoot, ExpectedReceiptsRoot and ActualReceiptsRoot are the
+	// claimed and computed roots of a processed block
+	ExpectedStateRoot    *common.Hash `json:"expectedStateRoot,omitempty"`
+	ActualStateRoot      *common.Hash `json:"actualStateRoot,omitempty"`
+	ExpectedReceiptsRoot *common.Hash `json:"expectedReceiptsRoot,omitempty"`
+	ActualReceiptsRoot   *common.Hash `json:"actualReceiptsRoot,omitempty"`
+	// FirstDivergingReceipt is the first computed receipt differing from the expected receipts
+	FirstDivergingReceipt *ReceiptDivergence `json:"firstDivergingReceipt,omitempty"`
+
+	// MissingAccounts and MissingStorage are the state touched by the execution but missing from
+	// the witness or account proofs
+	MissingAccounts []common.Address `json:"missingAccounts,omitempty"`
+	MissingStorage  []StorageSlot    `json:"missingStorage,omitempty"`
+
+	Trace *TraceSummary `json:"trace,omitempty"`
+}
+
+// StorageSlot is a storage slot of an account
+type StorageSlot struct {
+	Address common.Address `json:"address"`
+	Slot    common.Hash    `json:"slot"`
+}
+
+// ReceiptDivergence is a field of a receipt differing from the expected receipt
+type ReceiptDivergence struct {
+	Index    int         `json:"index"`
+	TxHash   common.Hash `json:"txHash"`
+	Field    string      `json:"field"`
+	Expected string      `json:"expected"`
+	Actual   string      `json:"actual"`
+}
+
+// TraceSummary summarizes the opcodes run by an execution
+type TraceSummary struct {
+	Steps    uint64            `json:"steps"`
+	Calls    uint64            `json:"calls"`
+	Reverts  uint64            `json:"reverts"`
+	MaxDepth int               `json:"maxDepth"`
+	Opcodes  map[string]uint64 `json:"opcodes"`
+	// Tail holds the last opcode steps, as "depth:pc:opcode"
+	Tail []string `json:"tail"`
+	// Errors holds the errors of the calls and opcodes which failed
+	Errors []string `json:"errors,omitempty"`
+}
+
+// String returns the report as indented JSON
+func (d *Diagnostics) String() string {
+	report, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%+v", *d)
+	}
+	return string(report)
+}
+
+// DiagnosticTracer records the state touched and the opcodes run by an execution
+type DiagnosticTracer struct {
+	summary  TraceSummary
+	accounts map[common.Address]bool
+	slots    map[StorageSlot]bool
+	receipts []*types.Receipt
+}
+
+func NewDiagnosticTracer() *DiagnosticTracer {
+	return &DiagnosticTracer{
+		summary:  TraceSummary{Opcodes: map[string]uint64{}, Tail: []string{}},
+		accounts: map[common.Address]bool{},
+		slots:    map[StorageSlot]bool{},
+	}
+}
+
+// Hooks returns the go-ethereum tracing hooks of the tracer
+func (t *DiagnosticTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: func(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+			t.accounts[from] = true
+		},
+		OnTxEnd: func(receipt *types.Receipt, err error) {
+			if err != nil {
+				t.summary.Errors = append(t.summary.Errors, fmt.Sprintf("transaction: %v", err))
+			}
+			if receipt != nil {
+				t.receipts = append(t.receipts, receipt)
+			}
+		},
+		OnEnter: func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+			t.summary.Calls++
+			t.accounts[from] = true
+			t.accounts[to] = true
+			if depth > t.summary.MaxDepth {
+				t.summary.MaxDepth = depth
+			}
+		},
+		OnExit: func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+			if reverted {
+				t.summary.Reverts++
+			}
+			if err != nil {
+				t.summary.Errors = append(t.summary.Errors, fmt.Sprintf("call at depth %d: %v", depth, err))
+			}
+		},
+		OnOpcode: t.onOpcode,
+		OnFault: func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, depth int, err error) {
+			t.summary.Errors = append(t.summary.Errors, fmt.Sprintf("%s at depth %d, pc %d: %v", vm.OpCode(op), depth, pc, err))
+		},
+	}
+}
+
+func (t *DiagnosticTracer) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	opcode := vm.OpCode(op)
+	t.summary.Steps++
+	t.summary.Opcodes[opcode.String()]++
+	if len(t.summary.Tail) == diagnosticTailLength {
+		t.summary.Tail = t.summary.Tail[1:]
+	}
+	t.summary.Tail = append(t.summary.Tail, fmt.Sprintf("%d:%d:%s", depth, pc, opcode))
+
+	// Record the accounts and slots read from the stack, whose top is its last element
+	stack := scope.StackData()
+	peek := func(n int) (common.Hash, bool) {
+		if len(stack) <= n {
+			return common.Hash{}, false
+		}
+		return common.Hash(stack[len(stack)-1-n].Bytes32()), true
+	}
+	switch opcode {
+	case vm.SLOAD, vm.SSTORE:
+		if slot, ok := peek(0); ok {
+			t.slots[StorageSlot{Address: scope.Address(), Slot: slot}] = true
+		}
+	case vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODECOPY, vm.EXTCODEHASH, vm.SELFDESTRUCT:
+		if address, ok := peek(0); ok {
+			t.accounts[common.BytesToAddress(address[:])] = true
+		}
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		if address, ok := peek(1); ok {
+			t.accounts[common.BytesToAddress(address[:])] = true
+		}
+	}
+}
+
+// Summary returns the summary of the opcodes run
+func (t *DiagnosticTracer) Summary() *TraceSummary {
+	summary := t.summary
+	return &summary
+}
+
+// Receipts returns the receipts of the transactions run
+func (t *DiagnosticTracer) Receipts() []*types.Receipt {
+	return t.receipts
+}
+
+// MissingState returns the touched accounts and slots which can't be resolved from the trie
+// nodes of db under the state root
+func (t *DiagnosticTracer) MissingState(stateRoot common.Hash, db ethdb.KeyValueReader) ([]common.Address, []StorageSlot) {
+	missingAccounts := []common.Address{}
+	missingStorage := []StorageSlot{}
+	storageRoots := map[common.Address]*common.Hash{}
+
+	for _, address := range sortedAddresses(t.accounts) {
+		storageRoot, ok := resolveStorageRoot(stateRoot, address, db)
+		if !ok {
+			missingAccounts = append(missingAccounts, address)
+		}
+		storageRoots[address] = storageRoot
+	}
+
+	slots := make([]StorageSlot, 0, len(t.slots))
+	for slot := range t.slots {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].Address != slots[j].Address {
+			return bytes.Compare(slots[i].Address[:], slots[j].Address[:]) < 0
+		}
+		return bytes.Compare(slots[i].Slot[:], slots[j].Slot[:]) < 0
+	})
+	for _, slot := range slots {
+		storageRoot, ok := storageRoots[slot.Address]
+		if !ok {
+			storageRoot, _ = resolveStorageRoot(stateRoot, slot.Address, db)
+			storageRoots[slot.Address] = storageRoot
+		}
+		if storageRoot == nil {
+			// The account is missing, or absent and without storage
+			continue
+		}
+		_, err := trie.VerifyProof(*storageRoot, crypto.Keccak256(slot.Slot[:]), db)
+		if err != nil {
+			missingStorage = append(missingStorage, slot)
+		}
+	}
+	return missingAccounts, missingStorage
+}
+
+// resolveStorageRoot returns the storage root of an account and whether its trie path is
+// resolvable, the storage root is nil for absent accounts
+func resolveStorageRoot(stateRoot common.Hash, address common.Address, db ethdb.KeyValueReader) (*common.Hash, bool) {
+	accountRLP, err := trie.VerifyProof(stateRoot, crypto.Keccak256(address.Bytes()), db)
+	if err != nil {
+		return nil, false
+	}
+	if len(accountRLP) == 0 {
+		return nil, true
+	}
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(accountRLP, &account); err != nil {
+		return nil, false
+	}
+	if account.Root == types.EmptyRootHash {
+		return nil, true
+	}
+	return &account.Root, true
+}
+
+func sortedAddresses(addresses map[common.Address]bool) []common.Address {
+	sorted := make([]common.Address, 0, len(addresses))
+	for address := range addresses {
+		sorted = append(sorted, address)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+	return sorted
+}
+
+// CompareReceipts returns the first receipt of actual differing from expected, or nil
+func CompareReceipts(expected []*types.Receipt, actual []*types.Receipt) *ReceiptDivergence {
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		if i >= len(actual) {
+			return &ReceiptDivergence{Index: i, TxHash: expected[i].TxHash, Field: "missing", Expected: "receipt", Actual: "none"}
+		}
+		if i >= len(expected) {
+			return &ReceiptDivergence{Index: i, TxHash: actual[i].TxHash, Field: "unexpected", Expected: "none", Actual: "receipt"}
+		}
+		divergence := func(field string, expectedValue, actualValue any) *ReceiptDivergence {
+			return &ReceiptDivergence{
+				Index:    i,
+				TxHash:   expected[i].TxHash,
+				Field:    field,
+				Expected: fmt.Sprint(expectedValue),
+				Actual:   fmt.Sprint(actualValue),
+			}
+		}
+		e, a := expected[i], actual[i]
+		switch {
+		case e.Status != a.Status:
+			return divergence("status", e.Status, a.Status)
+		case e.CumulativeGasUsed != a.CumulativeGasUsed:
+			return divergence("cumulativeGasUsed", e.CumulativeGasUsed, a.CumulativeGasUsed)
+		case len(e.Logs) != len(a.Logs):
+			return divergence("logs", len(e.Logs), len(a.Logs))
+		case e.Bloom != a.Bloom:
+			return divergence("logsBloom", hexutil.Encode(e.Bloom[:]), hexutil.Encode(a.Bloom[:]))
+		}
+		for j := range e.Logs {
+			expectedLog, err := rlp.EncodeToBytes(e.Logs[j])
+			if err != nil {
+				continue
+			}
+			actualLog, err := rlp.EncodeToBytes(a.Logs[j])
+			if err != nil || !bytes.Equal(expectedLog, actualLog) {
+				return divergence(fmt.Sprintf("logs[%d]", j), hexutil.Encode(expectedLog), hexutil.Encode(actualLog))
+			}
+		}
+	}
+	return nil
+}
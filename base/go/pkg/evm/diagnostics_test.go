@@ -0,0 +1,62 @@
+package evm
+
+import (
+	"base/pkg/abstract_types"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestMissingState(t *testing.T) {
+	emptySlot := common.HexToHash("0x02")
+	stateRoot, proof := newTestProof(t, testSlot, emptySlot)
+	tracer := NewDiagnosticTracer()
+	tracer.accounts[testContract] = true
+	tracer.slots[StorageSlot{Address: testContract, Slot: testSlot}] = true
+	tracer.slots[StorageSlot{Address: testContract, Slot: emptySlot}] = true
+
+	db, err := GenerateProofDB(proof.Proof.AccountProof)
+	if err != nil {
+		t.Fatalf("Failed to generate proof db: %v", err)
+	}
+	missingAccounts, missingStorage := tracer.MissingState(stateRoot, db)
+	if len(missingAccounts) != 0 || len(missingStorage) != 2 || missingStorage[0].Slot != testSlot {
+		t.Fatalf("Expected the slots without storage proofs to be missing, got %v and %v", missingAccounts, missingStorage)
+	}
+
+	db, err = GenerateMultiProofDB(&abstract_types.MultiProof{Accounts: []abstract_types.Account{*proof}})
+	if err != nil {
+		t.Fatalf("Failed to generate proof db: %v", err)
+	}
+	missingAccounts, missingStorage = tracer.MissingState(stateRoot, db)
+	if len(missingAccounts) != 0 || len(missingStorage) != 0 {
+		t.Fatalf("Expected the proven state to be resolved, got %v and %v", missingAccounts, missingStorage)
+	}
+	missingAccounts, _ = tracer.MissingState(common.HexToHash("0x01"), db)
+	if len(missingAccounts) != 1 || missingAccounts[0] != testContract {
+		t.Fatalf("Expected the account under an unknown state root to be missing, got %v", missingAccounts)
+	}
+}
+
+func TestCompareReceipts(t *testing.T) {
+	expected := []*types.Receipt{
+		{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, TxHash: common.HexToHash("0x01")},
+		{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 42000, TxHash: common.HexToHash("0x02")},
+	}
+	actual := []*types.Receipt{
+		{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, TxHash: common.HexToHash("0x01")},
+		{Status: types.ReceiptStatusFailed, CumulativeGasUsed: 42000, TxHash: common.HexToHash("0x02")},
+	}
+	if divergence := CompareReceipts(expected, expected); divergence != nil {
+		t.Fatalf("Expected equal receipts, got %+v", divergence)
+	}
+	divergence := CompareReceipts(expected, actual)
+	if divergence == nil || divergence.Index != 1 || divergence.Field != "status" {
+		t.Fatalf("Expected the status of the second receipt to diverge, got %+v", divergence)
+	}
+	divergence = CompareReceipts(expected, actual[:1])
+	if divergence == nil || divergence.Index != 1 || divergence.Field != "missing" {
+		t.Fatalf("Expected the second receipt to be missing, got %+v", divergence)
+	}
+}
@@ -0,0 +1,296 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/pkg/errors"
+)
+
+// EIP712Domain is the domain of EIP-712 typed data, the unset fields are left out of the domain
+// separator
+type EIP712Domain struct {
+	Name              string
+	Version           string
+	ChainId           *big.Int
+	VerifyingContract *common.Address
+}
+
+// TypedDataSigner is implemented by signers that can only sign EIP-712 typed data on their side
+// (e.g. `eth_signTypedData_v4` of a remote signer), rather than its hash
+type TypedDataSigner interface {
+	Signer
+	// SignTypedData signs the EIP-712 hash of typed data and returns the 65-byte [R || S || V] signature
+	SignTypedData(ctx context.Context, typedData *apitypes.TypedData) ([]byte, error)
+}
+
+var (
+	addressType = reflect.TypeOf(common.Address{})
+	hashType    = reflect.TypeOf(common.Hash{})
+	bigIntType  = reflect.TypeOf(&big.Int{})
+)
+
+// TypedData returns the EIP-712 typed data of message, a struct or pointer to a struct. Its struct
+// types are derived from the Go structs: a type is named after its Go type, and its fields after
+// their json tags, in the order of the Go fields. The typed data marshals to the JSON expected by
+// `eth_signTypedData_v4`.
+func TypedData(domain EIP712Domain, message any) (*apitypes.TypedData, error) {
+	value := reflect.Indirect(reflect.ValueOf(message))
+	if value.Kind() != reflect.Struct {
+		return nil, errors.Errorf("typed data message of type %T is not a struct", message)
+	}
+
+	types := apitypes.Types{}
+	primaryType, err := eip712Type(value.Type(), types)
+	if err != nil {
+		return nil, err
+	}
+	data, err := eip712Value(value)
+	if err != nil {
+		return nil, err
+	}
+
+	typedDomain := apitypes.TypedDataDomain{Name: domain.Name, Version: domain.Version}
+	domainType := []apitypes.Type{}
+	if domain.Name != "" {
+		domainType = append(domainType, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if domain.Version != "" {
+		domainType = append(domainType, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if domain.ChainId != nil {
+		domainType = append(domainType, apitypes.Type{Name: "chainId", Type: "uint256"})
+		typedDomain.ChainId = (*math.HexOrDecimal256)(domain.ChainId)
+	}
+	if domain.VerifyingContract != nil {
+		domainType = append(domainType, apitypes.Type{Name: "verifyingContract", Type: "address"})
+		typedDomain.VerifyingContract = domain.VerifyingContract.Hex()
+	}
+	types["EIP712Domain"] = domainType
+
+	return &apitypes.TypedData{
+		Types:       types,
+		PrimaryType: primaryType,
+		Domain:      typedDomain,
+		Message:     data.(map[string]interface{}),
+	}, nil
+}
+
+// EIP712TypeString returns the EIP-712 encoding of the struct type of message and the struct types
+// it references, as hashed into the type hash, e.g. for the type hash constants of a contract
+func EIP712TypeString(message any) (string, error) {
+	typedData, err := TypedData(EIP712Domain{}, message)
+	if err != nil {
+		return "", err
+	}
+	return string(typedData.EncodeType(typedData.PrimaryType)), nil
+}
+
+// TypedDataHash returns the EIP-712 hash of message under the domain, as signed by `eth_signTypedData_v4`
+func TypedDataHash(domain EIP712Domain, message any) (common.Hash, error) {
+	typedData, err := TypedData(domain, message)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash, _, err := apitypes.TypedDataAndHash(*typedData)
+	if err != nil {
+		return common.Hash{}, errors.WithStack(err)
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// SignTypedData signs message as EIP-712 typed data under the domain
+func SignTypedData(ctx context.Context, signer Signer, domain EIP712Domain, message any) (*SignedComponents, error) {
+	typedData, err := TypedData(domain, message)
+	if err != nil {
+		return nil, err
+	}
+	hash, _, err := apitypes.TypedDataAndHash(*typedData)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	messageHash := common.BytesToHash(hash)
+
+	var signature []byte
+	if typedDataSigner, ok := signer.(TypedDataSigner); ok {
+		signature, err = typedDataSigner.SignTypedData(ctx, typedData)
+	} else {
+		signature, err = signer.SignHash(ctx, messageHash)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newSignedComponents(signer, messageHash, signature)
+}
+
+// RecoverTypedDataSigner rebuilds the EIP-712 hash of message under the domain, checks that it is the
+// hash that was signed and returns the address that signed it
+func RecoverTypedDataSigner(domain EIP712Domain, message any, signed SignedComponents) (common.Address, error) {
+	messageHash, err := TypedDataHash(domain, message)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return recoverHashSigner(messageHash, signed)
+}
+
+// VerifyTypedDataSignature checks that signed is the EIP-712 signature of message under the domain
+// by the expected address
+func VerifyTypedDataSignature(domain EIP712Domain, message any, signed SignedComponents, expected common.Address) error {
+	signer, err := RecoverTypedDataSigner(domain, message, signed)
+	if err != nil {
+		return err
+	}
+	if signer != expected {
+		return errors.Errorf("typed data is signed by %s instead of %s", signer.Hex(), expected.Hex())
+	}
+	return nil
+}
+
+// eip712Type returns the EIP-712 type of a Go type, adding the struct types to types
+func eip712Type(t reflect.Type, types apitypes.Types) (string, error) {
+	switch {
+	case t == addressType:
+		return "address", nil
+	case t == hashType:
+		return "bytes32", nil
+	case t == bigIntType:
+		return "uint256", nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return fmt.Sprintf("uint%d", t.Bits()), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return fmt.Sprintf("int%d", t.Bits()), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+		elemType, err := eip712Type(t.Elem(), types)
+		if err != nil {
+			return "", err
+		}
+		return elemType + "[]", nil
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Len() <= 32 {
+			return fmt.Sprintf("bytes%d", t.Len()), nil
+		}
+		elemType, err := eip712Type(t.Elem(), types)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s[%d]", elemType, t.Len()), nil
+	case reflect.Pointer:
+		return eip712Type(t.Elem(), types)
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return "", errors.New("anonymous structs have no EIP-712 type name")
+		}
+		if _, ok := types[name]; ok {
+			return name, nil
+		}
+		// Register the type before its fields, for the recursive types
+		types[name] = []apitypes.Type{}
+		fields := []apitypes.Type{}
+		for _, field := range eip712Fields(t) {
+			fieldType, err := eip712Type(field.Type, types)
+			if err != nil {
+				return "", errors.Wrapf(err, "field %s of %s", field.Name, name)
+			}
+			fields = append(fields, apitypes.Type{Name: eip712FieldName(field), Type: fieldType})
+		}
+		types[name] = fields
+		return name, nil
+	}
+	return "", errors.Errorf("type %s has no EIP-712 type", t)
+}
+
+// eip712Value returns a Go value in the form expected by apitypes for its EIP-712 type
+func eip712Value(value reflect.Value) (interface{}, error) {
+	t := value.Type()
+	switch {
+	case t == addressType:
+		return value.Interface().(common.Address).Hex(), nil
+	case t == hashType:
+		return value.Interface().(common.Hash).Hex(), nil
+	case t == bigIntType:
+		if value.IsNil() {
+			return nil, errors.New("nil integer")
+		}
+		return (*math.HexOrDecimal256)(value.Interface().(*big.Int)), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return value.Bool(), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return (*math.HexOrDecimal256)(new(big.Int).SetUint64(value.Uint())), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return (*math.HexOrDecimal256)(big.NewInt(value.Int())), nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && (t.Kind() == reflect.Slice || t.Len() <= 32) {
+			bytes := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(bytes), value)
+			return hexutil.Bytes(bytes), nil
+		}
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			item, err := eip712Value(value.Index(i))
+			if err != nil {
+				return nil, errors.Wrapf(err, "item %d", i)
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Pointer:
+		if value.IsNil() {
+			return nil, errors.Errorf("nil %s", t)
+		}
+		return eip712Value(value.Elem())
+	case reflect.Struct:
+		data := map[string]interface{}{}
+		for _, field := range eip712Fields(t) {
+			fieldValue, err := eip712Value(value.FieldByIndex(field.Index))
+			if err != nil {
+				return nil, errors.Wrapf(err, "field %s of %s", field.Name, t.Name())
+			}
+			data[eip712FieldName(field)] = fieldValue
+		}
+		return data, nil
+	}
+	return nil, errors.Errorf("type %s has no EIP-712 type", t)
+}
+
+// eip712Fields returns the exported fields of a struct which are not ignored by their json tag
+func eip712Fields(t reflect.Type) []reflect.StructField {
+	fields := []reflect.StructField{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous || field.Tag.Get("json") == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func eip712FieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
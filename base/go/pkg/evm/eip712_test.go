@@ -0,0 +1,68 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The example of EIP-712
+type Person struct {
+	Name   string         `json:"name"`
+	Wallet common.Address `json:"wallet"`
+}
+
+type Mail struct {
+	From     Person `json:"from"`
+	To       Person `json:"to"`
+	Contents string `json:"contents"`
+}
+
+func TestSignTypedData(t *testing.T) {
+	verifyingContract := common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+	domain := EIP712Domain{Name: "Ether Mail", Version: "1", ChainId: big.NewInt(1), VerifyingContract: &verifyingContract}
+	mail := Mail{
+		From:     Person{Name: "Cow", Wallet: common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")},
+		To:       Person{Name: "Bob", Wallet: common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")},
+		Contents: "Hello, Bob!",
+	}
+
+	typeString, err := EIP712TypeString(mail)
+	if err != nil {
+		t.Fatalf("Failed to encode type: %v", err)
+	}
+	if typeString != "Mail(Person from,Person to,string contents)Person(string name,address wallet)" {
+		t.Fatalf("Unexpected type encoding %s", typeString)
+	}
+	hash, err := TypedDataHash(domain, mail)
+	if err != nil {
+		t.Fatalf("Failed to hash typed data: %v", err)
+	}
+	if hash != common.HexToHash("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2") {
+		t.Fatalf("Unexpected typed data hash %s", hash.Hex())
+	}
+
+	signer := NewPrivateKeySigner(crypto.ToECDSAUnsafe(crypto.Keccak256([]byte("cow"))))
+	signed, err := SignTypedData(context.Background(), signer, domain, &mail)
+	if err != nil {
+		t.Fatalf("Failed to sign typed data: %v", err)
+	}
+	if signed.V != 28 || signed.R != "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" || signed.S != "0x07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" {
+		t.Fatalf("Unexpected signature %+v", signed)
+	}
+	err = VerifyTypedDataSignature(domain, mail, *signed, signer.Address())
+	if err != nil {
+		t.Fatalf("Failed to verify typed data signature: %v", err)
+	}
+
+	mail.Contents = "Hello, Alice!"
+	if VerifyTypedDataSignature(domain, mail, *signed, signer.Address()) == nil {
+		t.Fatalf("Expected a signature of other typed data to be rejected")
+	}
+	if VerifySignedComponents(mail, *signed, signer.Address()) == nil {
+		t.Fatalf("Expected a typed data signature to be rejected as an RLP signature")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -17,6 +18,7 @@ import (
 	"github.com/pkg/errors"
 )
 
+// CreateConfig creates the config of a devnet running every fork up to Cancun from genesis
 func CreateConfig(chainId *big.Int) *params.ChainConfig {
 	shanghaiTime := uint64(0)
 	cancunTime := uint64(0)
@@ -45,12 +47,22 @@ func CreateConfig(chainId *big.Int) *params.ChainConfig {
 
 // CreateEVM creates and initializes an EVM instance with the given parameters
 //
-//   - chainId: Chain ID
+//   - chainId: Chain ID, which must have a registered chain config
 //   - stateRoot: State root hash of the EVM
 //   - blockHeader: Initial block header
 //   - accountProofs: Account proofs, including account proof, storage proof, and code
+//   - proofNodes: Trie nodes shared by the account proofs of a multiproof, nil if the account proofs hold all their nodes
 //   - getBlockHash: Function to get the block hash
-func CreateEVM(chainId *big.Int, stateRoot common.Hash, blockHeader *types.Header, accountProofs []abstract_types.Account, getBlockHash func(u uint64) common.Hash) (*vm.EVM, *state.StateDB, error) {
+func CreateEVM(chainId *big.Int, stateRoot common.Hash, blockHeader *types.Header, accountProofs []abstract_types.Account, proofNodes [][]byte, getBlockHash func(u uint64) common.Hash) (*vm.EVM, *state.StateDB, error) {
+	return CreateEVMWithOverrides(chainId, stateRoot, blockHeader, accountProofs, proofNodes, getBlockHash, nil, nil)
+}
+
+// CreateEVMWithOverrides creates an EVM instance like CreateEVM, and applies the state and block
+// overrides of an eth_call after verifying the account proofs
+//
+//   - stateOverrides: Accounts overridden in the state, nil for none
+//   - blockOverrides: Fields overridden in the block context, nil for none
+func CreateEVMWithOverrides(chainId *big.Int, stateRoot common.Hash, blockHeader *types.Header, accountProofs []abstract_types.Account, proofNodes [][]byte, getBlockHash func(u uint64) common.Hash, stateOverrides abstract_types.StateOverride, blockOverrides *abstract_types.BlockOverrides) (*vm.EVM, *state.StateDB, error) {
 	// Related variables
 	hash := make([]byte, 32)
 	hasher := crypto.NewKeccakState()
@@ -60,18 +72,28 @@ func CreateEVM(chainId *big.Int, stateRoot common.Hash, blockHeader *types.Heade
 	header := *blockHeader
 	header.Root = stateRoot
 
-	chainConfig := CreateConfig(chainId)
+	chainConfig, err := ChainConfig(chainId)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Write block header
 	rawdb.WriteHeader(memdb, &header)
+	// Verify proofs
+	err = VerifyMultiProof(stateRoot, &abstract_types.MultiProof{Accounts: accountProofs, Nodes: proofNodes})
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	// Set the shared proof nodes
+	for _, proof := range proofNodes {
+		hasher.Reset()
+		hasher.Write(proof)
+		hasher.Read(hash)
+		rawdb.WriteLegacyTrieNode(memdb, common.BytesToHash(hash), proof)
+	}
 	// Set account and storage to Memory Database, this part is take https://github.com/ethereum/go-ethereum/blob/master/core/stateless/database.go#L29 as implementation reference
 	for _, v := range accountProofs {
-		// Verify proof
-		err := VerifyProof(stateRoot, v.Proof.Addr, &v)
-		if err != nil {
-			return nil, nil, errors.WithStack(err)
-		}
-
 		// Set account
 		for _, proof := range v.Proof.AccountProof {
 			hasher.Reset()
@@ -105,8 +127,17 @@ func CreateEVM(chainId *big.Int, stateRoot common.Hash, blockHeader *types.Heade
 		fmt.Printf("error initializing stateDB: %+v\n", err)
 		return nil, nil, errors.WithStack(err)
 	}
+	err = ApplyStateOverrides(stateDB, stateOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+	// BLOBBASEFEE is zero for headers without blob gas
+	blobBaseFee := big.NewInt(0)
+	if header.ExcessBlobGas != nil && chainConfig.BlobScheduleConfig != nil && chainConfig.IsCancun(header.Number, header.Time) {
+		blobBaseFee = eip4844.CalcBlobFee(chainConfig, &header)
+	}
 	// stateDBWrapper := NewStateDBWrapper(stateDB)
-	evm := vm.NewEVM(vm.BlockContext{
+	blockContext := vm.BlockContext{
 		CanTransfer: core.CanTransfer,
 		Transfer:    core.Transfer,
 		Coinbase:    header.Coinbase,
@@ -117,8 +148,10 @@ func CreateEVM(chainId *big.Int, stateRoot common.Hash, blockHeader *types.Heade
 		BaseFee:     header.BaseFee,
 		GetHash:     getBlockHash,
 		Random:      &header.MixDigest,
-		BlobBaseFee: big.NewInt(0),
-	}, stateDB, chainConfig, vm.Config{
+		BlobBaseFee: blobBaseFee,
+	}
+	ApplyBlockOverrides(&blockContext, blockOverrides)
+	evm := vm.NewEVM(blockContext, stateDB, chainConfig, vm.Config{
 		StatelessSelfValidation: true,
 		NoBaseFee:               false,
 		ExtraEips:               []int{}, // Added more EIPs if needed
@@ -0,0 +1,86 @@
+package evm
+
+import (
+	"base/pkg/abstract_types"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+	"github.com/pkg/errors"
+)
+
+// ApplyStateOverrides overrides the fields of the accounts in the state, like eth_call
+func ApplyStateOverrides(stateDB *state.StateDB, overrides abstract_types.StateOverride) error {
+	// Apply the overrides in a fixed order, so that errors are reproducible
+	addresses := make([]common.Address, 0, len(overrides))
+	for address := range overrides {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Cmp(addresses[j]) < 0
+	})
+
+	for _, address := range addresses {
+		account := overrides[address]
+		if account.State != nil && account.StateDiff != nil {
+			return errors.Errorf("account %s: both state and stateDiff are overridden", address)
+		}
+		if account.Nonce != nil {
+			stateDB.SetNonce(address, uint64(*account.Nonce), tracing.NonceChangeUnspecified)
+		}
+		if account.Code != nil {
+			stateDB.SetCode(address, *account.Code)
+		}
+		if account.Balance != nil {
+			balance, overflow := uint256.FromBig(account.Balance.ToInt())
+			if overflow {
+				return errors.Errorf("account %s: balance override %s overflows 256 bits", address, account.Balance)
+			}
+			stateDB.SetBalance(address, balance, tracing.BalanceChangeUnspecified)
+		}
+		if account.State != nil {
+			stateDB.SetStorage(address, account.State)
+		}
+		for slot, value := range account.StateDiff {
+			stateDB.SetState(address, slot, value)
+		}
+	}
+	// The overrides behave as if they were made by a transaction before the call
+	stateDB.Finalise(false)
+	return nil
+}
+
+// ApplyBlockOverrides overrides the fields of the block context, like eth_call. A Number override
+// doesn't move the hashes served by GetHash, so it must not be combined with ancestor headers.
+func ApplyBlockOverrides(blockContext *vm.BlockContext, overrides *abstract_types.BlockOverrides) {
+	if overrides == nil {
+		return
+	}
+	if overrides.Number != nil {
+		blockContext.BlockNumber = overrides.Number.ToInt()
+	}
+	if overrides.Difficulty != nil {
+		blockContext.Difficulty = overrides.Difficulty.ToInt()
+	}
+	if overrides.Time != nil {
+		blockContext.Time = uint64(*overrides.Time)
+	}
+	if overrides.GasLimit != nil {
+		blockContext.GasLimit = uint64(*overrides.GasLimit)
+	}
+	if overrides.FeeRecipient != nil {
+		blockContext.Coinbase = *overrides.FeeRecipient
+	}
+	if overrides.PrevRandao != nil {
+		blockContext.Random = overrides.PrevRandao
+	}
+	if overrides.BaseFeePerGas != nil {
+		blockContext.BaseFee = overrides.BaseFeePerGas.ToInt()
+	}
+	if overrides.BlobBaseFee != nil {
+		blockContext.BlobBaseFee = overrides.BlobBaseFee.ToInt()
+	}
+}
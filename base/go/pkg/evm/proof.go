@@ -2,29 +2,67 @@ package evm
 
 import (
 	"base/pkg/abstract_types"
+	"bytes"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/pkg/errors"
 )
 
 func GenerateProofDB(proofs [][]byte) (ethdb.KeyValueReader, error) {
 	db := memorydb.New()
-	for _, proof := range proofs {
-		key := crypto.Keccak256(proof)
-		err := db.Put(key, proof)
+	err := putProofNodes(db, proofs)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// GenerateMultiProofDB returns a database of all the trie nodes of a multiproof
+func GenerateMultiProofDB(multiProof *abstract_types.MultiProof) (ethdb.KeyValueReader, error) {
+	return generateAccountProofDB(multiProof.Nodes, multiProof.Accounts)
+}
+
+// generateAccountProofDB returns a database of the shared nodes and the nodes of the account proofs
+func generateAccountProofDB(nodes [][]byte, accounts []abstract_types.Account) (ethdb.KeyValueReader, error) {
+	db := memorydb.New()
+	err := putProofNodes(db, nodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		err = putProofNodes(db, account.Proof.AccountProof)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, err
+		}
+		for _, storageProof := range account.Proof.StorageProof {
+			err = putProofNodes(db, storageProof.Proof)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 	return db, nil
 }
 
+func putProofNodes(db ethdb.KeyValueWriter, nodes [][]byte) error {
+	for _, node := range nodes {
+		err := db.Put(crypto.Keccak256(node), node)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
 // DecodeProofs decodes the proofs with the state root and address
 func DecodeProofs(stateRoot common.Hash, address common.Address, proof *abstract_types.AccountProof) ([]byte, error) {
 	db, err := GenerateProofDB(proof.AccountProof)
@@ -43,28 +81,178 @@ func VerifyCode(code []byte, codeHash common.Hash) error {
 	return errors.WithStack(errors.New("code verification failed"))
 }
 
-// VerifyProof verifies the account proofs with the state root and address
+// VerifyProof verifies the account proofs with the state root and address: the account fields
+// against the decoded account, the code against the code hash, and every storage slot against the
+// storage root of the account
 func VerifyProof(stateRoot common.Hash, address common.Address, proof *abstract_types.Account) error {
-	accountRLP, err := DecodeProofs(stateRoot, address, &proof.Proof)
+	db, err := generateAccountProofDB(nil, []abstract_types.Account{*proof})
 	if err != nil {
-		return errors.WithStack(err)
+		return err
+	}
+	return verifyAccount(stateRoot, address, proof, db)
+}
+
+// VerifyMultiProof verifies every account of a multiproof like VerifyProof, looking the trie nodes
+// up in the shared nodes as well as in the nodes of each account
+func VerifyMultiProof(stateRoot common.Hash, multiProof *abstract_types.MultiProof) error {
+	db, err := generateAccountProofDB(multiProof.Nodes, multiProof.Accounts)
+	if err != nil {
+		return err
+	}
+	for i := range multiProof.Accounts {
+		account := &multiProof.Accounts[i]
+		err = verifyAccount(stateRoot, account.Proof.Addr, account, db)
+		if err != nil {
+			return err
+		}
 	}
-	if len(accountRLP) == 0 &&
-		proof.Proof.Balance.Cmp(big.NewInt(0)) == 0 &&
-		proof.Proof.Nonce.Cmp(big.NewInt(0)) == 0 {
+	return nil
+}
+
+func verifyAccount(stateRoot common.Hash, address common.Address, proof *abstract_types.Account, db ethdb.KeyValueReader) error {
+	accountRLP, err := trie.VerifyProof(stateRoot, crypto.Keccak256(address.Bytes()), db)
+	if err != nil {
+		return errors.Wrapf(err, "account %s: invalid account proof", address)
+	}
+
+	// An account missing from the state is empty, and so is all its storage
+	if len(accountRLP) == 0 {
+		if !isZero(proof.Proof.Balance) || !isZero(proof.Proof.Nonce) || len(proof.Code) > 0 {
+			return errors.Errorf("account %s: absent from the state but claimed non-empty", address)
+		}
+		for _, storageProof := range proof.Proof.StorageProof {
+			if storageProof.Value != ([32]byte{}) {
+				return errors.Errorf("account %s: storage slot %s: absent account claimed to store %s", address, common.Hash(storageProof.Key), common.Hash(storageProof.Value))
+			}
+		}
 		return nil
 	}
-	var accountInformation [][]byte
-	err = rlp.DecodeBytes(accountRLP, &accountInformation)
+
+	var account types.StateAccount
+	err = rlp.DecodeBytes(accountRLP, &account)
 	if err != nil {
-		return errors.WithStack(err)
+		return errors.Wrapf(err, "account %s: invalid account encoding", address)
+	}
+	if proof.Proof.Balance == nil || proof.Proof.Balance.Cmp(account.Balance.ToBig()) != 0 {
+		return errors.Errorf("account %s: claimed balance %v does not match the proven balance %s", address, proof.Proof.Balance, account.Balance)
+	}
+	if proof.Proof.Nonce == nil || !proof.Proof.Nonce.IsUint64() || proof.Proof.Nonce.Uint64() != account.Nonce {
+		return errors.Errorf("account %s: claimed nonce %v does not match the proven nonce %d", address, proof.Proof.Nonce, account.Nonce)
+	}
+	if proof.Proof.StorageHash != account.Root {
+		return errors.Errorf("account %s: claimed storage hash %s does not match the proven storage root %s", address, proof.Proof.StorageHash, account.Root)
+	}
+	codeHash := common.BytesToHash(account.CodeHash)
+	if proof.Proof.CodeHash != codeHash {
+		return errors.Errorf("account %s: claimed code hash %s does not match the proven code hash %s", address, proof.Proof.CodeHash, codeHash)
 	}
 
 	// Verify code with code hash that decoded from proof
-	err = VerifyCode(proof.Code, common.BytesToHash(accountInformation[3]))
+	err = VerifyCode(proof.Code, codeHash)
+	if err != nil {
+		return errors.Wrapf(err, "account %s", address)
+	}
+
+	for _, storageProof := range proof.Proof.StorageProof {
+		err = verifyStorage(account.Root, &storageProof, db)
+		if err != nil {
+			return errors.Wrapf(err, "account %s: storage slot %s", address, common.Hash(storageProof.Key))
+		}
+	}
+
+	return nil
+}
+
+// VerifyStorageProof verifies a storage slot value with the storage root of its account
+func VerifyStorageProof(storageRoot common.Hash, storageProof *abstract_types.StorageProof) error {
+	db, err := GenerateProofDB(storageProof.Proof)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	return verifyStorage(storageRoot, storageProof, db)
+}
+
+func verifyStorage(storageRoot common.Hash, storageProof *abstract_types.StorageProof, db ethdb.KeyValueReader) error {
+	valueRLP, err := trie.VerifyProof(storageRoot, crypto.Keccak256(storageProof.Key[:]), db)
+	if err != nil {
+		return errors.Wrap(err, "invalid storage proof")
+	}
 
+	// Slots missing from the storage trie hold zero
+	value := common.Hash{}
+	if len(valueRLP) > 0 {
+		var valueBytes []byte
+		err = rlp.DecodeBytes(valueRLP, &valueBytes)
+		if err != nil {
+			return errors.Wrap(err, "invalid storage value encoding")
+		}
+		if len(valueBytes) > common.HashLength {
+			return errors.Errorf("storage value of %d bytes", len(valueBytes))
+		}
+		value = common.BytesToHash(valueBytes)
+	}
+	if value != common.Hash(storageProof.Value) {
+		return errors.Errorf("claimed value %s does not match the proven value %s", common.Hash(storageProof.Value), value)
+	}
 	return nil
 }
+
+// ProveListItem builds the trie of a list of a block, like its transactions or receipts, and
+// returns its root and the proof of the item at index, from the root node down
+func ProveListItem(list types.DerivableList, index int) (common.Hash, [][]byte, error) {
+	if index < 0 || index >= list.Len() {
+		return common.Hash{}, nil, errors.Errorf("item %d is out of a list of %d items", index, list.Len())
+	}
+	listTrie := trie.NewEmpty(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	var value bytes.Buffer
+	for i := 0; i < list.Len(); i++ {
+		value.Reset()
+		list.EncodeIndex(i, &value)
+		err := listTrie.Update(rlp.AppendUint64(nil, uint64(i)), common.CopyBytes(value.Bytes()))
+		if err != nil {
+			return common.Hash{}, nil, errors.WithStack(err)
+		}
+	}
+
+	proof := &orderedProof{}
+	err := listTrie.Prove(rlp.AppendUint64(nil, uint64(index)), proof)
+	if err != nil {
+		return common.Hash{}, nil, errors.WithStack(err)
+	}
+	return listTrie.Hash(), proof.nodes, nil
+}
+
+// VerifyListItemProof verifies the proof of the item at index of a list trie and returns the
+// encoding of the item
+func VerifyListItemProof(root common.Hash, index uint64, proof [][]byte) ([]byte, error) {
+	db, err := GenerateProofDB(proof)
+	if err != nil {
+		return nil, err
+	}
+	value, err := trie.VerifyProof(root, rlp.AppendUint64(nil, index), db)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid list item proof")
+	}
+	if len(value) == 0 {
+		return nil, errors.Errorf("item %d is not in the list of root %s", index, root)
+	}
+	return value, nil
+}
+
+// orderedProof collects the nodes of a trie proof in order
+type orderedProof struct {
+	nodes [][]byte
+}
+
+func (p *orderedProof) Put(key []byte, value []byte) error {
+	p.nodes = append(p.nodes, common.CopyBytes(value))
+	return nil
+}
+
+func (p *orderedProof) Delete(key []byte) error {
+	return errors.New("proof nodes can't be deleted")
+}
+
+func isZero(value *big.Int) bool {
+	return value == nil || value.Sign() == 0
+}
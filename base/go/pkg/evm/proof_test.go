@@ -0,0 +1,232 @@
+package evm
+
+import (
+	"base/pkg/abstract_types"
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+)
+
+var (
+	testContract = common.HexToAddress("0x1000")
+	testCode     = []byte{0x60, 0x00, 0x54, 0x00}
+	testSlot     = common.HexToHash("0x01")
+	testValue    = common.HexToHash("0x2a")
+)
+
+func proofNodes(t *testing.T, trie state.Trie, key []byte) [][]byte {
+	proofDB := memorydb.New()
+	err := trie.Prove(key, proofDB)
+	if err != nil {
+		t.Fatalf("Failed to prove %x: %v", key, err)
+	}
+	nodes := [][]byte{}
+	iterator := proofDB.NewIterator(nil, nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		nodes = append(nodes, common.CopyBytes(iterator.Value()))
+	}
+	return nodes
+}
+
+// newTestProof returns a state root and the proof of testContract, holding testValue at testSlot,
+// with the proofs of the given storage slots
+func newTestProof(t *testing.T, slots ...common.Hash) (common.Hash, *abstract_types.Account) {
+	stateDB, err := state.New(types.EmptyRootHash, state.NewDatabase(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil), nil))
+	if err != nil {
+		t.Fatalf("Failed to create state: %v", err)
+	}
+	stateDB.SetCode(testContract, testCode)
+	stateDB.SetNonce(testContract, 3, tracing.NonceChangeUnspecified)
+	stateDB.SetBalance(testContract, uint256.NewInt(1000), tracing.BalanceChangeUnspecified)
+	stateDB.SetState(testContract, testSlot, testValue)
+	stateRoot, err := stateDB.Commit(0, false, false)
+	if err != nil {
+		t.Fatalf("Failed to commit state: %v", err)
+	}
+
+	accountTrie, err := stateDB.Database().OpenTrie(stateRoot)
+	if err != nil {
+		t.Fatalf("Failed to open state trie: %v", err)
+	}
+	account, err := accountTrie.GetAccount(testContract)
+	if err != nil {
+		t.Fatalf("Failed to get account: %v", err)
+	}
+	storageTrie, err := stateDB.Database().OpenStorageTrie(stateRoot, testContract, account.Root, accountTrie)
+	if err != nil {
+		t.Fatalf("Failed to open storage trie: %v", err)
+	}
+	storageProofs := []abstract_types.StorageProof{}
+	for _, slot := range slots {
+		value := common.Hash{}
+		if slot == testSlot {
+			value = testValue
+		}
+		storageProofs = append(storageProofs, abstract_types.StorageProof{
+			Key:   slot,
+			Value: value,
+			Proof: proofNodes(t, storageTrie, slot.Bytes()),
+		})
+	}
+
+	return stateRoot, &abstract_types.Account{
+		Proof: abstract_types.AccountProof{
+			Addr:         testContract,
+			AccountProof: proofNodes(t, accountTrie, testContract.Bytes()),
+			Balance:      big.NewInt(1000),
+			CodeHash:     crypto.Keccak256Hash(testCode),
+			Nonce:        big.NewInt(3),
+			StorageHash:  account.Root,
+			StorageProof: storageProofs,
+		},
+		Code: testCode,
+	}
+}
+
+func TestVerifyProof(t *testing.T) {
+	emptySlot := common.HexToHash("0x02")
+	stateRoot, proof := newTestProof(t, testSlot, emptySlot)
+	err := VerifyProof(stateRoot, testContract, proof)
+	if err != nil {
+		t.Fatalf("Failed to verify proof: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		tamper   func(proof *abstract_types.Account)
+		expected string
+	}{
+		{"balance", func(proof *abstract_types.Account) { proof.Proof.Balance = big.NewInt(1001) }, "claimed balance 1001"},
+		{"nonce", func(proof *abstract_types.Account) { proof.Proof.Nonce = big.NewInt(4) }, "claimed nonce 4"},
+		{"storage hash", func(proof *abstract_types.Account) { proof.Proof.StorageHash = types.EmptyRootHash }, "claimed storage hash"},
+		{"code", func(proof *abstract_types.Account) { proof.Code = []byte{0x00} }, "code verification failed"},
+		{"storage value", func(proof *abstract_types.Account) { proof.Proof.StorageProof[0].Value = common.HexToHash("0x2b") }, "storage slot " + testSlot.Hex() + ": claimed value"},
+		{"empty slot", func(proof *abstract_types.Account) { proof.Proof.StorageProof[1].Value = testValue }, "storage slot " + emptySlot.Hex() + ": claimed value"},
+		{"storage proof", func(proof *abstract_types.Account) {
+			proof.Proof.StorageProof[0].Proof = nil
+			proof.Proof.StorageProof[1].Proof = nil
+		}, "storage slot " + testSlot.Hex() + ": invalid storage proof"},
+	}
+	for _, test := range tests {
+		_, proof := newTestProof(t, testSlot, emptySlot)
+		test.tamper(proof)
+		err := VerifyProof(stateRoot, testContract, proof)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Fatalf("Expected a tampered %s to be rejected with %q, got %v", test.name, test.expected, err)
+		}
+	}
+}
+
+func TestVerifyProofOfAbsentAccount(t *testing.T) {
+	stateRoot, proof := newTestProof(t)
+	absent := common.HexToAddress("0x2000")
+	absentProof := &abstract_types.Account{
+		Proof: abstract_types.AccountProof{
+			Addr:         absent,
+			AccountProof: proof.Proof.AccountProof,
+			Balance:      big.NewInt(0),
+			Nonce:        big.NewInt(0),
+			StorageProof: []abstract_types.StorageProof{{Key: testSlot}},
+		},
+	}
+	err := VerifyProof(stateRoot, absent, absentProof)
+	if err != nil {
+		t.Fatalf("Failed to verify the proof of an absent account: %v", err)
+	}
+
+	absentProof.Proof.StorageProof[0].Value = testValue
+	err = VerifyProof(stateRoot, absent, absentProof)
+	if err == nil || !strings.Contains(err.Error(), "absent account claimed to store") {
+		t.Fatalf("Expected storage of an absent account to be rejected, got %v", err)
+	}
+	absentProof.Proof.StorageProof = nil
+	absentProof.Proof.Balance = big.NewInt(1)
+	err = VerifyProof(stateRoot, absent, absentProof)
+	if err == nil || !strings.Contains(err.Error(), "absent from the state") {
+		t.Fatalf("Expected a balance of an absent account to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyMultiProof(t *testing.T) {
+	stateRoot, proof := newTestProof(t, testSlot)
+	absent := abstract_types.Account{
+		Proof: abstract_types.AccountProof{
+			Addr:         common.HexToAddress("0x2000"),
+			AccountProof: proof.Proof.AccountProof,
+			Balance:      big.NewInt(0),
+			Nonce:        big.NewInt(0),
+		},
+	}
+	multiProof := abstract_types.NewMultiProof([]abstract_types.Account{*proof, absent})
+	if len(multiProof.Nodes) != len(proof.Proof.AccountProof)+len(proof.Proof.StorageProof[0].Proof) {
+		t.Fatalf("Expected the shared account proof nodes to be stored once, got %d nodes", len(multiProof.Nodes))
+	}
+	if len(multiProof.Accounts[0].Proof.AccountProof) != 0 || len(multiProof.Accounts[0].Proof.StorageProof[0].Proof) != 0 {
+		t.Fatalf("Expected the proof nodes to be moved out of the accounts")
+	}
+	err := VerifyMultiProof(stateRoot, multiProof)
+	if err != nil {
+		t.Fatalf("Failed to verify multiproof: %v", err)
+	}
+
+	multiProof.Accounts[0].Proof.StorageProof[0].Value = common.HexToHash("0x2b")
+	err = VerifyMultiProof(stateRoot, multiProof)
+	if err == nil || !strings.Contains(err.Error(), "claimed value") {
+		t.Fatalf("Expected a tampered storage value to be rejected, got %v", err)
+	}
+	multiProof = abstract_types.NewMultiProof([]abstract_types.Account{*proof})
+	multiProof.Nodes = multiProof.Nodes[1:]
+	if VerifyMultiProof(stateRoot, multiProof) == nil {
+		t.Fatalf("Expected a multiproof missing a node to be rejected")
+	}
+}
+
+func TestProveListItem(t *testing.T) {
+	receipts := types.Receipts{}
+	for i := 0; i < 200; i++ {
+		receipts = append(receipts, &types.Receipt{
+			Type:              types.DynamicFeeTxType,
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs:              []*types.Log{{Address: testContract, Data: big.NewInt(int64(i)).Bytes()}},
+		})
+	}
+	for _, index := range []int{0, 1, 127, 128, 199} {
+		root, proof, err := ProveListItem(receipts, index)
+		if err != nil {
+			t.Fatalf("Failed to prove receipt %d: %v", index, err)
+		}
+		if root != types.DeriveSha(receipts, trie.NewStackTrie(nil)) {
+			t.Fatalf("Expected the root of the receipts trie")
+		}
+		value, err := VerifyListItemProof(root, uint64(index), proof)
+		if err != nil {
+			t.Fatalf("Failed to verify the proof of receipt %d: %v", index, err)
+		}
+		expected, _ := receipts[index].MarshalBinary()
+		if !bytes.Equal(value, expected) {
+			t.Fatalf("Expected the proof of receipt %d to prove its encoding", index)
+		}
+		_, err = VerifyListItemProof(root, uint64(index+1), proof)
+		if err == nil {
+			t.Fatalf("Expected the proof of receipt %d to be rejected for receipt %d", index, index+1)
+		}
+	}
+	_, _, err := ProveListItem(receipts, len(receipts))
+	if err == nil {
+		t.Fatalf("Expected an index out of the list to be rejected")
+	}
+}
@@ -1,8 +1,10 @@
 package evm
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"fmt"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -26,9 +28,9 @@ func EIP191Hash(data []byte) common.Hash {
 	return crypto.Keccak256Hash(dataToHash)
 }
 
-// PrivateKeyFromHex converts a hex string to an ECDSA private key.
+// PrivateKeyFromHex converts a hex string, with or without the 0x prefix, to an ECDSA private key.
 func PrivateKeyFromHex(privateKeyHex string) (*ecdsa.PrivateKey, error) {
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -45,12 +47,33 @@ func AddressFromPrivateKey(privateKeyHex string) (*common.Address, error) {
 	return &address, nil
 }
 
+// Signer signs hashes on behalf of an account, without exposing how the key is stored
+type Signer interface {
+	// Address returns the address of the signing account
+	Address() common.Address
+	// SignHash signs a 32-byte hash and returns the 65-byte [R || S || V] signature
+	SignHash(ctx context.Context, hash common.Hash) ([]byte, error)
+}
+
+// TextSigner is implemented by signers that can only sign data with the EIP-191 prefix applied
+// on their side (e.g. `eth_sign` of a remote signer), rather than arbitrary hashes
+type TextSigner interface {
+	Signer
+	// SignText signs the EIP-191 hash of data and returns the 65-byte [R || S || V] signature
+	SignText(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// SignMessage signs the RLP encoding of message with the EIP-191 prefix, using a raw hex private key
 func SignMessage(privateKeyHex string, message any) (*SignedComponents, error) {
-	privateKey, err := PrivateKeyFromHex(privateKeyHex)
+	signer, err := NewPrivateKeySignerFromHex(privateKeyHex)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	return SignMessageWithSigner(context.Background(), signer, message)
+}
 
+// SignMessageWithSigner signs the RLP encoding of message with the EIP-191 prefix
+func SignMessageWithSigner(ctx context.Context, signer Signer, message any) (*SignedComponents, error) {
 	// Encode the message to bytes
 	messageBytes, err := rlp.EncodeToBytes(message)
 	if err != nil {
@@ -61,28 +84,102 @@ func SignMessage(privateKeyHex string, message any) (*SignedComponents, error) {
 	messageHash := EIP191Hash(messageBytes)
 
 	// Sign the message
-	signature, err := crypto.Sign(messageHash.Bytes(), privateKey)
+	var signature []byte
+	if textSigner, ok := signer.(TextSigner); ok {
+		signature, err = textSigner.SignText(ctx, messageBytes)
+	} else {
+		signature, err = signer.SignHash(ctx, messageHash)
+	}
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	return newSignedComponents(signer, messageHash, signature)
+}
 
-	// Extract the r, s, v
-	r := signature[:32]
-	s := signature[32:64]
-	v := signature[64]
+// RecoverSigner rebuilds the RLP+EIP-191 hash of message, checks that it is the hash that was signed
+// and returns the address that signed it
+func RecoverSigner(message any, signed SignedComponents) (common.Address, error) {
+	messageBytes, err := rlp.EncodeToBytes(message)
+	if err != nil {
+		return common.Address{}, errors.WithStack(err)
+	}
+	messageHash := EIP191Hash(messageBytes)
+	return recoverHashSigner(messageHash, signed)
+}
+
+// VerifySignedComponents checks that signed is the signature of message by the expected address
+func VerifySignedComponents(message any, signed SignedComponents, expected common.Address) error {
+	signer, err := RecoverSigner(message, signed)
+	if err != nil {
+		return err
+	}
+	if signer != expected {
+		return errors.Errorf("message is signed by %s instead of %s", signer.Hex(), expected.Hex())
+	}
+	return nil
+}
 
+// newSignedComponents checks that a signature of hash recovers to the signer, and splits it into
+// its components with V as 27 or 28
+func newSignedComponents(signer Signer, hash common.Hash, signature []byte) (*SignedComponents, error) {
+	if len(signature) != crypto.SignatureLength {
+		return nil, errors.Errorf("invalid signature length %d", len(signature))
+	}
 	// Adjust V for eth_sign compatibility (27 or 28)
 	// https://github.com/ethereum/go-ethereum/blob/master/crypto/signature_nocgo.go#L92-L93 Ecrecover uses `v-27`
 	// so the V value in the signature byte array is 0 or 1.
 	// To match common signature representations (like in ethers.js, web3.js, or what many wallets expect for eth_sign),
 	// V is typically 27 or 28.
 	// So, if v_raw is 0, V becomes 27. If v_raw is 1, V becomes 28.
-	adjustedV := v + 27
-
+	// Remote signers may already return 27 or 28.
+	v := signature[64]
+	if v < 27 {
+		v += 27
+	}
+	// Make sure the signer signed what we expect, a remote signer may hash the data differently
+	normalizedSignature := append(append([]byte{}, signature[:64]...), v-27)
+	publicKey, err := crypto.SigToPub(hash.Bytes(), normalizedSignature)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if crypto.PubkeyToAddress(*publicKey) != signer.Address() {
+		return nil, errors.Errorf("signature does not recover to signer address %s", signer.Address().Hex())
+	}
 	return &SignedComponents{
-		Hash: messageHash.Hex(),
-		R:    hexutil.Encode(r),
-		S:    hexutil.Encode(s),
-		V:    adjustedV,
+		Hash: hash.Hex(),
+		R:    hexutil.Encode(signature[:32]),
+		S:    hexutil.Encode(signature[32:64]),
+		V:    v,
 	}, nil
 }
+
+// recoverHashSigner checks that signed is a signature of hash and returns the address that signed it
+func recoverHashSigner(hash common.Hash, signed SignedComponents) (common.Address, error) {
+	signedHash, err := hexutil.Decode(signed.Hash)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "invalid signature hash %s", signed.Hash)
+	}
+	if len(signedHash) != common.HashLength || common.BytesToHash(signedHash) != hash {
+		return common.Address{}, errors.Errorf("signature hash %s does not match the message hash %s", signed.Hash, hash.Hex())
+	}
+
+	r, err := hexutil.Decode(signed.R)
+	if err != nil || len(r) != 32 {
+		return common.Address{}, errors.Errorf("invalid signature r %s", signed.R)
+	}
+	s, err := hexutil.Decode(signed.S)
+	if err != nil || len(s) != 32 {
+		return common.Address{}, errors.Errorf("invalid signature s %s", signed.S)
+	}
+	// SignedComponents report V as 27 or 28, Ecrecover expects 0 or 1
+	if signed.V != 27 && signed.V != 28 {
+		return common.Address{}, errors.Errorf("invalid signature v %d", signed.V)
+	}
+	signature := append(append(r, s...), signed.V-27)
+
+	publicKey, err := crypto.SigToPub(hash.Bytes(), signature)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to recover signer")
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
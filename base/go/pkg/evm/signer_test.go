@@ -0,0 +1,191 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+)
+
+const testPrivateKeyHex = "a3a0fd7cf0ef00256689d7e77262ea5ebac5349f2c724b15489ea7725d440338"
+
+type testMessage struct {
+	From  string
+	Nonce string
+}
+
+// web3SignerStandIn serves `eth_sign` like web3signer does, for a single key
+type web3SignerStandIn struct {
+	signer *PrivateKeySigner
+}
+
+func (s *web3SignerStandIn) Sign(address common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	signature, err := s.signer.SignHash(context.Background(), EIP191Hash(data))
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+// SignTypedData_v4 serves `eth_signTypedData_v4`, hashing the typed data on the signer side
+func (s *web3SignerStandIn) SignTypedData_v4(address common.Address, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := s.signer.SignHash(context.Background(), common.BytesToHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+func TestSignersProduceTheSameSignature(t *testing.T) {
+	ctx := context.Background()
+	message := testMessage{From: "0x6992a624044AAE8efBBA3404C6e0897912f72Aed", Nonce: "7"}
+
+	privateKeySigner, err := NewPrivateKeySignerFromHex(testPrivateKeyHex)
+	if err != nil {
+		t.Fatalf("Failed to create private key signer: %v", err)
+	}
+	domain := EIP712Domain{Name: "Test", Version: "1", ChainId: big.NewInt(1)}
+	expected, err := SignMessage(testPrivateKeyHex, message)
+	if err != nil {
+		t.Fatalf("Failed to sign message: %v", err)
+	}
+
+	// Keystore signer
+	key := &keystore.Key{
+		Id:         uuid.New(),
+		Address:    privateKeySigner.Address(),
+		PrivateKey: privateKeySigner.privateKey,
+	}
+	keyJSON, err := keystore.EncryptKey(key, "passphrase", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("Failed to encrypt key: %v", err)
+	}
+	keystorePath := filepath.Join(t.TempDir(), "key.json")
+	err = os.WriteFile(keystorePath, keyJSON, 0600)
+	if err != nil {
+		t.Fatalf("Failed to write keystore: %v", err)
+	}
+	keystoreSigner, err := NewKeystoreSigner(keystorePath, "passphrase")
+	if err != nil {
+		t.Fatalf("Failed to create keystore signer: %v", err)
+	}
+	if _, err := NewKeystoreSigner(keystorePath, "wrong"); err == nil {
+		t.Fatalf("Expected wrong passphrase to fail")
+	}
+
+	// Remote signer
+	server := rpc.NewServer()
+	err = server.RegisterName("eth", &web3SignerStandIn{signer: privateKeySigner})
+	if err != nil {
+		t.Fatalf("Failed to register remote signer: %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	remoteSigner, err := NewRemoteSigner(ctx, httpServer.URL, privateKeySigner.Address())
+	if err != nil {
+		t.Fatalf("Failed to create remote signer: %v", err)
+	}
+
+	for name, signer := range map[string]Signer{
+		"private key": privateKeySigner,
+		"keystore":    keystoreSigner,
+		"remote":      remoteSigner,
+	} {
+		signed, err := SignMessageWithSigner(ctx, signer, message)
+		if err != nil {
+			t.Fatalf("%s: failed to sign message: %v", name, err)
+		}
+		if *signed != *expected {
+			t.Fatalf("%s: signature %+v does not match %+v", name, *signed, *expected)
+		}
+		signedTypedData, err := SignTypedData(ctx, signer, domain, message)
+		if err != nil {
+			t.Fatalf("%s: failed to sign typed data: %v", name, err)
+		}
+		err = VerifyTypedDataSignature(domain, message, *signedTypedData, privateKeySigner.Address())
+		if err != nil {
+			t.Fatalf("%s: invalid typed data signature: %v", name, err)
+		}
+	}
+}
+
+func TestSignMessageRejectsSignatureOfAnotherKey(t *testing.T) {
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	signer, err := NewPrivateKeySignerFromHex(testPrivateKeyHex)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	signer.privateKey = otherKey
+
+	_, err = SignMessageWithSigner(context.Background(), signer, testMessage{})
+	if err == nil {
+		t.Fatalf("Expected signature of another key to be rejected")
+	}
+}
+
+func TestRecoverSigner(t *testing.T) {
+	signer, err := NewPrivateKeySignerFromHex(testPrivateKeyHex)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	message := testMessage{From: signer.Address().Hex(), Nonce: "3"}
+	signed, err := SignMessage(testPrivateKeyHex, message)
+	if err != nil {
+		t.Fatalf("Failed to sign message: %v", err)
+	}
+
+	recovered, err := RecoverSigner(message, *signed)
+	if err != nil {
+		t.Fatalf("Failed to recover signer: %v", err)
+	}
+	if recovered != signer.Address() {
+		t.Fatalf("Recovered %s instead of %s", recovered.Hex(), signer.Address().Hex())
+	}
+	err = VerifySignedComponents(message, *signed, signer.Address())
+	if err != nil {
+		t.Fatalf("Failed to verify signature: %v", err)
+	}
+
+	// Signature of another message
+	err = VerifySignedComponents(testMessage{From: message.From, Nonce: "4"}, *signed, signer.Address())
+	if err == nil {
+		t.Fatalf("Expected the signature of another message to be rejected")
+	}
+	// Signature of another account
+	err = VerifySignedComponents(message, *signed, common.HexToAddress("0x220c902381bdc091cf13d5f8efd8432f264b8f9a"))
+	if err == nil {
+		t.Fatalf("Expected the signature of another account to be rejected")
+	}
+	// Tampered signature
+	tampered := *signed
+	tampered.V = 55 - tampered.V
+	recovered, err = RecoverSigner(message, tampered)
+	if err == nil && recovered == signer.Address() {
+		t.Fatalf("Expected a tampered signature not to recover to the signer")
+	}
+	tampered = *signed
+	tampered.V = 1
+	_, err = RecoverSigner(message, tampered)
+	if err == nil {
+		t.Fatalf("Expected an invalid v to be rejected")
+	}
+}
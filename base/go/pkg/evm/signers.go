@@ -0,0 +1,147 @@
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/pkg/errors"
+)
+
+// PrivateKeySigner signs with an in-memory private key
+type PrivateKeySigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+func NewPrivateKeySigner(privateKey *ecdsa.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}
+}
+
+// NewPrivateKeySignerFromHex creates a signer from a hex private key, with or without the 0x prefix
+func NewPrivateKeySignerFromHex(privateKeyHex string) (*PrivateKeySigner, error) {
+	privateKey, err := PrivateKeyFromHex(privateKeyHex)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return NewPrivateKeySigner(privateKey), nil
+}
+
+// NewKeystoreSigner decrypts a go-ethereum keystore JSON file and signs with the key it holds
+func NewKeystoreSigner(keystorePath string, passphrase string) (*PrivateKeySigner, error) {
+	keyJSON, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt keystore %s", keystorePath)
+	}
+	return NewPrivateKeySigner(key.PrivateKey), nil
+}
+
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *PrivateKeySigner) SignHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	signature, err := crypto.Sign(hash.Bytes(), s.privateKey)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return signature, nil
+}
+
+// RemoteSigner signs through a remote signer speaking the web3signer JSON-RPC API (`eth_sign`),
+// so the private key never leaves the signer
+type RemoteSigner struct {
+	address common.Address
+	client  *rpc.Client
+}
+
+func NewRemoteSigner(ctx context.Context, url string, address common.Address) (*RemoteSigner, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &RemoteSigner{
+		address: address,
+		client:  client,
+	}, nil
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignHash is not supported: `eth_sign` always applies the EIP-191 prefix on the signer side
+func (s *RemoteSigner) SignHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	return nil, errors.New("remote signer cannot sign raw hashes")
+}
+
+func (s *RemoteSigner) SignText(ctx context.Context, data []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	err := s.client.CallContext(ctx, &signature, "eth_sign", s.address, hexutil.Bytes(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "remote signer failed to sign")
+	}
+	return signature, nil
+}
+
+// SignTypedData signs typed data with `eth_signTypedData_v4`, which hashes it on the signer side
+func (s *RemoteSigner) SignTypedData(ctx context.Context, typedData *apitypes.TypedData) ([]byte, error) {
+	var signature hexutil.Bytes
+	err := s.client.CallContext(ctx, &signature, "eth_signTypedData_v4", s.address, typedData)
+	if err != nil {
+		return nil, errors.Wrap(err, "remote signer failed to sign typed data")
+	}
+	return signature, nil
+}
+
+// SignerConfig selects how a Signer is created, the first configured source wins:
+// a raw private key, an encrypted keystore file, then a remote signer
+type SignerConfig struct {
+	PrivateKey       string
+	KeystorePath     string
+	KeystorePassword string
+	RemoteSignerURL  string
+	Address          string
+}
+
+// SignerConfigFromEnv reads a signer config from the environment variables
+// <prefix>_PRIVATE_KEY, <prefix>_KEYSTORE, <prefix>_KEYSTORE_PASSWORD, <prefix>_REMOTE_SIGNER_URL and <prefix>_ADDRESS
+func SignerConfigFromEnv(prefix string) SignerConfig {
+	return SignerConfig{
+		PrivateKey:       os.Getenv(prefix + "_PRIVATE_KEY"),
+		KeystorePath:     os.Getenv(prefix + "_KEYSTORE"),
+		KeystorePassword: os.Getenv(prefix + "_KEYSTORE_PASSWORD"),
+		RemoteSignerURL:  os.Getenv(prefix + "_REMOTE_SIGNER_URL"),
+		Address:          os.Getenv(prefix + "_ADDRESS"),
+	}
+}
+
+// NewSigner creates the signer described by the config
+func NewSigner(ctx context.Context, config SignerConfig) (Signer, error) {
+	switch {
+	case config.PrivateKey != "":
+		return NewPrivateKeySignerFromHex(config.PrivateKey)
+	case config.KeystorePath != "":
+		return NewKeystoreSigner(config.KeystorePath, config.KeystorePassword)
+	case config.RemoteSignerURL != "":
+		if !common.IsHexAddress(config.Address) {
+			return nil, errors.New("remote signer requires the account address")
+		}
+		return NewRemoteSigner(ctx, config.RemoteSignerURL, common.HexToAddress(config.Address))
+	default:
+		return nil, errors.New("no private key, keystore or remote signer configured")
+	}
+}
@@ -117,29 +117,69 @@ func (t *EVMTransaction) ToMessage() (*core.Message, error) {
 	}
 }
 
-// TransactionReceipt is the model for the RPC response of eth_getTransactionReceipt, currently only used on the test files
+// EVMTransactionReceipt is the model for the RPC response of eth_getTransactionReceipt and
+// eth_getBlockReceipts, which converts losslessly to a go-ethereum receipt
 type EVMTransactionReceipt struct {
-	Hash                 string          `json:"hash"`
-	Nonce                string          `json:"nonce"`
-	BlockHash            string          `json:"blockHash"`
-	BlockNumber          string          `json:"blockNumber"`
-	TransactionIndex     string          `json:"transactionIndex"`
-	From                 string          `json:"from"`
-	To                   string          `json:"to"`
-	Value                string          `json:"value"`
-	GasPrice             string          `json:"gasPrice"`
-	GasUsed              string          `json:"gasUsed"`
-	MaxFeePerGas         string          `json:"maxFeePerGas"`
-	MaxPriorityFeePerGas string          `json:"maxPriorityFeePerGas"`
-	Input                string          `json:"input"`
-	R                    string          `json:"r"`
-	S                    string          `json:"s"`
-	V                    string          `json:"v"`
-	YParity              string          `json:"yParity"`
-	ChainId              string          `json:"chainId"`
-	AccessList           []EVMAccessList `json:"accessList"`
-	Type                 string          `json:"type"`
-	ContractAddress      string          `json:"contractAddress"`
+	Type hexutil.Uint64 `json:"type"`
+	// Root is the post-state root of pre-Byzantium receipts, which have no Status
+	Root              hexutil.Bytes   `json:"root,omitempty"`
+	Status            *hexutil.Uint64 `json:"status,omitempty"`
+	CumulativeGasUsed hexutil.Uint64  `json:"cumulativeGasUsed"`
+	LogsBloom         types.Bloom     `json:"logsBloom"`
+	Logs              []*types.Log    `json:"logs"`
+
+	TransactionHash   common.Hash     `json:"transactionHash"`
+	TransactionIndex  hexutil.Uint    `json:"transactionIndex"`
+	BlockHash         common.Hash     `json:"blockHash"`
+	BlockNumber       *hexutil.Big    `json:"blockNumber"`
+	From              common.Address  `json:"from"`
+	To                *common.Address `json:"to"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	// BlobGasUsed and BlobGasPrice are only set for blob transactions
+	BlobGasUsed  *hexutil.Uint64 `json:"blobGasUsed,omitempty"`
+	BlobGasPrice *hexutil.Big    `json:"blobGasPrice,omitempty"`
+}
+
+// ToGethReceipt converts the receipt to a go-ethereum receipt, which has every field but From and To
+func (r *EVMTransactionReceipt) ToGethReceipt() *types.Receipt {
+	receipt := &types.Receipt{
+		Type:              uint8(r.Type),
+		PostState:         r.Root,
+		CumulativeGasUsed: uint64(r.CumulativeGasUsed),
+		Bloom:             r.LogsBloom,
+		Logs:              r.Logs,
+		TxHash:            r.TransactionHash,
+		GasUsed:           uint64(r.GasUsed),
+		EffectiveGasPrice: (*big.Int)(r.EffectiveGasPrice),
+		BlobGasPrice:      (*big.Int)(r.BlobGasPrice),
+		BlockHash:         r.BlockHash,
+		BlockNumber:       (*big.Int)(r.BlockNumber),
+		TransactionIndex:  uint(r.TransactionIndex),
+	}
+	if r.Logs == nil {
+		receipt.Logs = []*types.Log{}
+	}
+	if r.Status != nil {
+		receipt.Status = uint64(*r.Status)
+	}
+	if r.ContractAddress != nil {
+		receipt.ContractAddress = *r.ContractAddress
+	}
+	if r.BlobGasUsed != nil {
+		receipt.BlobGasUsed = uint64(*r.BlobGasUsed)
+	}
+	return receipt
+}
+
+// ToGethReceipts converts receipts to go-ethereum receipts
+func ToGethReceipts(receipts []*EVMTransactionReceipt) types.Receipts {
+	gethReceipts := make(types.Receipts, len(receipts))
+	for i, receipt := range receipts {
+		gethReceipts[i] = receipt.ToGethReceipt()
+	}
+	return gethReceipts
 }
 
 // EVMBlock is the model for the RPC response of eth_getBlockByHash and eth_getBlockByNumber
@@ -0,0 +1,44 @@
+package vsl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// RPCError is the JSON-RPC error object returned by the VSL node.
+//
+// Errors returned by VSLRPCClient wrap an *RPCError whenever the node answered the
+// request, use errors.As to tell those apart from transport failures.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	if len(e.Data) > 0 && string(e.Data) != "null" {
+		return fmt.Sprintf("vsl rpc error %d: %s: %s", e.Code, e.Message, string(e.Data))
+	}
+	return fmt.Sprintf("vsl rpc error %d: %s", e.Code, e.Message)
+}
+
+// AsRPCError returns the *RPCError wrapped by err, if any
+func AsRPCError(err error) (*RPCError, bool) {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr, true
+	}
+	return nil, false
+}
+
+// ErrorCodeInvalidNonce is the JSON-RPC error code of a message rejected because its nonce is
+// not the next nonce of its account. The message was not applied.
+const ErrorCodeInvalidNonce = -32001
+
+// IsNonceError reports whether the node rejected a message because of its nonce
+func IsNonceError(err error) bool {
+	rpcErr, ok := AsRPCError(err)
+	return ok && rpcErr.Code == ErrorCodeInvalidNonce
+}
@@ -0,0 +1,348 @@
+package vsl
+
+import (
+	"base/pkg/evm"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultFundingInterval is the default interval between two balance checks of a Funder
+	DefaultFundingInterval = time.Minute
+
+	fundingCapWindow = 24 * time.Hour
+)
+
+// FundedAccount is an account watched by a Funder. Amounts are decimal or 0x-prefixed hex strings.
+type FundedAccount struct {
+	Address string `json:"address"`
+	// Threshold is the balance under which the account is low on funds
+	Threshold string `json:"threshold"`
+	// Target is the balance the account is topped up to, defaults to twice the threshold
+	Target string `json:"target,omitempty"`
+}
+
+// FundingConfig configures a Funder, for example:
+//
+//	{
+//	  "accounts": [{"address": "0x...", "threshold": "100", "target": "1000"}],
+//	  "max_daily_top_up": "10000",
+//	  "treasury_threshold": "100000",
+//	  "interval_seconds": 60
+//	}
+type FundingConfig struct {
+	Accounts []FundedAccount `json:"accounts"`
+	// MaxDailyTopUp caps the amount paid by the treasury over the last 24 hours, empty means no cap
+	MaxDailyTopUp string `json:"max_daily_top_up,omitempty"`
+	// TreasuryThreshold is the treasury balance under which the treasury is reported low on funds
+	TreasuryThreshold string `json:"treasury_threshold,omitempty"`
+	// IntervalSeconds is the interval between two balance checks, defaults to DefaultFundingInterval
+	IntervalSeconds uint64 `json:"interval_seconds,omitempty"`
+}
+
+// LoadFundingConfig reads a funding config JSON file
+func LoadFundingConfig(path string) (*FundingConfig, error) {
+	configJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var config FundingConfig
+	err = json.Unmarshal(configJSON, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode funding config %s", path)
+	}
+	return &config, nil
+}
+
+type FundingReportType string
+
+const (
+	// FundingLow reports an account under its threshold
+	FundingLow FundingReportType = "LowFunds"
+	// FundingToppedUp reports a payment from the treasury to an account
+	FundingToppedUp FundingReportType = "ToppedUp"
+	// FundingCapReached reports a top-up skipped or reduced because of the daily cap
+	FundingCapReached FundingReportType = "DailyCapReached"
+	// FundingTreasuryLow reports a treasury under its threshold, or unable to pay a top-up
+	FundingTreasuryLow FundingReportType = "TreasuryLow"
+	// FundingFailed reports a top-up payment that failed
+	FundingFailed FundingReportType = "TopUpFailed"
+	// FundingUnconfirmed reports a top-up payment whose response was lost after the node consumed its
+	// nonce, so it was probably applied and counts against the daily cap
+	FundingUnconfirmed FundingReportType = "TopUpUnconfirmed"
+	// FundingCheckFailed reports a balance that could not be read
+	FundingCheckFailed FundingReportType = "CheckFailed"
+)
+
+// FundingReport is a condition found by a balance check
+type FundingReport struct {
+	Type    FundingReportType
+	Account string
+	Balance *big.Int
+	// Amount is the amount paid or missing, if any
+	Amount *big.Int
+	Err    error
+}
+
+type fundedAccount struct {
+	address   string
+	threshold *big.Int
+	target    *big.Int
+}
+
+type topUp struct {
+	time   time.Time
+	amount *big.Int
+}
+
+// Funder watches the balances of accounts, and tops them up from a treasury account when they
+// drop under their threshold. Without a treasury, it only reports the accounts low on funds.
+type Funder struct {
+	client            *VSLRPCClient
+	nonceManager      *NonceManager
+	accounts          []fundedAccount
+	maxDailyTopUp     *big.Int
+	treasuryThreshold *big.Int
+	interval          time.Duration
+
+	mu     sync.Mutex
+	topUps []topUp
+	now    func() time.Time
+}
+
+// NewFunder creates a funder paying from the account of the client signer, or reporting only if
+// the client has no signer
+func NewFunder(client *VSLRPCClient, config FundingConfig) (*Funder, error) {
+	funder := &Funder{
+		client:       client,
+		nonceManager: NewNonceManager(client),
+		interval:     DefaultFundingInterval,
+	}
+	if config.IntervalSeconds > 0 {
+		funder.interval = time.Duration(config.IntervalSeconds) * time.Second
+	}
+	var err error
+	if config.MaxDailyTopUp != "" {
+		funder.maxDailyTopUp, err = ParseAmount(config.MaxDailyTopUp)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid max daily top up")
+		}
+	}
+	if config.TreasuryThreshold != "" {
+		funder.treasuryThreshold, err = ParseAmount(config.TreasuryThreshold)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid treasury threshold")
+		}
+	}
+	for _, account := range config.Accounts {
+		threshold, err := ParseAmount(account.Threshold)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid threshold of %s", account.Address)
+		}
+		target := new(big.Int).Mul(threshold, big.NewInt(2))
+		if account.Target != "" {
+			target, err = ParseAmount(account.Target)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid target of %s", account.Address)
+			}
+		}
+		if target.Cmp(threshold) < 0 {
+			return nil, errors.Errorf("target %s of %s is under its threshold %s", target, account.Address, threshold)
+		}
+		funder.accounts = append(funder.accounts, fundedAccount{
+			address:   account.Address,
+			threshold: threshold,
+			target:    target,
+		})
+	}
+	return funder, nil
+}
+
+// FunderFromEnv creates the funder configured by the VSL_FUNDING_CONFIG file, paying from the
+// treasury signer configured by the VSL_TREASURY_* environment variables, if any.
+// It returns nil when VSL_FUNDING_CONFIG is not set.
+func FunderFromEnv(ctx context.Context, rpc string) (*Funder, error) {
+	path := os.Getenv("VSL_FUNDING_CONFIG")
+	if path == "" {
+		return nil, nil
+	}
+	config, err := LoadFundingConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var treasury evm.Signer
+	signerConfig := evm.SignerConfigFromEnv("VSL_TREASURY")
+	if signerConfig != (evm.SignerConfig{}) {
+		treasury, err = evm.NewSigner(ctx, signerConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create treasury signer")
+		}
+	}
+	return NewFunder(NewVSLRPCClient(rpc, treasury), *config)
+}
+
+// Run checks the balances until the context is done, logging the reports
+func (f *Funder) Run(ctx context.Context) error {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		reports, err := f.Check(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to check VSL balances: %+v", err)
+		}
+		for _, report := range reports {
+			log.Printf("VSL funding: %s", report)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check checks the balances once, tops up the accounts low on funds, and returns what it found.
+// A balance that can't be read is reported on its account and the other accounts are still
+// checked; the returned error then joins every such failure. Without the treasury balance,
+// nothing is topped up.
+func (f *Funder) Check(ctx context.Context) ([]FundingReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reports := []FundingReport{}
+	failures := []error{}
+	var treasuryBalance *big.Int
+	treasury := f.client.Signer()
+	if treasury != nil {
+		var err error
+		treasuryBalance, err = f.client.GetBalance(ctx, GetBalanceParams{AccountId: treasury.Address().Hex()})
+		if err != nil {
+			err = errors.Wrapf(err, "failed to get the balance of the treasury %s", treasury.Address().Hex())
+			reports = append(reports, FundingReport{Type: FundingCheckFailed, Account: treasury.Address().Hex(), Err: err})
+			failures = append(failures, err)
+			treasury = nil
+		} else if f.treasuryThreshold != nil && treasuryBalance.Cmp(f.treasuryThreshold) < 0 {
+			reports = append(reports, FundingReport{Type: FundingTreasuryLow, Account: treasury.Address().Hex(), Balance: new(big.Int).Set(treasuryBalance)})
+		}
+	}
+
+	for _, account := range f.accounts {
+		balance, err := f.client.GetBalance(ctx, GetBalanceParams{AccountId: account.address})
+		if err != nil {
+			err = errors.Wrapf(err, "failed to get the balance of %s", account.address)
+			reports = append(reports, FundingReport{Type: FundingCheckFailed, Account: account.address, Err: err})
+			failures = append(failures, err)
+			continue
+		}
+		if balance.Cmp(account.threshold) >= 0 {
+			continue
+		}
+		reports = append(reports, FundingReport{Type: FundingLow, Account: account.address, Balance: balance})
+		if treasury == nil {
+			continue
+		}
+
+		amount := new(big.Int).Sub(account.target, balance)
+		if remaining := f.remainingDailyTopUp(); remaining != nil && amount.Cmp(remaining) > 0 {
+			reports = append(reports, FundingReport{Type: FundingCapReached, Account: account.address, Balance: balance, Amount: new(big.Int).Sub(amount, remaining)})
+			amount = remaining
+		}
+		if amount.Sign() == 0 {
+			continue
+		}
+		if treasuryBalance.Cmp(amount) < 0 {
+			reports = append(reports, FundingReport{Type: FundingTreasuryLow, Account: treasury.Address().Hex(), Balance: new(big.Int).Set(treasuryBalance), Amount: amount})
+			continue
+		}
+
+		err = f.pay(ctx, account.address, amount)
+		if err != nil && !errors.Is(err, ErrNonceConsumed) {
+			reports = append(reports, FundingReport{Type: FundingFailed, Account: account.address, Balance: balance, Amount: amount, Err: err})
+			continue
+		}
+		treasuryBalance.Sub(treasuryBalance, amount)
+		f.topUps = append(f.topUps, topUp{time: f.clock(), amount: amount})
+		if err != nil {
+			reports = append(reports, FundingReport{Type: FundingUnconfirmed, Account: account.address, Balance: balance, Amount: amount, Err: err})
+			continue
+		}
+		reports = append(reports, FundingReport{Type: FundingToppedUp, Account: account.address, Balance: balance, Amount: amount})
+	}
+	if len(failures) > 0 {
+		return reports, stderrors.Join(failures...)
+	}
+	return reports, nil
+}
+
+func (f *Funder) pay(ctx context.Context, to string, amount *big.Int) error {
+	from := f.client.Signer().Address().Hex()
+	return f.nonceManager.Do(ctx, from, func(nonce uint64) error {
+		_, err := f.client.Pay(ctx, PayParams{
+			From:   from,
+			To:     to,
+			Amount: amount.String(),
+			Nonce:  fmt.Sprintf("%d", nonce),
+		})
+		return err
+	})
+}
+
+// remainingDailyTopUp returns what can still be paid under the daily cap, or nil without a cap
+func (f *Funder) remainingDailyTopUp() *big.Int {
+	if f.maxDailyTopUp == nil {
+		return nil
+	}
+	since := f.clock().Add(-fundingCapWindow)
+	kept := f.topUps[:0]
+	remaining := new(big.Int).Set(f.maxDailyTopUp)
+	for _, topUp := range f.topUps {
+		if topUp.time.After(since) {
+			kept = append(kept, topUp)
+			remaining.Sub(remaining, topUp.amount)
+		}
+	}
+	f.topUps = kept
+	if remaining.Sign() < 0 {
+		remaining.SetInt64(0)
+	}
+	return remaining
+}
+
+func (f *Funder) clock() time.Time {
+	if f.now != nil {
+		return f.now()
+	}
+	return time.Now()
+}
+
+func (r FundingReport) String() string {
+	switch r.Type {
+	case FundingToppedUp:
+		return fmt.Sprintf("topped up %s with %s from a balance of %s", r.Account, r.Amount, r.Balance)
+	case FundingCapReached:
+		return fmt.Sprintf("daily top up cap reached, %s is missing %s", r.Account, r.Amount)
+	case FundingTreasuryLow:
+		if r.Amount != nil {
+			return fmt.Sprintf("treasury %s balance %s cannot pay a top up of %s", r.Account, r.Balance, r.Amount)
+		}
+		return fmt.Sprintf("treasury %s is low on funds with a balance of %s", r.Account, r.Balance)
+	case FundingFailed:
+		return fmt.Sprintf("failed to top up %s with %s: %v", r.Account, r.Amount, r.Err)
+	case FundingUnconfirmed:
+		return fmt.Sprintf("top up of %s with %s is unconfirmed, counted against the daily cap: %v", r.Account, r.Amount, r.Err)
+	case FundingCheckFailed:
+		return fmt.Sprintf("failed to check %s: %v", r.Account, r.Err)
+	default:
+		return fmt.Sprintf("%s is low on funds with a balance of %s", r.Account, r.Balance)
+	}
+}
@@ -0,0 +1,207 @@
+package vsl_test
+
+import (
+	"base/pkg/evm"
+	"base/pkg/vsl"
+	"base/pkg/vsl/vsltest"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func reportTypes(reports []vsl.FundingReport) []vsl.FundingReportType {
+	types := []vsl.FundingReportType{}
+	for _, report := range reports {
+		types = append(types, report.Type)
+	}
+	return types
+}
+
+func TestFunder(t *testing.T) {
+	ctx := context.Background()
+	node := vsltest.NewNode(t, nil)
+	treasuryKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	treasury := evm.NewPrivateKeySigner(treasuryKey)
+	node.Fund(treasury.Address().Hex(), big.NewInt(1000))
+	submitter := "0x220c902381bdc091cf13d5f8efd8432f264b8f9a"
+	verifier := "0x0C5d19cb92ad3b75b74c2301947018Dfb913064a"
+	node.Fund(verifier, big.NewInt(50))
+
+	config := vsl.FundingConfig{
+		Accounts: []vsl.FundedAccount{
+			{Address: submitter, Threshold: "100", Target: "300"},
+			{Address: verifier, Threshold: "100", Target: "0x226"}, // 550
+		},
+		MaxDailyTopUp:     "600",
+		TreasuryThreshold: "500",
+	}
+
+	// Without a treasury, the accounts low on funds are only reported
+	monitor, err := vsl.NewFunder(node.Client(nil), config)
+	if err != nil {
+		t.Fatalf("Failed to create funder: %v", err)
+	}
+	reports, err := monitor.Check(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check balances: %v", err)
+	}
+	if len(reports) != 2 || reports[0].Type != vsl.FundingLow || reports[1].Type != vsl.FundingLow {
+		t.Fatalf("Unexpected reports %v", reportTypes(reports))
+	}
+
+	funder, err := vsl.NewFunder(node.Client(treasury), config)
+	if err != nil {
+		t.Fatalf("Failed to create funder: %v", err)
+	}
+	reports, err = funder.Check(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check balances: %v", err)
+	}
+	// The submitter is topped up to its target, the verifier only up to the daily cap
+	if node.Balance(submitter).Int64() != 300 || node.Balance(verifier).Int64() != 350 {
+		t.Fatalf("Unexpected balances %s and %s", node.Balance(submitter), node.Balance(verifier))
+	}
+	expected := []vsl.FundingReportType{vsl.FundingLow, vsl.FundingToppedUp, vsl.FundingLow, vsl.FundingCapReached, vsl.FundingToppedUp}
+	if len(reports) != len(expected) {
+		t.Fatalf("Unexpected reports %v", reportTypes(reports))
+	}
+	for i := range expected {
+		if reports[i].Type != expected[i] {
+			t.Fatalf("Unexpected reports %v", reportTypes(reports))
+		}
+	}
+	if reports[3].Amount.Int64() != 200 {
+		t.Fatalf("Expected 200 to be missing because of the cap, got %s", reports[3].Amount)
+	}
+
+	// The treasury is now under its threshold and the cap is spent
+	node.Fund(submitter, big.NewInt(-250))
+	reports, err = funder.Check(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check balances: %v", err)
+	}
+	types := reportTypes(reports)
+	if len(types) != 3 || types[0] != vsl.FundingTreasuryLow || types[1] != vsl.FundingLow || types[2] != vsl.FundingCapReached {
+		t.Fatalf("Unexpected reports %v", types)
+	}
+	if node.Balance(treasury.Address().Hex()).Int64() != 400 {
+		t.Fatalf("Unexpected treasury balance %s", node.Balance(treasury.Address().Hex()))
+	}
+}
+
+func TestFunderCheckFailed(t *testing.T) {
+	ctx := context.Background()
+	node := vsltest.NewNode(t, nil)
+	treasuryKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	treasury := evm.NewPrivateKeySigner(treasuryKey)
+	node.Fund(treasury.Address().Hex(), big.NewInt(1000))
+	verifier := "0x0C5d19cb92ad3b75b74c2301947018Dfb913064a"
+
+	funder, err := vsl.NewFunder(node.Client(treasury), vsl.FundingConfig{
+		Accounts: []vsl.FundedAccount{
+			{Address: "unknown", Threshold: "100"},
+			{Address: verifier, Threshold: "100"},
+			{Address: "invalid", Threshold: "100"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create funder: %v", err)
+	}
+
+	// The accounts after a failed balance are still checked and topped up, every failure is returned
+	reports, err := funder.Check(ctx)
+	if err == nil || !strings.Contains(err.Error(), "unknown") || !strings.Contains(err.Error(), "invalid") {
+		t.Fatalf("Expected both failed balances in the error, got %v", err)
+	}
+	types := reportTypes(reports)
+	expected := []vsl.FundingReportType{vsl.FundingCheckFailed, vsl.FundingLow, vsl.FundingToppedUp, vsl.FundingCheckFailed}
+	if len(types) != len(expected) {
+		t.Fatalf("Unexpected reports %v", types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Fatalf("Unexpected reports %v", types)
+		}
+	}
+	if reports[0].Account != "unknown" || reports[0].Err == nil {
+		t.Fatalf("Expected the failure on the account report, got %+v", reports[0])
+	}
+	if node.Balance(verifier).Int64() != 200 {
+		t.Fatalf("Unexpected balance %s", node.Balance(verifier))
+	}
+}
+
+func TestFunderUnconfirmed(t *testing.T) {
+	ctx := context.Background()
+	node := vsltest.NewNode(t, nil)
+	treasuryKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	treasury := evm.NewPrivateKeySigner(treasuryKey)
+	node.Fund(treasury.Address().Hex(), big.NewInt(1000))
+	verifier := "0x0C5d19cb92ad3b75b74c2301947018Dfb913064a"
+
+	// The payments reach the node but their responses are lost
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		resp, err := http.Post(node.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Errorf("Failed to forward request: %v", err)
+			return
+		}
+		defer resp.Body.Close()
+		if bytes.Contains(body, []byte("vsl_pay")) {
+			http.Error(w, "response lost", http.StatusBadGateway)
+			return
+		}
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(proxy.Close)
+	client := vsl.NewVSLRPCClientWithConfig(proxy.URL, treasury, vsl.VSLRPCClientConfig{Timeout: 10 * time.Second})
+
+	funder, err := vsl.NewFunder(client, vsl.FundingConfig{
+		Accounts:      []vsl.FundedAccount{{Address: verifier, Threshold: "100", Target: "300"}},
+		MaxDailyTopUp: "400",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create funder: %v", err)
+	}
+
+	// The applied payment is reported unconfirmed and counted against the daily cap
+	reports, err := funder.Check(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check balances: %v", err)
+	}
+	types := reportTypes(reports)
+	if len(types) != 2 || types[1] != vsl.FundingUnconfirmed || !errors.Is(reports[1].Err, vsl.ErrNonceConsumed) {
+		t.Fatalf("Expected the top up to be unconfirmed, got %v", types)
+	}
+	node.Fund(verifier, big.NewInt(-300))
+	reports, err = funder.Check(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check balances: %v", err)
+	}
+	types = reportTypes(reports)
+	if len(types) != 3 || types[1] != vsl.FundingCapReached || reports[1].Amount.Int64() != 200 || types[2] != vsl.FundingUnconfirmed {
+		t.Fatalf("Expected the unconfirmed top up to count against the cap, got %v", types)
+	}
+	if node.Balance(verifier).Int64() != 100 {
+		t.Fatalf("Unexpected balance %s", node.Balance(verifier))
+	}
+}
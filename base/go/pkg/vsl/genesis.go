@@ -0,0 +1,33 @@
+package vsl
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Genesis is the initial state of a VSL node, as in the genesis.json file of the repository
+type Genesis struct {
+	Accounts []GenesisAccount `json:"accounts"`
+}
+
+type GenesisAccount struct {
+	Id string `json:"id"`
+	// Balance is a decimal amount
+	Balance string `json:"balance"`
+}
+
+// LoadGenesis reads a genesis.json file
+func LoadGenesis(path string) (*Genesis, error) {
+	genesisJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var genesis Genesis
+	err = json.Unmarshal(genesisJSON, &genesis)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode genesis %s", path)
+	}
+	return &genesis, nil
+}
@@ -0,0 +1,142 @@
+package vsl
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNonceConsumed is returned by NonceManager.Do when a message failed without an answer from the
+// node but the node consumed its nonce, so the message was most likely applied and is not resent
+var ErrNonceConsumed = errors.New("vsl nonce consumed by a message whose response was lost")
+
+// NonceManager hands out sequential nonces for VSL accounts, so that concurrent submissions
+// from the same account don't race on vsl_getAccountNonce. The nonce of an account is fetched
+// from the node once and then incremented locally.
+type NonceManager struct {
+	client   *VSLRPCClient
+	mu       sync.Mutex
+	accounts map[string]*accountNonce
+}
+
+type accountNonce struct {
+	mu     sync.Mutex
+	next   uint64
+	synced bool
+}
+
+func NewNonceManager(client *VSLRPCClient) *NonceManager {
+	return &NonceManager{
+		client:   client,
+		accounts: map[string]*accountNonce{},
+	}
+}
+
+func (m *NonceManager) account(address string) *accountNonce {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := strings.ToLower(address)
+	account, ok := m.accounts[key]
+	if !ok {
+		account = &accountNonce{}
+		m.accounts[key] = account
+	}
+	return account
+}
+
+// Next reserves the next nonce of the account
+func (m *NonceManager) Next(ctx context.Context, address string) (uint64, error) {
+	account := m.account(address)
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	return m.next(ctx, address, account)
+}
+
+// next reserves the next nonce of an account locked by the caller
+func (m *NonceManager) next(ctx context.Context, address string, account *accountNonce) (uint64, error) {
+	if !account.synced {
+		nonce, err := m.client.GetAccountNonce(ctx, GetAccountNonceParams{
+			AccountId: address,
+		})
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		account.next = *nonce
+		account.synced = true
+	}
+
+	nonce := account.next
+	account.next++
+	return nonce, nil
+}
+
+// Resync drops the local nonce of the account, the next reservation fetches it from the node again
+func (m *NonceManager) Resync(address string) {
+	account := m.account(address)
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	account.synced = false
+}
+
+// Do calls send with the next nonce of the account. The messages of an account sent with Do are
+// sent one at a time, since the node only accepts the next nonce of an account.
+//
+// The message is resent at most once and only when the node did not apply it, so that it is never
+// applied twice:
+//   - a message rejected because of its nonce is resent with a freshly synced nonce
+//   - a message whose outcome is unknown after a transport failure is resent with the same nonce
+//     if the node did not consume it, otherwise ErrNonceConsumed is returned
+//
+// Any other error is returned as is. The local nonce is resynced after every failure.
+func (m *NonceManager) Do(ctx context.Context, address string, send func(nonce uint64) error) error {
+	account := m.account(address)
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	nonce, err := m.next(ctx, address, account)
+	if err != nil {
+		return err
+	}
+
+	err = send(nonce)
+	if err == nil {
+		return nil
+	}
+	account.synced = false
+	_, answered := AsRPCError(err)
+	switch {
+	case IsNonceError(err):
+		nonce, err = m.next(ctx, address, account)
+		if err != nil {
+			return err
+		}
+	case !answered:
+		// The node may have applied the message before the response was lost, check whether it
+		// consumed the nonce before sending the message again
+		nodeNonce, nonceErr := m.client.GetAccountNonce(ctx, GetAccountNonceParams{
+			AccountId: address,
+		})
+		if nonceErr != nil {
+			return err
+		}
+		if *nodeNonce > nonce {
+			return errors.Wrapf(ErrNonceConsumed, "nonce %d of %s: %v", nonce, address, err)
+		}
+		account.next = nonce + 1
+		account.synced = true
+	default:
+		return err
+	}
+
+	err = send(nonce)
+	if err != nil {
+		account.synced = false
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package vsl_test
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+	"base/pkg/vsl"
+	"base/pkg/vsl/vsltest"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type nonceTest struct {
+	client    *vsl.VSLRPCClient
+	manager   *vsl.NonceManager
+	address   string
+	verifiers []string
+}
+
+func newNonceTest(t *testing.T) *nonceTest {
+	node := vsltest.NewNode(t, nil)
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	client := node.Client(evm.NewPrivateKeySigner(key))
+	address := client.Signer().Address().Hex()
+	node.Fund(address, big.NewInt(1000))
+	return &nonceTest{
+		client:    client,
+		manager:   vsl.NewNonceManager(client),
+		address:   address,
+		verifiers: []string{address},
+	}
+}
+
+func (n *nonceTest) submit(ctx context.Context, claim string, nonce uint64) error {
+	_, err := n.client.SubmitClaim(ctx, vsl.SubmitClaimParams{
+		Claim:     claim,
+		ClaimType: "Test",
+		Nonce:     fmt.Sprintf("%d", nonce),
+		To:        n.verifiers,
+		Quorum:    1,
+		From:      n.address,
+		Expires:   abstract_types.Timestamp{Seconds: uint64(time.Now().Add(time.Minute).Unix())},
+		Fee:       "1",
+	})
+	return err
+}
+
+// submitted returns the number of claims applied by the node
+func (n *nonceTest) submitted(t *testing.T) int {
+	claims, err := n.client.ListSubmittedClaimsForSender(context.Background(), vsl.ListSubmittedClaimsForSenderParams{Address: n.address})
+	if err != nil {
+		t.Fatalf("Failed to list claims: %v", err)
+	}
+	return len(claims)
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	ctx := context.Background()
+	test := newNonceTest(t)
+
+	// Concurrent reservations get distinct nonces
+	nonces := make([]uint64, 20)
+	var wg sync.WaitGroup
+	for i := range nonces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := test.manager.Next(ctx, test.address)
+			if err != nil {
+				t.Errorf("Failed to reserve nonce: %v", err)
+			}
+			nonces[i] = nonce
+		}()
+	}
+	wg.Wait()
+	seen := map[uint64]bool{}
+	for _, nonce := range nonces {
+		if seen[nonce] || nonce >= uint64(len(nonces)) {
+			t.Fatalf("Expected the nonces 0 to %d once each, got %v", len(nonces)-1, nonces)
+		}
+		seen[nonce] = true
+	}
+
+	// Concurrent submissions are all applied once, the reserved nonces being resynced
+	test.manager.Resync(test.address)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := test.manager.Do(ctx, test.address, func(nonce uint64) error {
+				return test.submit(ctx, fmt.Sprintf("claim %d", i), nonce)
+			})
+			if err != nil {
+				t.Errorf("Failed to submit claim %d: %v", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if submitted := test.submitted(t); submitted != 20 {
+		t.Fatalf("Expected 20 claims, got %d", submitted)
+	}
+}
+
+func TestNonceManagerDo(t *testing.T) {
+	ctx := context.Background()
+	test := newNonceTest(t)
+	lost := errors.New("connection reset")
+
+	// A nonce used behind the manager's back is rejected, then resent with the synced nonce
+	_, err := test.manager.Next(ctx, test.address)
+	if err != nil {
+		t.Fatalf("Failed to reserve nonce: %v", err)
+	}
+	err = test.submit(ctx, "other", 0)
+	if err != nil {
+		t.Fatalf("Failed to submit claim: %v", err)
+	}
+	err = test.submit(ctx, "other", 1)
+	if err != nil {
+		t.Fatalf("Failed to submit claim: %v", err)
+	}
+	sent := []uint64{}
+	err = test.manager.Do(ctx, test.address, func(nonce uint64) error {
+		sent = append(sent, nonce)
+		return test.submit(ctx, "resent", nonce)
+	})
+	if err != nil || fmt.Sprint(sent) != "[1 2]" {
+		t.Fatalf("Expected the rejected claim to be resent with nonce 2, got %v, %v", sent, err)
+	}
+
+	// A message applied by the node whose response was lost is not resent
+	sent = sent[:0]
+	err = test.manager.Do(ctx, test.address, func(nonce uint64) error {
+		sent = append(sent, nonce)
+		err := test.submit(ctx, "lost", nonce)
+		if err != nil {
+			return err
+		}
+		return lost
+	})
+	if !errors.Is(err, vsl.ErrNonceConsumed) || len(sent) != 1 {
+		t.Fatalf("Expected the applied claim not to be resent, got %v, %v", sent, err)
+	}
+
+	// A message which did not reach the node is resent with the same nonce
+	sent = sent[:0]
+	err = test.manager.Do(ctx, test.address, func(nonce uint64) error {
+		sent = append(sent, nonce)
+		if len(sent) == 1 {
+			return lost
+		}
+		return test.submit(ctx, "retried", nonce)
+	})
+	if err != nil || fmt.Sprint(sent) != "[4 4]" {
+		t.Fatalf("Expected the claim to be resent with the same nonce, got %v, %v", sent, err)
+	}
+
+	// Rejected messages are not resent
+	sent = sent[:0]
+	test.verifiers = nil
+	err = test.manager.Do(ctx, test.address, func(nonce uint64) error {
+		sent = append(sent, nonce)
+		return test.submit(ctx, "invalid", nonce)
+	})
+	if err == nil || len(sent) != 1 {
+		t.Fatalf("Expected the rejected claim not to be resent, got %v, %v", sent, err)
+	}
+	if submitted := test.submitted(t); submitted != 5 {
+		t.Fatalf("Expected every claim to be applied once, got %d claims", submitted)
+	}
+}
@@ -0,0 +1,264 @@
+package vsl
+
+import (
+	"base/pkg/abstract_types"
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultClaimFee is the fee of a claim when the policy has no fee for its type
+	DefaultClaimFee = "1"
+	// DefaultClaimExpiry is how long a submitted claim waits for settlement by default
+	DefaultClaimExpiry = 10 * time.Minute
+
+	feeCapWindow = time.Hour
+)
+
+// ErrFeeCapExceeded is returned when submitting a claim would exceed the hourly fee cap of the policy
+var ErrFeeCapExceeded = errors.New("hourly fee cap exceeded")
+
+// FeeRule is the fee of a claim type: a fixed amount, plus an amount per started KiB of proof.
+// Amounts are decimal or 0x-prefixed hex strings.
+type FeeRule struct {
+	Fixed  string `json:"fixed,omitempty"`
+	PerKiB string `json:"per_kib,omitempty"`
+}
+
+// Fee returns the fee of a claim with a proof of proofSize bytes
+func (r FeeRule) Fee(proofSize int) (*big.Int, error) {
+	fee := new(big.Int)
+	if r.Fixed != "" {
+		fixed, err := ParseAmount(r.Fixed)
+		if err != nil {
+			return nil, err
+		}
+		fee.Add(fee, fixed)
+	}
+	if r.PerKiB != "" {
+		perKiB, err := ParseAmount(r.PerKiB)
+		if err != nil {
+			return nil, err
+		}
+		kib := big.NewInt(int64((proofSize + 1023) / 1024))
+		fee.Add(fee, kib.Mul(kib, perKiB))
+	}
+	return fee, nil
+}
+
+// SubmissionPolicy decides the fee, expiry and receivers of the claims a submitter sends to VSL
+//
+// The policy is loaded from a JSON file, for example:
+//
+//	{
+//	  "fees": {"EVMViewFn": {"fixed": "1", "per_kib": "1"}},
+//	  "default_fee": {"fixed": "1"},
+//	  "expiry_seconds": 600,
+//	  "verifiers": ["0x...", "0x..."],
+//	  "quorum": 2,
+//	  "max_fee_per_hour": "1000"
+//	}
+type SubmissionPolicy struct {
+	// Fees holds the fee rule of each claim type
+	Fees map[string]FeeRule `json:"fees,omitempty"`
+	// DefaultFee is the fee rule of the claim types missing from Fees
+	DefaultFee FeeRule `json:"default_fee"`
+	// ExpirySeconds is how long a submitted claim waits for settlement
+	ExpirySeconds uint64 `json:"expiry_seconds"`
+	// Verifiers are the addresses the claims are submitted to
+	Verifiers []string `json:"verifiers"`
+	// Quorum is the number of verifiers that must settle a claim
+	Quorum uint16 `json:"quorum"`
+	// MaxFeePerHour caps the fees spent over the last hour, empty means no cap
+	MaxFeePerHour string `json:"max_fee_per_hour,omitempty"`
+
+	mu          sync.Mutex
+	spent       []feeSpend
+	nextSpendId uint64
+	now         func() time.Time
+}
+
+// feeSpend is a fee reserved against the hourly fee cap, identified by a sequence id for releasing it
+type feeSpend struct {
+	id   uint64
+	time time.Time
+	fee  *big.Int
+}
+
+// ClaimTerms are the fee, expiry and receivers of a claim, as decided by a SubmissionPolicy
+type ClaimTerms struct {
+	To      []string
+	Quorum  uint16
+	Expires abstract_types.Timestamp
+	// Fee is the 0x-prefixed hex fee
+	Fee string
+
+	spent *feeSpend
+}
+
+// DefaultSubmissionPolicy returns the policy submitting claims to a single verifier, with a fee of
+// DefaultClaimFee and an expiry of DefaultClaimExpiry
+func DefaultSubmissionPolicy(verifier string) *SubmissionPolicy {
+	return &SubmissionPolicy{
+		DefaultFee:    FeeRule{Fixed: DefaultClaimFee},
+		ExpirySeconds: uint64(DefaultClaimExpiry.Seconds()),
+		Verifiers:     []string{verifier},
+		Quorum:        1,
+	}
+}
+
+// LoadSubmissionPolicy reads and validates a policy JSON file
+func LoadSubmissionPolicy(path string) (*SubmissionPolicy, error) {
+	policyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var policy SubmissionPolicy
+	err = json.Unmarshal(policyJSON, &policy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode submission policy %s", path)
+	}
+	err = policy.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid submission policy %s", path)
+	}
+	return &policy, nil
+}
+
+// SubmissionPolicyFromEnv loads the policy file at VSL_SUBMISSION_POLICY, or falls back to
+// DefaultSubmissionPolicy with the verifier at VSL_VERIFIER_ADDRESS
+func SubmissionPolicyFromEnv() (*SubmissionPolicy, error) {
+	path := os.Getenv("VSL_SUBMISSION_POLICY")
+	if path != "" {
+		return LoadSubmissionPolicy(path)
+	}
+	policy := DefaultSubmissionPolicy(os.Getenv("VSL_VERIFIER_ADDRESS"))
+	err := policy.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Validate checks that the policy can be used to submit claims
+func (p *SubmissionPolicy) Validate() error {
+	if len(p.Verifiers) == 0 {
+		return errors.New("no verifiers")
+	}
+	for _, verifier := range p.Verifiers {
+		if !common.IsHexAddress(verifier) {
+			return errors.Errorf("invalid verifier address %q", verifier)
+		}
+	}
+	if p.Quorum == 0 || int(p.Quorum) > len(p.Verifiers) {
+		return errors.Errorf("quorum %d must be between 1 and the %d verifiers", p.Quorum, len(p.Verifiers))
+	}
+	if p.ExpirySeconds == 0 {
+		return errors.New("expiry must be positive")
+	}
+	if _, err := p.DefaultFee.Fee(0); err != nil {
+		return errors.Wrap(err, "invalid default fee")
+	}
+	for claimType, rule := range p.Fees {
+		if _, err := rule.Fee(0); err != nil {
+			return errors.Wrapf(err, "invalid fee of %s", claimType)
+		}
+	}
+	if p.MaxFeePerHour != "" {
+		if _, err := ParseAmount(p.MaxFeePerHour); err != nil {
+			return errors.Wrap(err, "invalid max fee per hour")
+		}
+	}
+	return nil
+}
+
+// Terms returns the terms of a claim of claimType with a proof of proofSize bytes.
+//
+// The fee is reserved against the hourly fee cap, and ErrFeeCapExceeded is returned when the cap
+// would be exceeded. Release the terms if the claim is not submitted after all.
+func (p *SubmissionPolicy) Terms(claimType string, proofSize int) (*ClaimTerms, error) {
+	rule, ok := p.Fees[claimType]
+	if !ok {
+		rule = p.DefaultFee
+	}
+	fee, err := rule.Fee(proofSize)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock()
+	terms := &ClaimTerms{
+		To:     append([]string(nil), p.Verifiers...),
+		Quorum: p.Quorum,
+		Expires: abstract_types.Timestamp{
+			Seconds: uint64(now.Unix()) + p.ExpirySeconds,
+		},
+		Fee: hexutil.EncodeBig(fee),
+	}
+	if p.MaxFeePerHour == "" {
+		return terms, nil
+	}
+
+	maxFee, err := ParseAmount(p.MaxFeePerHour)
+	if err != nil {
+		return nil, err
+	}
+	spent := p.spentSince(now.Add(-feeCapWindow))
+	if new(big.Int).Add(spent, fee).Cmp(maxFee) > 0 {
+		return nil, errors.Wrapf(ErrFeeCapExceeded, "fee %s on top of %s spent in the last hour exceeds %s", fee, spent, maxFee)
+	}
+	p.nextSpendId++
+	terms.spent = &feeSpend{id: p.nextSpendId, time: now, fee: fee}
+	p.spent = append(p.spent, *terms.spent)
+	return terms, nil
+}
+
+// Release gives back the fee reserved by terms of a claim that was not submitted. A claim failing
+// with ErrNonceConsumed was probably applied and charged, its terms must not be released.
+func (p *SubmissionPolicy) Release(terms *ClaimTerms) {
+	if terms == nil || terms.spent == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, spend := range p.spent {
+		if spend.id == terms.spent.id {
+			p.spent = append(p.spent[:i], p.spent[i+1:]...)
+			break
+		}
+	}
+	terms.spent = nil
+}
+
+// spentSince drops the spends before since and returns the sum of the others
+func (p *SubmissionPolicy) spentSince(since time.Time) *big.Int {
+	kept := p.spent[:0]
+	total := new(big.Int)
+	for _, spend := range p.spent {
+		if spend.time.After(since) {
+			kept = append(kept, spend)
+			total.Add(total, spend.fee)
+		}
+	}
+	p.spent = kept
+	return total
+}
+
+func (p *SubmissionPolicy) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,87 @@
+package vsl
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const testPolicyJSON = `{
+  "fees": {"EVMViewFn": {"fixed": "10", "per_kib": "0x2"}},
+  "default_fee": {"fixed": "1"},
+  "expiry_seconds": 300,
+  "verifiers": ["0x220c902381bdc091cf13d5f8efd8432f264b8f9a", "0x0C5d19cb92ad3b75b74c2301947018Dfb913064a"],
+  "quorum": 2,
+  "max_fee_per_hour": "25"
+}`
+
+func TestSubmissionPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	err := os.WriteFile(path, []byte(testPolicyJSON), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+	policy, err := LoadSubmissionPolicy(path)
+	if err != nil {
+		t.Fatalf("Failed to load policy: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	policy.now = func() time.Time { return now }
+
+	// 10 + 2 per started KiB
+	terms, err := policy.Terms("EVMViewFn", 1025)
+	if err != nil {
+		t.Fatalf("Failed to get terms: %v", err)
+	}
+	if terms.Fee != "0xe" || terms.Quorum != 2 || len(terms.To) != 2 || terms.Expires.Seconds != 1_700_000_300 {
+		t.Fatalf("Unexpected terms %+v", terms)
+	}
+
+	// 14 + 1 + 10 = 25 is within the cap, another fee of 1 is not
+	_, err = policy.Terms("MirroringGeth", 1<<20)
+	if err != nil {
+		t.Fatalf("Failed to get terms: %v", err)
+	}
+	released, err := policy.Terms("EVMViewFn", 0)
+	if err != nil {
+		t.Fatalf("Failed to get terms: %v", err)
+	}
+	_, err = policy.Terms("MirroringGeth", 0)
+	if !errors.Is(err, ErrFeeCapExceeded) {
+		t.Fatalf("Expected the fee cap to be exceeded, got %v", err)
+	}
+
+	// Released fees and fees older than an hour don't count, a copy of the terms releasing the same fee
+	spent := *released.spent
+	spent.fee = new(big.Int).Set(spent.fee)
+	copied := *released
+	copied.spent = &spent
+	policy.Release(&copied)
+	_, err = policy.Terms("MirroringGeth", 0)
+	if err != nil {
+		t.Fatalf("Expected the released fee to be available: %v", err)
+	}
+	now = now.Add(time.Hour)
+	_, err = policy.Terms("EVMViewFn", 4096)
+	if err != nil {
+		t.Fatalf("Expected the hourly window to move on: %v", err)
+	}
+}
+
+func TestSubmissionPolicyValidate(t *testing.T) {
+	policy := DefaultSubmissionPolicy("0x220c902381bdc091cf13d5f8efd8432f264b8f9a")
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("Expected the default policy to be valid: %v", err)
+	}
+	policy.Quorum = 2
+	if err := policy.Validate(); err == nil {
+		t.Fatalf("Expected a quorum larger than the verifiers to be rejected")
+	}
+	if err := DefaultSubmissionPolicy("").Validate(); err == nil {
+		t.Fatalf("Expected a missing verifier to be rejected")
+	}
+}
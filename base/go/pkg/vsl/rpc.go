@@ -3,6 +3,13 @@ package vsl
 import (
 	"base/pkg/abstract_types"
 	"base/pkg/evm"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/gofiber/fiber"
 	"github.com/gofiber/fiber/v3/client"
@@ -10,40 +17,153 @@ import (
 	"github.com/tidwall/gjson"
 )
 
+const (
+	// DefaultTimeout is the default timeout of a single request attempt
+	DefaultTimeout = 30 * time.Second
+	// DefaultMaxRetries is the default number of retries of a read-only method after a transport failure
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the default wait before the first retry, doubled on every retry
+	DefaultRetryBackoff = 500 * time.Millisecond
+
+	maxRetryBackoff = 10 * time.Second
+)
+
+// VSLRPCClientConfig holds the transport settings of a VSLRPCClient
+type VSLRPCClientConfig struct {
+	// Timeout of a single request attempt, 0 means no timeout besides the context
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a read-only method after a transport failure
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled on every retry
+	RetryBackoff time.Duration
+}
+
+// DefaultVSLRPCClientConfig returns the config used by NewVSLRPCClient
+func DefaultVSLRPCClientConfig() VSLRPCClientConfig {
+	return VSLRPCClientConfig{
+		Timeout:      DefaultTimeout,
+		MaxRetries:   DefaultMaxRetries,
+		RetryBackoff: DefaultRetryBackoff,
+	}
+}
+
 type VSLRPCClient struct {
-	rpc        string
-	privateKey string
-	rpcClient  *client.Client
+	rpc       string
+	signer    evm.Signer
+	rpcClient *client.Client
+	config    VSLRPCClientConfig
+	requestId atomic.Uint64
 }
 
-func NewVSLRPCClient(rpc string, privateKey string) *VSLRPCClient {
+// NewVSLRPCClient creates a client which signs its messages with signer, which may be nil for read-only usage
+func NewVSLRPCClient(rpc string, signer evm.Signer) *VSLRPCClient {
+	return NewVSLRPCClientWithConfig(rpc, signer, DefaultVSLRPCClientConfig())
+}
+
+func NewVSLRPCClientWithConfig(rpc string, signer evm.Signer, config VSLRPCClientConfig) *VSLRPCClient {
 	return &VSLRPCClient{
-		rpc:        rpc,
-		privateKey: privateKey,
-		rpcClient:  client.New(),
+		rpc:       rpc,
+		signer:    signer,
+		rpcClient: client.New(),
+		config:    config,
+	}
+}
+
+// Signer returns the signer of the client
+func (c *VSLRPCClient) Signer() evm.Signer {
+	return c.signer
+}
+
+// sign signs a message with the client signer
+func (c *VSLRPCClient) sign(ctx context.Context, message any) (*evm.SignedComponents, error) {
+	if c.signer == nil {
+		return nil, errors.New("vsl client has no signer")
 	}
+	return evm.SignMessageWithSigner(ctx, c.signer, message)
 }
 
-func (c *VSLRPCClient) CallRaw(method string, params interface{}) (*gjson.Result, error) {
+// CallRaw calls a VSL JSON-RPC method and returns the whole response body
+//
+// Transport failures (connection errors, timeouts, 5xx and 429 responses) of read-only methods
+// are retried with exponential backoff until the context is done. Methods sending a signed message
+// are never retried, since the node may have applied a message whose response was lost, see
+// NonceManager.Do. A JSON-RPC error object is never retried and is returned as an *RPCError.
+func (c *VSLRPCClient) CallRaw(ctx context.Context, method string, params interface{}) (*gjson.Result, error) {
+	body := fiber.Map{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      c.requestId.Add(1),
+	}
+
+	maxRetries := c.config.MaxRetries
+	if !isReadOnly(method) {
+		maxRetries = 0
+	}
+	backoff := c.config.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, errors.Wrapf(ctx.Err(), "%s: %v", method, err)
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxRetryBackoff)
+		}
+
+		var response *gjson.Result
+		var retryable bool
+		response, retryable, err = c.call(ctx, body)
+		if err == nil {
+			return response, nil
+		}
+		if !retryable || ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
+	return nil, errors.Wrapf(err, "%s failed after %d attempts", method, maxRetries+1)
+}
+
+// isReadOnly reports whether a method only reads the state of the node, so that it is safe to retry
+func isReadOnly(method string) bool {
+	return strings.HasPrefix(method, "vsl_get") || strings.HasPrefix(method, "vsl_list")
+}
+
+// call sends a single request and reports whether a failure is worth retrying
+func (c *VSLRPCClient) call(ctx context.Context, body fiber.Map) (*gjson.Result, bool, error) {
 	response, err := c.rpcClient.Post(c.rpc, client.Config{
-		Body: fiber.Map{
-			"jsonrpc": "2.0",
-			"method":  method,
-			"params":  params,
-			"id":      1,
-		},
+		Ctx:     ctx,
+		Body:    body,
+		Timeout: c.config.Timeout,
 	})
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, true, errors.WithStack(err)
+	}
+	defer response.Close()
+
+	statusCode := response.StatusCode()
+	responseBytes := response.Body()
+	if statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests {
+		return nil, true, errors.Errorf("vsl rpc responded with status %d: %s", statusCode, string(responseBytes))
+	}
+	if !gjson.ValidBytes(responseBytes) {
+		return nil, false, errors.Errorf("vsl rpc responded with status %d and invalid JSON: %s", statusCode, string(responseBytes))
 	}
 
-	responseBody := gjson.ParseBytes(response.Body())
+	// The body buffer is released with the response, keep a copy
+	responseBody := gjson.ParseBytes(append([]byte(nil), responseBytes...))
 
-	if responseBody.Get("error").Exists() {
-		return nil, errors.New(responseBody.Get("error").Raw)
+	if responseError := responseBody.Get("error"); responseError.Exists() && responseError.Type != gjson.Null {
+		var rpcErr RPCError
+		if err := json.Unmarshal([]byte(responseError.Raw), &rpcErr); err != nil {
+			return nil, false, errors.Errorf("vsl rpc responded with malformed error: %s", responseError.Raw)
+		}
+		return nil, false, errors.WithStack(&rpcErr)
 	}
 
-	return &responseBody, nil
+	return &responseBody, false, nil
 }
 
 type CreateAccountParams struct {
@@ -57,13 +177,13 @@ type SignedCreateAccountParams struct {
 	evm.SignedComponents
 }
 
-func (c *VSLRPCClient) CreateAccount(params CreateAccountParams) (*string, error) {
-	signedMessage, err := evm.SignMessage(c.privateKey, params)
+func (c *VSLRPCClient) CreateAccount(ctx context.Context, params CreateAccountParams) (*string, error) {
+	signedMessage, err := c.sign(ctx, params)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	response, err := c.CallRaw("vsl_createAccount", fiber.Map{
+	response, err := c.CallRaw(ctx, "vsl_createAccount", fiber.Map{
 		"account_data": SignedCreateAccountParams{
 			CreateAccountParams: params,
 			SignedComponents:    *signedMessage,
@@ -89,13 +209,13 @@ type SignedPayParams struct {
 	evm.SignedComponents
 }
 
-func (c *VSLRPCClient) Pay(params PayParams) (*string, error) {
-	signedMessage, err := evm.SignMessage(c.privateKey, params)
+func (c *VSLRPCClient) Pay(ctx context.Context, params PayParams) (*string, error) {
+	signedMessage, err := c.sign(ctx, params)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	response, err := c.CallRaw("vsl_pay", fiber.Map{
+	response, err := c.CallRaw(ctx, "vsl_pay", fiber.Map{
 		"payment": SignedPayParams{
 			PayParams:        params,
 			SignedComponents: *signedMessage,
@@ -109,6 +229,13 @@ func (c *VSLRPCClient) Pay(params PayParams) (*string, error) {
 	return &result, nil
 }
 
+// NewEIP712Domain returns the EIP-712 domain of the params signed as typed data with
+// evm.SignTypedData, whose struct types are derived from the param structs, e.g. SubmitClaimParams.
+// The chain id of the VSL network binds the signatures to it, so they can't be replayed on another.
+func NewEIP712Domain(chainId *big.Int) evm.EIP712Domain {
+	return evm.EIP712Domain{Name: "VSL", Version: "1", ChainId: chainId}
+}
+
 type SubmitClaimParams struct {
 	Claim     string                   `json:"claim"`
 	ClaimType string                   `json:"claim_type"`
@@ -126,12 +253,12 @@ type SignedSubmitClaimParams struct {
 	evm.SignedComponents
 }
 
-func (c *VSLRPCClient) SubmitClaim(params SubmitClaimParams) (*string, error) {
-	signedMessage, err := evm.SignMessage(c.privateKey, params)
+func (c *VSLRPCClient) SubmitClaim(ctx context.Context, params SubmitClaimParams) (*string, error) {
+	signedMessage, err := c.sign(ctx, params)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	response, err := c.CallRaw("vsl_submitClaim", fiber.Map{
+	response, err := c.CallRaw(ctx, "vsl_submitClaim", fiber.Map{
 		"claim": SignedSubmitClaimParams{
 			SubmitClaimParams: params,
 			SignedComponents:  *signedMessage,
@@ -156,12 +283,12 @@ type SignedSettleClaimParams struct {
 	evm.SignedComponents
 }
 
-func (c *VSLRPCClient) SettleClaim(params SettleClaimParams) (*string, error) {
-	signedMessage, err := evm.SignMessage(c.privateKey, params)
+func (c *VSLRPCClient) SettleClaim(ctx context.Context, params SettleClaimParams) (*string, error) {
+	signedMessage, err := c.sign(ctx, params)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	response, err := c.CallRaw("vsl_settleClaim", fiber.Map{
+	response, err := c.CallRaw(ctx, "vsl_settleClaim", fiber.Map{
 		"settled_claim": SignedSettleClaimParams{
 			SettleClaimParams: params,
 			SignedComponents:  *signedMessage,
@@ -179,8 +306,8 @@ type GetAccountNonceParams struct {
 	AccountId string `json:"account_id"`
 }
 
-func (c *VSLRPCClient) GetAccountNonce(params GetAccountNonceParams) (*uint64, error) {
-	response, err := c.CallRaw("vsl_getAccountNonce", params)
+func (c *VSLRPCClient) GetAccountNonce(ctx context.Context, params GetAccountNonceParams) (*uint64, error) {
+	response, err := c.CallRaw(ctx, "vsl_getAccountNonce", params)
 	if err != nil {
 		return nil, err
 	}
@@ -193,10 +320,166 @@ type ListSubmittedClaimsForReceiverParams struct {
 	Since   abstract_types.Timestamp `json:"since"`
 }
 
-func (c *VSLRPCClient) ListSubmittedClaimsForReceiver(params ListSubmittedClaimsForReceiverParams) ([]gjson.Result, error) {
-	response, err := c.CallRaw("vsl_listSubmittedClaimsForReceiver", params)
+func (c *VSLRPCClient) ListSubmittedClaimsForReceiver(ctx context.Context, params ListSubmittedClaimsForReceiverParams) ([]TimestampedSubmittedClaim, error) {
+	response, err := c.CallRaw(ctx, "vsl_listSubmittedClaimsForReceiver", params)
+	if err != nil {
+		return nil, err
+	}
+	var claims []TimestampedSubmittedClaim
+	err = decodeResult(response, &claims)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAll(claims)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+type ListSettledClaimsForReceiverParams struct {
+	// Address of the receiver, empty lists the settled claims of every receiver
+	Address string                   `json:"address,omitempty"`
+	Since   abstract_types.Timestamp `json:"since"`
+}
+
+func (c *VSLRPCClient) ListSettledClaimsForReceiver(ctx context.Context, params ListSettledClaimsForReceiverParams) ([]TimestampedSettledClaim, error) {
+	return c.listSettledClaims(ctx, "vsl_listSettledClaimsForReceiver", params)
+}
+
+type ListSettledClaimsForSenderParams struct {
+	Address string                   `json:"address"`
+	Since   abstract_types.Timestamp `json:"since"`
+}
+
+func (c *VSLRPCClient) ListSettledClaimsForSender(ctx context.Context, params ListSettledClaimsForSenderParams) ([]TimestampedSettledClaim, error) {
+	return c.listSettledClaims(ctx, "vsl_listSettledClaimsForSender", params)
+}
+
+func (c *VSLRPCClient) listSettledClaims(ctx context.Context, method string, params any) ([]TimestampedSettledClaim, error) {
+	response, err := c.CallRaw(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+	var claims []TimestampedSettledClaim
+	err = decodeResult(response, &claims)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAll(claims)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+type ListSubmittedClaimsForSenderParams struct {
+	Address string                   `json:"address"`
+	Since   abstract_types.Timestamp `json:"since"`
+}
+
+func (c *VSLRPCClient) ListSubmittedClaimsForSender(ctx context.Context, params ListSubmittedClaimsForSenderParams) ([]TimestampedSubmittedClaim, error) {
+	response, err := c.CallRaw(ctx, "vsl_listSubmittedClaimsForSender", params)
+	if err != nil {
+		return nil, err
+	}
+	var claims []TimestampedSubmittedClaim
+	err = decodeResult(response, &claims)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAll(claims)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+type GetSettledClaimByIdParams struct {
+	ClaimId string `json:"claim_id"`
+}
+
+func (c *VSLRPCClient) GetSettledClaimById(ctx context.Context, params GetSettledClaimByIdParams) (*TimestampedSettledClaim, error) {
+	response, err := c.CallRaw(ctx, "vsl_getSettledClaimById", params)
+	if err != nil {
+		return nil, err
+	}
+	var claim TimestampedSettledClaim
+	err = decodeResult(response, &claim)
+	if err != nil {
+		return nil, err
+	}
+	err = claim.validate()
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+type GetSubmittedClaimByIdParams struct {
+	ClaimId string `json:"claim_id"`
+}
+
+func (c *VSLRPCClient) GetSubmittedClaimById(ctx context.Context, params GetSubmittedClaimByIdParams) (*TimestampedSubmittedClaim, error) {
+	response, err := c.CallRaw(ctx, "vsl_getSubmittedClaimById", params)
+	if err != nil {
+		return nil, err
+	}
+	var claim TimestampedSubmittedClaim
+	err = decodeResult(response, &claim)
+	if err != nil {
+		return nil, err
+	}
+	err = claim.validate()
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+type GetBalanceParams struct {
+	AccountId string `json:"account_id"`
+}
+
+func (c *VSLRPCClient) GetBalance(ctx context.Context, params GetBalanceParams) (*big.Int, error) {
+	response, err := c.CallRaw(ctx, "vsl_getBalance", params)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := ParseAmount(response.Get("result").String())
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+type GetAccountStateParams struct {
+	AccountId string `json:"account_id"`
+}
+
+func (c *VSLRPCClient) GetAccountState(ctx context.Context, params GetAccountStateParams) (*AccountState, error) {
+	response, err := c.CallRaw(ctx, "vsl_getAccountState", params)
+	if err != nil {
+		return nil, err
+	}
+	var state AccountState
+	err = decodeResult(response, &state)
+	if err != nil {
+		return nil, err
+	}
+	err = state.validate()
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetHealth returns "ok" when the node is up and ready
+func (c *VSLRPCClient) GetHealth(ctx context.Context) (*string, error) {
+	response, err := c.CallRaw(ctx, "vsl_getHealth", fiber.Map{})
 	if err != nil {
 		return nil, err
 	}
-	return response.Get("result").Array(), nil
+	health := response.Get("result").String()
+	return &health, nil
 }
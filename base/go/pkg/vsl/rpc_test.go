@@ -0,0 +1,176 @@
+package vsl
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// newTestServer serves the given responses in order, repeating the last one, and counts the requests
+func newTestServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*VSLRPCClient, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(requests.Add(1)) - 1
+		responses[min(i, len(responses)-1)](w)
+	}))
+	t.Cleanup(server.Close)
+	client := NewVSLRPCClientWithConfig(server.URL, nil, VSLRPCClientConfig{
+		Timeout:      time.Second,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	})
+	return client, &requests
+}
+
+func status(code int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		http.Error(w, http.StatusText(code), code)
+	}
+}
+
+func body(json string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(json))
+	}
+}
+
+func TestCallRawRetries(t *testing.T) {
+	ctx := context.Background()
+	ok := body(`{"jsonrpc":"2.0","id":1,"result":"ok"}`)
+
+	// Read-only methods are retried after 5xx and 429 responses
+	client, requests := newTestServer(t, status(http.StatusServiceUnavailable), status(http.StatusTooManyRequests), ok)
+	health, err := client.GetHealth(ctx)
+	if err != nil || *health != "ok" {
+		t.Fatalf("Expected the call to succeed after the retries, got %v", err)
+	}
+	if requests.Load() != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", requests.Load())
+	}
+
+	client, requests = newTestServer(t, status(http.StatusBadGateway))
+	_, err = client.GetHealth(ctx)
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempts") || requests.Load() != 3 {
+		t.Fatalf("Expected the retries to be exhausted after 3 attempts, got %v after %d", err, requests.Load())
+	}
+
+	// Signed messages are never retried, the node may have applied them
+	client, requests = newTestServer(t, status(http.StatusServiceUnavailable), ok)
+	_, err = client.CallRaw(ctx, "vsl_submitClaim", map[string]string{})
+	if err == nil || requests.Load() != 1 {
+		t.Fatalf("Expected a single attempt of a signed message, got %v after %d", err, requests.Load())
+	}
+
+	// Client errors and invalid responses are not retried
+	client, requests = newTestServer(t, status(http.StatusBadRequest), ok)
+	_, err = client.GetHealth(ctx)
+	if err == nil || requests.Load() != 1 {
+		t.Fatalf("Expected a single attempt after a client error, got %v after %d", err, requests.Load())
+	}
+
+	// The backoff stops when the context is done
+	client, _ = newTestServer(t, status(http.StatusServiceUnavailable))
+	client.config.RetryBackoff = time.Hour
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = client.GetHealth(ctx)
+	if err == nil || ctx.Err() == nil {
+		t.Fatalf("Expected the call to stop with the context, got %v", err)
+	}
+}
+
+func TestCallRawRPCError(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		code     int
+		message  string
+		fails    bool
+		typed    bool
+	}{
+		{"error", `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"claim expired"}}`, -32000, "claim expired", true, true},
+		{"error data", `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"invalid params","data":"missing from"}}`, -32602, "invalid params", true, true},
+		{"null error", `{"jsonrpc":"2.0","id":1,"error":null,"result":"ok"}`, 0, "", false, false},
+		{"malformed error", `{"jsonrpc":"2.0","id":1,"error":{"code":"x"}}`, 0, "", true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, requests := newTestServer(t, body(test.response))
+			_, err := client.GetHealth(context.Background())
+			if (err != nil) != test.fails {
+				t.Fatalf("Expected failure %v, got %v", test.fails, err)
+			}
+			rpcErr, typed := AsRPCError(err)
+			if typed != test.typed {
+				t.Fatalf("Expected a typed error %v, got %v", test.typed, err)
+			}
+			if typed && (rpcErr.Code != test.code || rpcErr.Message != test.message) {
+				t.Fatalf("Unexpected error %+v", rpcErr)
+			}
+			if requests.Load() != 1 {
+				t.Fatalf("Expected an answered error not to be retried, got %d attempts", requests.Load())
+			}
+		})
+	}
+}
+
+// The type encodings and hashes are what wallets and contracts reproduce, they must not change
+func TestEIP712Types(t *testing.T) {
+	domain := NewEIP712Domain(big.NewInt(1))
+	tests := []struct {
+		message    any
+		typeString string
+		hash       string
+	}{
+		{
+			SubmitClaimParams{
+				Claim:     "0x01",
+				ClaimType: "EVMViewFn",
+				Proof:     "0x02",
+				Nonce:     "3",
+				To:        []string{"0x0C5d19cb92ad3b75b74c2301947018Dfb913064a"},
+				Quorum:    1,
+				From:      "0x220c902381bdc091cf13d5f8efd8432f264b8f9a",
+				Expires:   abstract_types.Timestamp{Seconds: 1700000000, Nanos: 5},
+				Fee:       "100",
+			},
+			"SubmitClaimParams(string claim,string claim_type,string proof,string nonce,string[] to,uint16 quorum,string from,Timestamp expires,string fee)Timestamp(uint64 seconds,uint32 nanos)",
+			"0xa88b894720e46827c3327cae0d89e8662390c61e1fba583b85d02162e7e3ad15",
+		},
+		{
+			SettleClaimParams{
+				From:          "0x0C5d19cb92ad3b75b74c2301947018Dfb913064a",
+				Nonce:         "4",
+				TargetClaimId: "0x05",
+			},
+			"SettleClaimParams(string from,string nonce,string target_claim_id)",
+			"0x935c4e8de721606c3880fba3d70f43fa0b1c03d321e92469b7560c9fb1ad842a",
+		},
+	}
+	for _, test := range tests {
+		typeString, err := evm.EIP712TypeString(test.message)
+		if err != nil {
+			t.Fatalf("Failed to encode type: %v", err)
+		}
+		if typeString != test.typeString {
+			t.Fatalf("Unexpected type encoding %s", typeString)
+		}
+		hash, err := evm.TypedDataHash(domain, test.message)
+		if err != nil {
+			t.Fatalf("Failed to hash typed data: %v", err)
+		}
+		if hash != common.HexToHash(test.hash) {
+			t.Fatalf("Unexpected typed data hash %s of %s", hash.Hex(), typeString)
+		}
+	}
+}
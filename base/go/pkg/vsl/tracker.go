@@ -0,0 +1,212 @@
+package vsl
+
+import (
+	"base/pkg/abstract_types"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultTrackerInterval is the default interval between two polls of a ClaimTracker
+const DefaultTrackerInterval = 5 * time.Second
+
+type ClaimEventType string
+
+const (
+	// ClaimQuorumReached is emitted when at least the requested quorum of verifiers settled the claim
+	ClaimQuorumReached ClaimEventType = "QuorumReached"
+	// ClaimSettled is emitted when the claim is settled
+	ClaimSettled ClaimEventType = "Settled"
+	// ClaimExpired is emitted when the claim expired without being settled
+	ClaimExpired ClaimEventType = "Expired"
+)
+
+// ClaimEvent is a step in the lifecycle of a tracked claim
+type ClaimEvent struct {
+	Type    ClaimEventType
+	ClaimId string
+	// Timestamp is the settlement time recorded by VSL, or the expiry time of an expired claim
+	Timestamp abstract_types.Timestamp
+	// SettledClaim is the settled claim, for QuorumReached and Settled events
+	SettledClaim *TimestampedSettledClaim
+}
+
+// TrackedClaim is a submitted claim followed by a ClaimTracker
+type TrackedClaim struct {
+	Id      string
+	Quorum  uint16
+	Expires abstract_types.Timestamp
+
+	// checked is set once the claim was looked up by id, in case it settled before being tracked
+	checked bool
+}
+
+// ClaimTracker follows the claims submitted by an account until they are settled or expire.
+//
+// The tracker polls the claims settled for the account, and emits a QuorumReached and a Settled
+// event for each tracked claim that gets settled, or an Expired event for each tracked claim that
+// is still not settled at its expiry. A claim is no longer tracked once it settled or expired.
+type ClaimTracker struct {
+	client   *VSLRPCClient
+	owner    string
+	interval time.Duration
+	events   chan ClaimEvent
+
+	mu     sync.Mutex
+	claims map[string]*TrackedClaim
+	since  abstract_types.Timestamp
+	now    func() time.Time
+}
+
+// NewClaimTracker creates a tracker of the claims submitted by owner, polling every interval
+func NewClaimTracker(client *VSLRPCClient, owner string, interval time.Duration) *ClaimTracker {
+	return &ClaimTracker{
+		client:   client,
+		owner:    owner,
+		interval: interval,
+		events:   make(chan ClaimEvent, 64),
+		claims:   map[string]*TrackedClaim{},
+	}
+}
+
+// Events returns the channel the events are emitted on, closed when Run returns
+func (t *ClaimTracker) Events() <-chan ClaimEvent {
+	return t.events
+}
+
+// Track starts following a submitted claim
+func (t *ClaimTracker) Track(claimId string, quorum uint16, expires abstract_types.Timestamp) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.claims[claimId] = &TrackedClaim{
+		Id:      claimId,
+		Quorum:  quorum,
+		Expires: expires,
+	}
+}
+
+// Pending returns the claims that are neither settled nor expired yet
+func (t *ClaimTracker) Pending() []TrackedClaim {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pending := make([]TrackedClaim, 0, len(t.claims))
+	for _, claim := range t.claims {
+		pending = append(pending, *claim)
+	}
+	return pending
+}
+
+// Run polls until the context is done, then closes the events channel
+func (t *ClaimTracker) Run(ctx context.Context) error {
+	defer close(t.events)
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		err := t.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			// Polling failures are transient, the claims are checked again on the next tick
+			log.Printf("Failed to poll tracked claims: %+v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks the tracked claims once and emits their events
+func (t *ClaimTracker) Poll(ctx context.Context) error {
+	t.mu.Lock()
+	since := t.since
+	unchecked := []string{}
+	for id, claim := range t.claims {
+		if !claim.checked {
+			unchecked = append(unchecked, id)
+		}
+	}
+	t.mu.Unlock()
+
+	settled, err := t.client.ListSettledClaimsForReceiver(ctx, ListSettledClaimsForReceiverParams{
+		Address: t.owner,
+		Since:   since,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Claims tracked after they settled are not listed anymore, look them up once
+	listed := len(settled)
+	for _, id := range unchecked {
+		claim, err := t.client.GetSettledClaimById(ctx, GetSettledClaimByIdParams{ClaimId: id})
+		if err != nil {
+			if _, ok := AsRPCError(err); ok {
+				continue
+			}
+			return errors.WithStack(err)
+		}
+		settled = append(settled, *claim)
+	}
+
+	events := []ClaimEvent{}
+	t.mu.Lock()
+	for i := range settled {
+		claim := &settled[i]
+		if i < listed && after(claim.Timestamp, t.since) {
+			t.since = claim.Timestamp
+		}
+		tracked, ok := t.claims[claim.Data.VerifiedClaim.ClaimId]
+		if !ok {
+			continue
+		}
+		delete(t.claims, tracked.Id)
+		if len(claim.Data.Verifiers) >= int(tracked.Quorum) {
+			events = append(events, ClaimEvent{Type: ClaimQuorumReached, ClaimId: tracked.Id, Timestamp: claim.Timestamp, SettledClaim: claim})
+		}
+		events = append(events, ClaimEvent{Type: ClaimSettled, ClaimId: tracked.Id, Timestamp: claim.Timestamp, SettledClaim: claim})
+	}
+	for _, id := range unchecked {
+		if tracked, ok := t.claims[id]; ok {
+			tracked.checked = true
+		}
+	}
+	now := t.clock()
+	for id, tracked := range t.claims {
+		if tracked.Expires.Seconds > uint64(now.Unix()) {
+			continue
+		}
+		delete(t.claims, id)
+		events = append(events, ClaimEvent{Type: ClaimExpired, ClaimId: id, Timestamp: tracked.Expires})
+	}
+	t.mu.Unlock()
+
+	for _, event := range events {
+		select {
+		case t.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (t *ClaimTracker) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// after reports whether a is strictly after b
+func after(a abstract_types.Timestamp, b abstract_types.Timestamp) bool {
+	if a.Seconds != b.Seconds {
+		return a.Seconds > b.Seconds
+	}
+	return a.Nanos > b.Nanos
+}
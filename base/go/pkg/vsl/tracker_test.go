@@ -0,0 +1,99 @@
+package vsl_test
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+	"base/pkg/vsl"
+	"base/pkg/vsl/vsltest"
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestClaimTracker(t *testing.T) {
+	ctx := context.Background()
+	node := vsltest.NewNode(t, nil)
+	submitterKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	verifierKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	submitter := node.Client(evm.NewPrivateKeySigner(submitterKey))
+	verifier := node.Client(evm.NewPrivateKeySigner(verifierKey))
+	submitterAddress := submitter.Signer().Address().Hex()
+	verifierAddress := verifier.Signer().Address().Hex()
+	node.Fund(submitterAddress, big.NewInt(10))
+
+	expires := abstract_types.Timestamp{Seconds: uint64(time.Now().Add(time.Minute).Unix())}
+	claimIds := []string{}
+	for nonce := 0; nonce < 3; nonce++ {
+		claimId, err := submitter.SubmitClaim(ctx, vsl.SubmitClaimParams{
+			Claim:     fmt.Sprintf("claim %d", nonce),
+			ClaimType: "Test",
+			Nonce:     fmt.Sprintf("%d", nonce),
+			To:        []string{verifierAddress},
+			Quorum:    1,
+			From:      submitterAddress,
+			Expires:   expires,
+			Fee:       "1",
+		})
+		if err != nil {
+			t.Fatalf("Failed to submit claim: %v", err)
+		}
+		claimIds = append(claimIds, *claimId)
+	}
+	settle := func(nonce int, claimId string) {
+		_, err := verifier.SettleClaim(ctx, vsl.SettleClaimParams{
+			From:          verifierAddress,
+			Nonce:         fmt.Sprintf("%d", nonce),
+			TargetClaimId: claimId,
+		})
+		if err != nil {
+			t.Fatalf("Failed to settle claim: %v", err)
+		}
+	}
+
+	tracker := vsl.NewClaimTracker(submitter, submitterAddress, time.Second)
+	// Settled before being tracked
+	settle(0, claimIds[0])
+	tracker.Track(claimIds[0], 1, expires)
+	// Settled while tracked, by fewer verifiers than the tracked quorum
+	tracker.Track(claimIds[1], 2, expires)
+	// Never settled, tracked with an expiry in the past
+	tracker.Track(claimIds[2], 1, abstract_types.Timestamp{Seconds: uint64(time.Now().Add(-time.Second).Unix())})
+
+	err = tracker.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Failed to poll: %v", err)
+	}
+	settle(1, claimIds[1])
+	err = tracker.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Failed to poll: %v", err)
+	}
+
+	events := map[string][]vsl.ClaimEventType{}
+	for len(tracker.Events()) > 0 {
+		event := <-tracker.Events()
+		events[event.ClaimId] = append(events[event.ClaimId], event.Type)
+	}
+	for i, expected := range [][]vsl.ClaimEventType{
+		{vsl.ClaimQuorumReached, vsl.ClaimSettled},
+		{vsl.ClaimSettled},
+		{vsl.ClaimExpired},
+	} {
+		if fmt.Sprint(events[claimIds[i]]) != fmt.Sprint(expected) {
+			t.Fatalf("Claim %d: expected events %v, got %v", i, expected, events[claimIds[i]])
+		}
+	}
+	if len(tracker.Pending()) != 0 {
+		t.Fatalf("Expected no pending claims, got %+v", tracker.Pending())
+	}
+}
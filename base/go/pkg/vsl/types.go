@@ -0,0 +1,153 @@
+package vsl
+
+import (
+	"base/pkg/abstract_types"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// SignatureV is the recovery id of a signature returned by the VSL node, which may be
+// encoded either as a JSON number or as a (hex) string
+type SignatureV uint8
+
+func (v *SignatureV) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	parsed, err := strconv.ParseUint(raw, 0, 8)
+	if err != nil {
+		return errors.Wrapf(err, "invalid signature v %s", string(data))
+	}
+	*v = SignatureV(parsed)
+	return nil
+}
+
+// Signature is the signature part of a signed message stored by VSL
+type Signature struct {
+	Hash string     `json:"hash"`
+	R    string     `json:"r"`
+	S    string     `json:"s"`
+	V    SignatureV `json:"v"`
+}
+
+func (s *Signature) validate() error {
+	if s.Hash == "" || s.R == "" || s.S == "" {
+		return errors.New("missing signature fields")
+	}
+	return nil
+}
+
+// Timestamped wraps a message stored by VSL with its id and the time it was recorded
+type Timestamped[T any] struct {
+	Id        string                   `json:"id"`
+	Data      T                        `json:"data"`
+	Timestamp abstract_types.Timestamp `json:"timestamp"`
+}
+
+func (t *Timestamped[T]) validate() error {
+	if t.Id == "" {
+		return errors.New("missing id")
+	}
+	if data, ok := any(&t.Data).(interface{ validate() error }); ok {
+		if err := data.validate(); err != nil {
+			return errors.Wrapf(err, "invalid data of %s", t.Id)
+		}
+	}
+	return nil
+}
+
+// SubmittedClaim is a signed claim verification request, as listed by vsl_listSubmittedClaimsForReceiver
+type SubmittedClaim struct {
+	SubmitClaimParams
+	Signature
+}
+
+func (c *SubmittedClaim) validate() error {
+	if c.Claim == "" || c.ClaimType == "" || c.From == "" || c.Nonce == "" {
+		return errors.New("missing submitted claim fields")
+	}
+	return c.Signature.validate()
+}
+
+// VerifiedClaim is the claim that was verified and settled
+type VerifiedClaim struct {
+	// Claim is the original claim
+	Claim string `json:"claim"`
+	// ClaimId is the id of the submitted claim
+	ClaimId string `json:"claim_id"`
+	// ClaimType is the type of the claim
+	ClaimType string `json:"claim_type"`
+	// ClaimOwner is the address of the client which submitted the claim
+	ClaimOwner string `json:"claim_owner"`
+}
+
+// SettledClaim is a signed settled claim, as listed by vsl_listSettledClaimsForReceiver
+type SettledClaim struct {
+	VerifiedClaim VerifiedClaim `json:"verified_claim"`
+	// Verifiers are the addresses of the verifiers which signed the verified claim
+	Verifiers []string `json:"verifiers"`
+	Signature
+}
+
+func (c *SettledClaim) validate() error {
+	if c.VerifiedClaim.ClaimId == "" || c.VerifiedClaim.ClaimType == "" || c.VerifiedClaim.ClaimOwner == "" {
+		return errors.New("missing verified claim fields")
+	}
+	return c.Signature.validate()
+}
+
+// AccountState is the state of an account created with vsl_createAccount, as returned by vsl_getAccountState
+type AccountState struct {
+	CreateAccountParams
+}
+
+func (s *AccountState) validate() error {
+	if s.OwnerAddress == "" {
+		return errors.New("missing account owner")
+	}
+	return nil
+}
+
+type TimestampedSubmittedClaim = Timestamped[SubmittedClaim]
+
+type TimestampedSettledClaim = Timestamped[SettledClaim]
+
+// decodeResult decodes the result of a response into out
+func decodeResult(response *gjson.Result, out any) error {
+	result := response.Get("result")
+	if !result.Exists() {
+		return errors.New("response has no result")
+	}
+	if err := json.Unmarshal([]byte(result.Raw), out); err != nil {
+		return errors.Wrap(err, "failed to decode result")
+	}
+	return nil
+}
+
+// validateAll checks that every listed message has its required fields, so that a renamed field
+// fails loudly instead of producing empty values
+func validateAll[T any](items []Timestamped[T]) error {
+	for i := range items {
+		if err := items[i].validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseAmount parses a VSL token amount, either decimal or 0x-prefixed hex
+func ParseAmount(amount string) (*big.Int, error) {
+	value, ok := new(big.Int), false
+	if hex, isHex := strings.CutPrefix(amount, "0x"); isHex {
+		_, ok = value.SetString(hex, 16)
+	} else {
+		_, ok = value.SetString(amount, 10)
+	}
+	if !ok || value.Sign() < 0 {
+		return nil, errors.Errorf("invalid amount %q", amount)
+	}
+	return value, nil
+}
@@ -0,0 +1,125 @@
+package vsl
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestSignatureVUnmarshal(t *testing.T) {
+	tests := []struct {
+		json  string
+		v     SignatureV
+		fails bool
+	}{
+		{`27`, 27, false},
+		{`"28"`, 28, false},
+		{`"0x1b"`, 27, false},
+		{`"0x0"`, 0, false},
+		{`256`, 0, true},
+		{`"v"`, 0, true},
+		{`null`, 0, true},
+	}
+	for _, test := range tests {
+		var v SignatureV
+		err := json.Unmarshal([]byte(test.json), &v)
+		if (err != nil) != test.fails || v != test.v {
+			t.Errorf("Unmarshal of %s: expected %d, failure %v, got %d, %v", test.json, test.v, test.fails, v, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	signature := `"hash":"0x01","r":"0x02","s":"0x03","v":27`
+	submitted := `"claim":"claim","claim_type":"EVMViewFn","from":"0x04","nonce":"1",` + signature
+	settled := `"verified_claim":{"claim":"claim","claim_id":"0x05","claim_type":"EVMViewFn","claim_owner":"0x04"},"verifiers":["0x06"],` + signature
+	tests := []struct {
+		name    string
+		json    string
+		settled bool
+		fails   bool
+	}{
+		{"submitted claim", `{"id":"0x05","data":{` + submitted + `}}`, false, false},
+		{"submitted claim without id", `{"data":{` + submitted + `}}`, false, true},
+		{"submitted claim without type", `{"id":"0x05","data":{"claim":"claim","from":"0x04","nonce":"1",` + signature + `}}`, false, true},
+		{"submitted claim without signature", `{"id":"0x05","data":{"claim":"claim","claim_type":"EVMViewFn","from":"0x04","nonce":"1"}}`, false, true},
+		{"settled claim", `{"id":"0x05","data":{` + settled + `}}`, true, false},
+		{"settled claim without owner", `{"id":"0x05","data":{"verified_claim":{"claim_id":"0x05","claim_type":"EVMViewFn"},` + signature + `}}`, true, true},
+		{"settled claim without signature", `{"id":"0x05","data":{"verified_claim":{"claim_id":"0x05","claim_type":"EVMViewFn","claim_owner":"0x04"}}}`, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := gjson.Parse(`{"result":[` + test.json + `]}`)
+			var err error
+			if test.settled {
+				var claims []TimestampedSettledClaim
+				err = decodeResult(&response, &claims)
+				if err == nil {
+					err = validateAll(claims)
+				}
+			} else {
+				var claims []TimestampedSubmittedClaim
+				err = decodeResult(&response, &claims)
+				if err == nil {
+					err = validateAll(claims)
+				}
+			}
+			if (err != nil) != test.fails {
+				t.Fatalf("Expected failure %v, got %v", test.fails, err)
+			}
+		})
+	}
+}
+
+func TestDecodeResult(t *testing.T) {
+	tests := []struct {
+		response string
+		fails    bool
+	}{
+		{`{"result":[]}`, false},
+		{`{"result":null}`, false},
+		{`{"error":null}`, true},
+		{`{"result":"claims"}`, true},
+		{`{"result":[{"id":5}]}`, true},
+	}
+	for _, test := range tests {
+		response := gjson.Parse(test.response)
+		var claims []TimestampedSubmittedClaim
+		err := decodeResult(&response, &claims)
+		if (err != nil) != test.fails {
+			t.Errorf("Decoding %s: expected failure %v, got %v", test.response, test.fails, err)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		value  int64
+		fails  bool
+	}{
+		{"0", 0, false},
+		{"1000", 1000, false},
+		{"010", 10, false},
+		{"0x10", 16, false},
+		{"0xff", 255, false},
+		{"", 0, true},
+		{"0x", 0, true},
+		{"-1", 0, true},
+		{"1.5", 0, true},
+		{"1_000", 0, true},
+		{"1e18", 0, true},
+	}
+	for _, test := range tests {
+		value, err := ParseAmount(test.amount)
+		if (err != nil) != test.fails {
+			t.Errorf("ParseAmount(%q): expected failure %v, got %v", test.amount, test.fails, err)
+			continue
+		}
+		if !test.fails && value.Cmp(big.NewInt(test.value)) != 0 {
+			t.Errorf("ParseAmount(%q): expected %d, got %s", test.amount, test.value, value)
+		}
+	}
+}
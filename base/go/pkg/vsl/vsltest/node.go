@@ -0,0 +1,560 @@
+// Package vsltest provides an in-memory VSL node served over JSON-RPC, so that code talking to
+// VSL can be tested with `go test` instead of the vsl-core Docker image.
+package vsltest
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+	"base/pkg/vsl"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeRejected       = -32000
+)
+
+// Node is a fake VSL node keeping accounts, balances, nonces, submitted and settled claims in memory.
+//
+// Signed messages are checked the way the real node does: the signature must match the RLP+EIP-191
+// hash of the message and recover to its `from` address, and the nonce must be the next nonce of
+// the account. Submitted claims expire at their `expires` time, refunding their fee, and are
+// settled once `quorum` of their receivers settled them, paying the fee to those verifiers.
+type Node struct {
+	// URL is the JSON-RPC endpoint of the node
+	URL string
+
+	server *httptest.Server
+	signer *evm.PrivateKeySigner
+
+	mu            sync.Mutex
+	clockOffset   time.Duration
+	lastTimestamp abstract_types.Timestamp
+	accounts      map[string]*account
+	created       map[string]*vsl.AccountState
+	submitted     []*submittedClaim
+	claims        map[string]*submittedClaim
+	settled       []*vsl.TimestampedSettledClaim
+}
+
+type account struct {
+	balance *big.Int
+	nonce   uint64
+}
+
+type submittedClaim struct {
+	message   vsl.TimestampedSubmittedClaim
+	verifiers []string
+	settled   bool
+	expired   bool
+}
+
+// settledClaimMessage is the part of a settled claim signed by the node
+type settledClaimMessage struct {
+	VerifiedClaim vsl.VerifiedClaim
+	Verifiers     []string
+}
+
+// NewNode starts a node seeded with the accounts of genesis, which may be nil.
+// The node is stopped when the test ends.
+func NewNode(t testing.TB, genesis *vsl.Genesis) *Node {
+	t.Helper()
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate node key: %v", err)
+	}
+	node := &Node{
+		signer:   evm.NewPrivateKeySigner(privateKey),
+		accounts: map[string]*account{},
+		created:  map[string]*vsl.AccountState{},
+		claims:   map[string]*submittedClaim{},
+	}
+	if genesis != nil {
+		for _, genesisAccount := range genesis.Accounts {
+			balance, ok := new(big.Int).SetString(genesisAccount.Balance, 10)
+			if !ok {
+				t.Fatalf("Invalid genesis balance %q of %s", genesisAccount.Balance, genesisAccount.Id)
+			}
+			node.account(genesisAccount.Id).balance.Set(balance)
+		}
+	}
+
+	node.server = httptest.NewServer(http.HandlerFunc(node.serveHTTP))
+	node.URL = node.server.URL
+	t.Cleanup(node.server.Close)
+	return node
+}
+
+// Client returns a VSL client of the node which signs with signer and doesn't retry
+func (n *Node) Client(signer evm.Signer) *vsl.VSLRPCClient {
+	return vsl.NewVSLRPCClientWithConfig(n.URL, signer, vsl.VSLRPCClientConfig{
+		Timeout: 10 * time.Second,
+	})
+}
+
+// Address returns the address of the key the node signs settled claims with
+func (n *Node) Address() string {
+	return n.signer.Address().Hex()
+}
+
+// Fund adds amount to the balance of an account
+func (n *Node) Fund(address string, amount *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	balance := n.account(address).balance
+	balance.Add(balance, amount)
+}
+
+// Balance returns the balance of an account
+func (n *Node) Balance(address string) *big.Int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return new(big.Int).Set(n.account(address).balance)
+}
+
+// Advance moves the clock of the node forward, expiring the claims that are past their expiry
+func (n *Node) Advance(d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.clockOffset += d
+	n.expire()
+}
+
+func (n *Node) account(address string) *account {
+	key := strings.ToLower(address)
+	acc, ok := n.accounts[key]
+	if !ok {
+		acc = &account{balance: new(big.Int)}
+		n.accounts[key] = acc
+	}
+	return acc
+}
+
+func (n *Node) now() abstract_types.Timestamp {
+	now := time.Now().Add(n.clockOffset)
+	return abstract_types.Timestamp{
+		Seconds: uint64(now.Unix()),
+		Nanos:   uint32(now.Nanosecond()),
+	}
+}
+
+// nextTimestamp returns a timestamp for a new message, strictly after the previous one
+func (n *Node) nextTimestamp() abstract_types.Timestamp {
+	timestamp := n.now()
+	if !after(timestamp, n.lastTimestamp) {
+		timestamp = n.lastTimestamp
+		timestamp.Tick()
+	}
+	n.lastTimestamp = timestamp
+	return timestamp
+}
+
+// expire marks the claims past their expiry as expired and refunds their fee
+func (n *Node) expire() {
+	now := n.now()
+	for _, claim := range n.submitted {
+		if claim.settled || claim.expired || after(claim.message.Data.Expires, now) {
+			continue
+		}
+		claim.expired = true
+		fee, _ := vsl.ParseAmount(claim.message.Data.Fee)
+		owner := n.account(claim.message.Data.From).balance
+		owner.Add(owner, fee)
+	}
+}
+
+// useNonce checks that nonce is the next nonce of the account and consumes it
+func (n *Node) useNonce(address string, nonce string) error {
+	acc := n.account(address)
+	parsed, err := strconv.ParseUint(nonce, 10, 64)
+	if err != nil {
+		return invalidParams(errors.Errorf("invalid nonce %q", nonce))
+	}
+	if parsed != acc.nonce {
+		return &rpcError{code: vsl.ErrorCodeInvalidNonce, err: errors.Errorf("invalid nonce %d, expected %d", parsed, acc.nonce)}
+	}
+	acc.nonce++
+	return nil
+}
+
+type request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Id     json.RawMessage `json:"id"`
+}
+
+type response struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Id      any           `json:"id"`
+	Result  any           `json:"result,omitempty"`
+	Error   *vsl.RPCError `json:"error,omitempty"`
+}
+
+// rpcError is returned by handlers to answer with a specific JSON-RPC error code
+type rpcError struct {
+	code int
+	err  error
+}
+
+func (e *rpcError) Error() string {
+	return e.err.Error()
+}
+
+func invalidParams(err error) error {
+	return &rpcError{code: codeInvalidParams, err: err}
+}
+
+func (n *Node) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "invalid JSON-RPC request", http.StatusBadRequest)
+		return
+	}
+
+	resp := response{JSONRPC: "2.0", Id: req.Id}
+	result, err := n.handle(req.Method, req.Params)
+	if err != nil {
+		code := codeRejected
+		if handlerErr, ok := err.(*rpcError); ok {
+			code = handlerErr.code
+		}
+		resp.Error = &vsl.RPCError{Code: code, Message: err.Error()}
+	} else {
+		resp.Result = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (n *Node) handle(method string, params json.RawMessage) (any, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.expire()
+
+	switch method {
+	case "vsl_getHealth":
+		return "ok", nil
+	case "vsl_getBalance":
+		return handle(params, func(p vsl.GetBalanceParams) (any, error) {
+			if !common.IsHexAddress(p.AccountId) {
+				return nil, &rpcError{code: codeInvalidParams, err: errors.Errorf("invalid account id %q", p.AccountId)}
+			}
+			return n.account(p.AccountId).balance.String(), nil
+		})
+	case "vsl_getAccountNonce":
+		return handle(params, func(p vsl.GetAccountNonceParams) (any, error) {
+			return n.account(p.AccountId).nonce, nil
+		})
+	case "vsl_createAccount":
+		return handle(params, func(p struct {
+			AccountData vsl.SignedCreateAccountParams `json:"account_data"`
+		}) (any, error) {
+			return n.createAccount(p.AccountData)
+		})
+	case "vsl_getAccountState":
+		return handle(params, func(p vsl.GetAccountStateParams) (any, error) {
+			state, ok := n.created[strings.ToLower(p.AccountId)]
+			if !ok {
+				return nil, errors.Errorf("account %s not found", p.AccountId)
+			}
+			return state, nil
+		})
+	case "vsl_pay":
+		return handle(params, func(p struct {
+			Payment vsl.SignedPayParams `json:"payment"`
+		}) (any, error) {
+			return n.pay(p.Payment)
+		})
+	case "vsl_submitClaim":
+		return handle(params, func(p struct {
+			Claim vsl.SignedSubmitClaimParams `json:"claim"`
+		}) (any, error) {
+			return n.submitClaim(p.Claim)
+		})
+	case "vsl_settleClaim":
+		return handle(params, func(p struct {
+			SettledClaim vsl.SignedSettleClaimParams `json:"settled_claim"`
+		}) (any, error) {
+			return n.settleClaim(p.SettledClaim)
+		})
+	case "vsl_getSubmittedClaimById":
+		return handle(params, func(p vsl.GetSubmittedClaimByIdParams) (any, error) {
+			claim, ok := n.claims[p.ClaimId]
+			if !ok {
+				return nil, errors.Errorf("claim %s not found", p.ClaimId)
+			}
+			return claim.message, nil
+		})
+	case "vsl_getSettledClaimById":
+		return handle(params, func(p vsl.GetSettledClaimByIdParams) (any, error) {
+			for _, claim := range n.settled {
+				if claim.Id == p.ClaimId {
+					return claim, nil
+				}
+			}
+			return nil, errors.Errorf("settled claim %s not found", p.ClaimId)
+		})
+	case "vsl_listSubmittedClaimsForReceiver":
+		return handle(params, func(p vsl.ListSubmittedClaimsForReceiverParams) (any, error) {
+			return n.listSubmitted(p.Since, func(claim *submittedClaim) bool {
+				return !claim.settled && !claim.expired && containsAddress(claim.message.Data.To, p.Address)
+			}), nil
+		})
+	case "vsl_listSubmittedClaimsForSender":
+		return handle(params, func(p vsl.ListSubmittedClaimsForSenderParams) (any, error) {
+			return n.listSubmitted(p.Since, func(claim *submittedClaim) bool {
+				return strings.EqualFold(claim.message.Data.From, p.Address)
+			}), nil
+		})
+	case "vsl_listSettledClaimsForReceiver":
+		return handle(params, func(p vsl.ListSettledClaimsForReceiverParams) (any, error) {
+			return n.listSettled(p.Since, func(claim *vsl.TimestampedSettledClaim) bool {
+				return p.Address == "" || strings.EqualFold(claim.Data.VerifiedClaim.ClaimOwner, p.Address)
+			}), nil
+		})
+	case "vsl_listSettledClaimsForSender":
+		return handle(params, func(p vsl.ListSettledClaimsForSenderParams) (any, error) {
+			return n.listSettled(p.Since, func(claim *vsl.TimestampedSettledClaim) bool {
+				return containsAddress(claim.Data.Verifiers, p.Address)
+			}), nil
+		})
+	default:
+		return nil, &rpcError{code: codeMethodNotFound, err: errors.Errorf("method %s not found", method)}
+	}
+}
+
+// handle decodes the params of a request and calls the handler with them
+func handle[P any](params json.RawMessage, handler func(P) (any, error)) (any, error) {
+	var p P
+	err := json.Unmarshal(params, &p)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	return handler(p)
+}
+
+func (n *Node) createAccount(params vsl.SignedCreateAccountParams) (any, error) {
+	err := checkSignature(params.CreateAccountParams, params.SignedComponents, params.OwnerAddress)
+	if err != nil {
+		return nil, err
+	}
+	id := messageId(params.OwnerAddress, "", params.Script+params.Label)
+	if _, ok := n.created[strings.ToLower(id)]; ok {
+		return nil, errors.Errorf("account %s already exists", id)
+	}
+	n.created[strings.ToLower(id)] = &vsl.AccountState{CreateAccountParams: params.CreateAccountParams}
+	return id, nil
+}
+
+func (n *Node) pay(payment vsl.SignedPayParams) (any, error) {
+	err := checkSignature(payment.PayParams, payment.SignedComponents, payment.From)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := vsl.ParseAmount(payment.Amount)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	from := n.account(payment.From).balance
+	if from.Cmp(amount) < 0 {
+		return nil, errors.Errorf("insufficient balance %s to pay %s", from, amount)
+	}
+	err = n.useNonce(payment.From, payment.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	from.Sub(from, amount)
+	to := n.account(payment.To).balance
+	to.Add(to, amount)
+	return messageId(payment.From, payment.Nonce, payment.To+payment.Amount), nil
+}
+
+func (n *Node) submitClaim(claim vsl.SignedSubmitClaimParams) (any, error) {
+	err := checkSignature(claim.SubmitClaimParams, claim.SignedComponents, claim.From)
+	if err != nil {
+		return nil, err
+	}
+	if len(claim.To) == 0 {
+		return nil, invalidParams(errors.New("claim has no receivers"))
+	}
+	if claim.Quorum == 0 || int(claim.Quorum) > len(claim.To) {
+		return nil, invalidParams(errors.Errorf("invalid quorum %d for %d receivers", claim.Quorum, len(claim.To)))
+	}
+	if !after(claim.Expires, n.now()) {
+		return nil, errors.New("claim already expired")
+	}
+	fee, err := vsl.ParseAmount(claim.Fee)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+	from := n.account(claim.From).balance
+	if from.Cmp(fee) < 0 {
+		return nil, errors.Errorf("insufficient balance %s to pay fee %s", from, fee)
+	}
+	err = n.useNonce(claim.From, claim.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// The fee is held until the claim is settled or expires
+	from.Sub(from, fee)
+	id := messageId(claim.From, claim.Nonce, claim.Claim)
+	submitted := &submittedClaim{
+		message: vsl.TimestampedSubmittedClaim{
+			Id: id,
+			Data: vsl.SubmittedClaim{
+				SubmitClaimParams: claim.SubmitClaimParams,
+				Signature:         signature(claim.SignedComponents),
+			},
+			Timestamp: n.nextTimestamp(),
+		},
+	}
+	n.submitted = append(n.submitted, submitted)
+	n.claims[id] = submitted
+	return id, nil
+}
+
+func (n *Node) settleClaim(settle vsl.SignedSettleClaimParams) (any, error) {
+	err := checkSignature(settle.SettleClaimParams, settle.SignedComponents, settle.From)
+	if err != nil {
+		return nil, err
+	}
+	claim, ok := n.claims[settle.TargetClaimId]
+	if !ok {
+		return nil, errors.Errorf("claim %s not found", settle.TargetClaimId)
+	}
+	switch {
+	case claim.settled:
+		return nil, errors.Errorf("claim %s already settled", settle.TargetClaimId)
+	case claim.expired:
+		return nil, errors.Errorf("claim %s expired", settle.TargetClaimId)
+	case !containsAddress(claim.message.Data.To, settle.From):
+		return nil, errors.Errorf("%s is not a receiver of claim %s", settle.From, settle.TargetClaimId)
+	case containsAddress(claim.verifiers, settle.From):
+		return nil, errors.Errorf("%s already settled claim %s", settle.From, settle.TargetClaimId)
+	}
+	err = n.useNonce(settle.From, settle.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	claim.verifiers = append(claim.verifiers, settle.From)
+	if len(claim.verifiers) < int(claim.message.Data.Quorum) {
+		return settle.TargetClaimId, nil
+	}
+
+	// Quorum reached, pay the fee to the verifiers and record the settled claim
+	claim.settled = true
+	fee, _ := vsl.ParseAmount(claim.message.Data.Fee)
+	share, remainder := new(big.Int).QuoRem(fee, big.NewInt(int64(len(claim.verifiers))), new(big.Int))
+	for i, verifier := range claim.verifiers {
+		balance := n.account(verifier).balance
+		balance.Add(balance, share)
+		if i == 0 {
+			balance.Add(balance, remainder)
+		}
+	}
+
+	message := settledClaimMessage{
+		VerifiedClaim: vsl.VerifiedClaim{
+			Claim:      claim.message.Data.Claim,
+			ClaimId:    claim.message.Id,
+			ClaimType:  claim.message.Data.ClaimType,
+			ClaimOwner: claim.message.Data.From,
+		},
+		Verifiers: slices.Clone(claim.verifiers),
+	}
+	signed, err := evm.SignMessageWithSigner(context.Background(), n.signer, message)
+	if err != nil {
+		return nil, err
+	}
+	n.settled = append(n.settled, &vsl.TimestampedSettledClaim{
+		Id: claim.message.Id,
+		Data: vsl.SettledClaim{
+			VerifiedClaim: message.VerifiedClaim,
+			Verifiers:     message.Verifiers,
+			Signature:     signature(*signed),
+		},
+		Timestamp: n.nextTimestamp(),
+	})
+	return settle.TargetClaimId, nil
+}
+
+func (n *Node) listSubmitted(since abstract_types.Timestamp, include func(*submittedClaim) bool) []vsl.TimestampedSubmittedClaim {
+	claims := []vsl.TimestampedSubmittedClaim{}
+	for _, claim := range n.submitted {
+		if after(claim.message.Timestamp, since) && include(claim) {
+			claims = append(claims, claim.message)
+		}
+	}
+	return claims
+}
+
+func (n *Node) listSettled(since abstract_types.Timestamp, include func(*vsl.TimestampedSettledClaim) bool) []vsl.TimestampedSettledClaim {
+	claims := []vsl.TimestampedSettledClaim{}
+	for _, claim := range n.settled {
+		if after(claim.Timestamp, since) && include(claim) {
+			claims = append(claims, *claim)
+		}
+	}
+	return claims
+}
+
+// checkSignature checks that signed is the signature of message by from
+func checkSignature(message any, signed evm.SignedComponents, from string) error {
+	if !common.IsHexAddress(from) {
+		return invalidParams(errors.Errorf("invalid address %q", from))
+	}
+	return evm.VerifySignedComponents(message, signed, common.HexToAddress(from))
+}
+
+// messageId derives the id of a message like the node does, from its owner, nonce and content
+func messageId(owner string, nonce string, content string) string {
+	return crypto.Keccak256Hash([]byte(owner + nonce + content)).Hex()
+}
+
+func signature(signed evm.SignedComponents) vsl.Signature {
+	return vsl.Signature{
+		Hash: signed.Hash,
+		R:    signed.R,
+		S:    signed.S,
+		V:    vsl.SignatureV(signed.V),
+	}
+}
+
+func containsAddress(addresses []string, address string) bool {
+	return slices.ContainsFunc(addresses, func(a string) bool {
+		return strings.EqualFold(a, address)
+	})
+}
+
+// after reports whether a is strictly after b
+func after(a abstract_types.Timestamp, b abstract_types.Timestamp) bool {
+	if a.Seconds != b.Seconds {
+		return a.Seconds > b.Seconds
+	}
+	return a.Nanos > b.Nanos
+}
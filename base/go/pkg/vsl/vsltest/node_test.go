@@ -0,0 +1,232 @@
+package vsltest
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+	"base/pkg/vsl"
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestSigner(t *testing.T) *evm.PrivateKeySigner {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return evm.NewPrivateKeySigner(privateKey)
+}
+
+func submitTestClaim(ctx context.Context, client *vsl.VSLRPCClient, nonce uint64, to []string, quorum uint16, expires time.Time) (*string, error) {
+	return client.SubmitClaim(ctx, vsl.SubmitClaimParams{
+		Claim:     fmt.Sprintf("claim %d", nonce),
+		ClaimType: "Test",
+		Proof:     "proof",
+		Nonce:     fmt.Sprintf("%d", nonce),
+		To:        to,
+		Quorum:    quorum,
+		From:      client.Signer().Address().Hex(),
+		Expires:   abstract_types.Timestamp{Seconds: uint64(expires.Unix())},
+		Fee:       "100",
+	})
+}
+
+func settleTestClaim(ctx context.Context, client *vsl.VSLRPCClient, nonce uint64, claimId string) (*string, error) {
+	return client.SettleClaim(ctx, vsl.SettleClaimParams{
+		From:          client.Signer().Address().Hex(),
+		Nonce:         fmt.Sprintf("%d", nonce),
+		TargetClaimId: claimId,
+	})
+}
+
+func TestGenesis(t *testing.T) {
+	genesis, err := vsl.LoadGenesis("../../../../../genesis.json")
+	if err != nil {
+		t.Fatalf("Failed to load genesis: %v", err)
+	}
+	node := NewNode(t, genesis)
+	client := node.Client(nil)
+
+	health, err := client.GetHealth(context.Background())
+	if err != nil || *health != "ok" {
+		t.Fatalf("Unexpected health %v: %v", health, err)
+	}
+	balance, err := client.GetBalance(context.Background(), vsl.GetBalanceParams{
+		AccountId: genesis.Accounts[0].Id,
+	})
+	if err != nil {
+		t.Fatalf("Failed to get balance: %v", err)
+	}
+	if balance.String() != genesis.Accounts[0].Balance {
+		t.Fatalf("Balance %s does not match genesis %s", balance, genesis.Accounts[0].Balance)
+	}
+}
+
+func TestSubmitAndSettleClaim(t *testing.T) {
+	ctx := context.Background()
+	node := NewNode(t, nil)
+	submitter := node.Client(newTestSigner(t))
+	verifierA := node.Client(newTestSigner(t))
+	verifierB := node.Client(newTestSigner(t))
+	submitterAddress := submitter.Signer().Address().Hex()
+	verifierAddresses := []string{verifierA.Signer().Address().Hex(), verifierB.Signer().Address().Hex()}
+	node.Fund(submitterAddress, big.NewInt(1000))
+
+	claimId, err := submitTestClaim(ctx, submitter, 0, verifierAddresses, 2, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to submit claim: %v", err)
+	}
+	if node.Balance(submitterAddress).Int64() != 900 {
+		t.Fatalf("Expected the fee to be held, balance is %s", node.Balance(submitterAddress))
+	}
+
+	// Both verifiers see the claim
+	for _, verifier := range []*vsl.VSLRPCClient{verifierA, verifierB} {
+		claims, err := verifier.ListSubmittedClaimsForReceiver(ctx, vsl.ListSubmittedClaimsForReceiverParams{
+			Address: verifier.Signer().Address().Hex(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to list submitted claims: %v", err)
+		}
+		if len(claims) != 1 || claims[0].Id != *claimId || claims[0].Data.Proof != "proof" {
+			t.Fatalf("Unexpected submitted claims %+v", claims)
+		}
+	}
+
+	// The claim is settled once the quorum is reached
+	_, err = settleTestClaim(ctx, verifierA, 0, *claimId)
+	if err != nil {
+		t.Fatalf("Failed to settle claim: %v", err)
+	}
+	_, err = verifierA.GetSettledClaimById(ctx, vsl.GetSettledClaimByIdParams{ClaimId: *claimId})
+	if err == nil {
+		t.Fatalf("Expected the claim not to be settled before the quorum")
+	}
+	_, err = settleTestClaim(ctx, verifierB, 0, *claimId)
+	if err != nil {
+		t.Fatalf("Failed to settle claim: %v", err)
+	}
+
+	settled, err := submitter.ListSettledClaimsForReceiver(ctx, vsl.ListSettledClaimsForReceiverParams{
+		Address: submitterAddress,
+	})
+	if err != nil {
+		t.Fatalf("Failed to list settled claims: %v", err)
+	}
+	if len(settled) != 1 || settled[0].Data.VerifiedClaim.ClaimId != *claimId || len(settled[0].Data.Verifiers) != 2 {
+		t.Fatalf("Unexpected settled claims %+v", settled)
+	}
+	if node.Balance(verifierAddresses[0]).Int64() != 50 || node.Balance(verifierAddresses[1]).Int64() != 50 {
+		t.Fatalf("Expected the fee to be paid to the verifiers")
+	}
+	pending, err := verifierA.ListSubmittedClaimsForReceiver(ctx, vsl.ListSubmittedClaimsForReceiverParams{
+		Address: verifierAddresses[0],
+	})
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("Expected no pending claims, got %+v: %v", pending, err)
+	}
+}
+
+func TestRejectedMessages(t *testing.T) {
+	ctx := context.Background()
+	node := NewNode(t, nil)
+	submitter := node.Client(newTestSigner(t))
+	verifier := node.Client(newTestSigner(t))
+	node.Fund(submitter.Signer().Address().Hex(), big.NewInt(1000))
+	to := []string{verifier.Signer().Address().Hex()}
+	expires := time.Now().Add(time.Minute)
+
+	_, err := submitTestClaim(ctx, submitter, 1, to, 1, expires)
+	if !vsl.IsNonceError(err) {
+		t.Fatalf("Expected a nonce error, got %v", err)
+	}
+	_, err = submitTestClaim(ctx, submitter, 0, to, 2, expires)
+	if err == nil {
+		t.Fatalf("Expected a quorum larger than the receivers to be rejected")
+	}
+
+	// Claim signed by another key than its sender
+	_, err = submitter.SubmitClaim(ctx, vsl.SubmitClaimParams{
+		Claim:     "claim",
+		ClaimType: "Test",
+		Nonce:     "0",
+		To:        to,
+		Quorum:    1,
+		From:      verifier.Signer().Address().Hex(),
+		Expires:   abstract_types.Timestamp{Seconds: uint64(expires.Unix())},
+		Fee:       "1",
+	})
+	if _, ok := vsl.AsRPCError(err); !ok {
+		t.Fatalf("Expected a claim signed by another key to be rejected, got %v", err)
+	}
+
+	// Only receivers may settle a claim
+	claimId, err := submitTestClaim(ctx, submitter, 0, to, 1, expires)
+	if err != nil {
+		t.Fatalf("Failed to submit claim: %v", err)
+	}
+	_, err = settleTestClaim(ctx, submitter, 1, *claimId)
+	if err == nil {
+		t.Fatalf("Expected a settlement by a non-receiver to be rejected")
+	}
+}
+
+func TestClaimExpiry(t *testing.T) {
+	ctx := context.Background()
+	node := NewNode(t, nil)
+	submitter := node.Client(newTestSigner(t))
+	verifier := node.Client(newTestSigner(t))
+	submitterAddress := submitter.Signer().Address().Hex()
+	node.Fund(submitterAddress, big.NewInt(1000))
+
+	claimId, err := submitTestClaim(ctx, submitter, 0, []string{verifier.Signer().Address().Hex()}, 1, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to submit claim: %v", err)
+	}
+	node.Advance(2 * time.Minute)
+
+	if node.Balance(submitterAddress).Int64() != 1000 {
+		t.Fatalf("Expected the fee to be refunded, balance is %s", node.Balance(submitterAddress))
+	}
+	claims, err := verifier.ListSubmittedClaimsForReceiver(ctx, vsl.ListSubmittedClaimsForReceiverParams{
+		Address: verifier.Signer().Address().Hex(),
+	})
+	if err != nil || len(claims) != 0 {
+		t.Fatalf("Expected no claims after expiry, got %+v: %v", claims, err)
+	}
+	_, err = settleTestClaim(ctx, verifier, 0, *claimId)
+	if err == nil {
+		t.Fatalf("Expected the settlement of an expired claim to be rejected")
+	}
+}
+
+func TestAccountState(t *testing.T) {
+	ctx := context.Background()
+	node := NewNode(t, nil)
+	client := node.Client(newTestSigner(t))
+	params := vsl.CreateAccountParams{
+		OwnerAddress: client.Signer().Address().Hex(),
+		Script:       "script",
+		Label:        "label",
+	}
+	accountId, err := client.CreateAccount(ctx, params)
+	if err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+
+	state, err := client.GetAccountState(ctx, vsl.GetAccountStateParams{AccountId: *accountId})
+	if err != nil {
+		t.Fatalf("Failed to get account state: %v", err)
+	}
+	if state.CreateAccountParams != params {
+		t.Fatalf("Expected the created account %+v, got %+v", params, state)
+	}
+	_, err = client.GetAccountState(ctx, vsl.GetAccountStateParams{AccountId: "0x01"})
+	if err == nil {
+		t.Fatalf("Expected an unknown account to be rejected")
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"backend/models"
+	"base/pkg/vsl"
 	"errors"
 	"strconv"
 	"time"
@@ -214,86 +215,38 @@ func RegisterBlockMirroringAPI(app *models.App) {
 	})
 
 	app.API.Get("/block_mirroring_records/:block_number/:execution_client/claim", func(c fiber.Ctx) error {
-		// TODO: Implement this endpoint to return the claim details for a given block number and execution client.
-		return c.Status(501).SendString("not implemented yet")
-		// blockNumber := c.Params("block_number")
-		// if blockNumber == "" {
-		// 	return c.Status(400).SendString("block_number is required")
-		// }
-
-		// executionClient := c.Params("execution_client")
-		// if executionClient == "" {
-		// 	return c.Status(400).SendString("execution_client is required")
-		// }
-
-		// dbVerificationRecord := models.VerificationRecord{}
-		// err := app.DB.Where("block_number = ? AND execution_client = ? AND verification_time IS NOT NULL", blockNumber, executionClient).First(&dbVerificationRecord).Error
-		// if err != nil {
-		// 	return c.Status(404).SendString("verification record not found")
-		// }
-
-		// // TODO: use VSL RPC pkg
-		// type rpcRequest struct {
-		// 	JSONRPC string      `json:"jsonrpc"`
-		// 	Method  string      `json:"method"`
-		// 	Params  interface{} `json:"params"`
-		// 	ID      int         `json:"id"`
-		// }
-		// type rpcParams struct {
-		// 	ClaimID string `json:"claim_id"`
-		// }
-		// type rpcResponse struct {
-		// 	JSONRPC string          `json:"jsonrpc"`
-		// 	Result  json.RawMessage `json:"result"`
-		// 	Error   *struct {
-		// 		Code    int    `json:"code"`
-		// 		Message string `json:"message"`
-		// 	} `json:"error,omitempty"`
-		// 	ID int `json:"id"`
-		// }
-
-		// if dbVerificationRecord.ClaimID == "" {
-		// 	return c.Status(400).SendString("claim_id is missing in verification record")
-		// }
-
-		// reqBody := rpcRequest{
-		// 	JSONRPC: "2.0",
-		// 	Method:  "vsl_getSettledClaimById",
-		// 	Params:  rpcParams{ClaimID: dbVerificationRecord.ClaimID},
-		// 	ID:      1,
-		// }
-
-		// jsonBytes, err := json.Marshal(reqBody)
-		// if err != nil {
-		// 	return c.Status(500).SendString("failed to marshal rpc request")
-		// }
-
-		// httpResp, err := http.Post(app.VSLEndpoint, "application/json", bytes.NewReader(jsonBytes))
-		// if err != nil {
-		// 	return c.Status(502).SendString("failed to contact claim RPC server")
-		// }
-		// defer httpResp.Body.Close()
-
-		// respBytes, err := io.ReadAll(httpResp.Body)
-		// if err != nil {
-		// 	return c.Status(500).SendString("failed to read rpc response")
-		// }
-
-		// var rpcResp rpcResponse
-		// if err := json.Unmarshal(respBytes, &rpcResp); err != nil {
-		// 	return c.Status(500).SendString("failed to parse rpc response")
-		// }
-		// if rpcResp.Error != nil {
-		// 	return c.Status(502).SendString("rpc error: " + rpcResp.Error.Message)
-		// }
-
-		// // Return the claim as-is (raw JSON)
-		// var claimWithBlock map[string]interface{}
-		// if err := json.Unmarshal(rpcResp.Result, &claimWithBlock); err != nil {
-		// 	return c.Status(500).SendString("failed to parse claim result")
-		// }
-
-		// return c.JSON(claimWithBlock)
+		blockNumber := c.Params("block_number")
+		if blockNumber == "" {
+			return c.Status(400).SendString("block_number is required")
+		}
+
+		executionClient := c.Params("execution_client")
+		if executionClient == "" {
+			return c.Status(400).SendString("execution_client is required")
+		}
+
+		record := models.BlockMirroringRecord{}
+		err := app.DB.Where("block_number = ? AND execution_client = ? AND verification_time IS NOT NULL", blockNumber, executionClient).First(&record).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(404).SendString("verified record not found")
+			}
+			return c.Status(500).SendString("internal server error")
+		}
+		if record.ClaimID == "" {
+			return c.Status(400).SendString("claim_id is missing in record")
+		}
+
+		claim, err := app.VSLRPCClient.GetSettledClaimById(c.Context(), vsl.GetSettledClaimByIdParams{
+			ClaimId: record.ClaimID,
+		})
+		if err != nil {
+			if rpcErr, ok := vsl.AsRPCError(err); ok {
+				return c.Status(502).SendString("rpc error: " + rpcErr.Message)
+			}
+			return c.Status(502).SendString("failed to get settled claim from VSL")
+		}
+		return c.JSON(claim)
 	})
 
 	app.API.Get("/block_mirroring_records/:block_number/:execution_client/verification_time", func(c fiber.Ctx) error {
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"base/pkg/vsl"
 	"os"
 
 	"github.com/gofiber/fiber/v3"
@@ -10,8 +11,9 @@ import (
 )
 
 type App struct {
-	API *fiber.App
-	DB  *gorm.DB
+	API          *fiber.App
+	DB           *gorm.DB
+	VSLRPCClient *vsl.VSLRPCClient
 }
 
 func NewApp() *App {
@@ -34,8 +36,12 @@ func NewApp() *App {
 		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization"},
 	}))
 
+	// The backend only reads from VSL, so the client doesn't need a signer
+	vslRPCClient := vsl.NewVSLRPCClient(os.Getenv("VSL_RPC"), nil)
+
 	return &App{
-		API: apiServer,
-		DB:  db,
+		API:          apiServer,
+		DB:           db,
+		VSLRPCClient: vslRPCClient,
 	}
 }
@@ -1,27 +1,33 @@
 package models
 
 import (
+	"context"
 	"log"
 	"os"
+	"sync"
 
+	"base/pkg/ethrpc"
+	"base/pkg/evm"
 	"base/pkg/vsl"
 
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
 )
 
 type App struct {
-	BackendEndpoint        string
-	VSLRPC                 string
-	VSLSubmitterAddress    string
-	VSLSubmitterPrivateKey string
-	VSLVerifierAddress     string
-	VSLVerifierPrivateKey  string
-	EthRPCClient           *ethclient.Client
-	EthWSClient            *ethclient.Client
-	VSLClient              *vsl.VSLRPCClient
+	BackendEndpoint     string
+	VSLRPC              string
+	VSLSubmitterAddress string
+	VSLSubmitterSigner  evm.Signer
+	VSLSubmissionPolicy *vsl.SubmissionPolicy
+	VSLClaimTracker     *vsl.ClaimTracker
+	VSLClaimBlocks      sync.Map // claim id to the number of the block it claims, for the tracked claims
+	VSLFunder           *vsl.Funder
+	SourceRPC           *ethrpc.MultiClient
+	SourceCache         *ethrpc.Cache
+	EthWSClient         *ethrpc.WSClient
+	VSLClient           *vsl.VSLRPCClient
+	VSLNonceManager     *vsl.NonceManager
 }
 
 func NewApp() (*App, error) {
@@ -33,34 +39,68 @@ func NewApp() (*App, error) {
 	backendEndpoint := os.Getenv("BACKEND_ENDPOINT")
 	vslRPC := os.Getenv("VSL_RPC")
 	vslSubmitterAddress := os.Getenv("VSL_SUBMITTER_ADDRESS")
-	vslSubmitterPrivateKey := os.Getenv("VSL_SUBMITTER_PRIVATE_KEY")
-	vslVerifierAddress := os.Getenv("VSL_VERIFIER_ADDRESS")
-	vslVerifierPrivateKey := os.Getenv("VSL_VERIFIER_PRIVATE_KEY")
-	rpcEndpoint := os.Getenv("SOURCE_RPC_ENDPOINT")
-	wsEndpoint := os.Getenv("SOURCE_WEBSOCKET_ENDPOINT")
 
-	rpcClient, err := rpc.Dial(rpcEndpoint)
+	// The source chain endpoints are loaded from SOURCE_RPC_ENDPOINTS or SOURCE_RPC_ENDPOINT, with SOURCE_RPC_QUORUM
+	sourceRPCConfig, err := ethrpc.MultiClientConfigFromEnv("SOURCE")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sourceRPC, err := ethrpc.DialMultiClient(context.Background(), sourceRPCConfig)
 	if err != nil {
 		log.Fatalf("Failed to create RPC client: %+v", err)
 	}
 
-	ethRPCClient := ethclient.NewClient(rpcClient)
-	ethWSClient, err := ethclient.Dial(wsEndpoint)
+	// The execution witnesses fetched for the claims are cached in memory, up to SOURCE_RPC_CACHE_MB, and in SOURCE_RPC_CACHE_DIR if set
+	sourceCacheConfig, err := ethrpc.CacheConfigFromEnv("SOURCE")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sourceCache, err := ethrpc.NewCache(sourceCacheConfig)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The new heads are subscribed over SOURCE_WEBSOCKET_ENDPOINTS or SOURCE_WEBSOCKET_ENDPOINT, failing over between them
+	ethWSClient, err := ethrpc.DialWSClient(context.Background(), ethrpc.WSEndpointsFromEnv("SOURCE"))
 	if err != nil {
 		log.Fatalf("Failed to create WS client: %+v", err)
 	}
 
-	vslClient := vsl.NewVSLRPCClient(vslRPC, vslSubmitterPrivateKey)
+	// The submitter key is loaded from VSL_SUBMITTER_PRIVATE_KEY, VSL_SUBMITTER_KEYSTORE or VSL_SUBMITTER_REMOTE_SIGNER_URL
+	vslSubmitterSigner, err := evm.NewSigner(context.Background(), evm.SignerConfigFromEnv("VSL_SUBMITTER"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if vslSubmitterAddress == "" {
+		vslSubmitterAddress = vslSubmitterSigner.Address().Hex()
+	}
+
+	// The claim fee, expiry and verifiers are loaded from VSL_SUBMISSION_POLICY, or default to VSL_VERIFIER_ADDRESS
+	vslSubmissionPolicy, err := vsl.SubmissionPolicyFromEnv()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Optional balance monitoring and top-up of the VSL accounts, configured by VSL_FUNDING_CONFIG
+	vslFunder, err := vsl.FunderFromEnv(context.Background(), vslRPC)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	vslClient := vsl.NewVSLRPCClient(vslRPC, vslSubmitterSigner)
 
 	return &App{
-		BackendEndpoint:        backendEndpoint,
-		VSLRPC:                 vslRPC,
-		VSLClient:              vslClient,
-		VSLSubmitterAddress:    vslSubmitterAddress,
-		VSLSubmitterPrivateKey: vslSubmitterPrivateKey,
-		VSLVerifierAddress:     vslVerifierAddress,
-		VSLVerifierPrivateKey:  vslVerifierPrivateKey,
-		EthRPCClient:           ethRPCClient,
-		EthWSClient:            ethWSClient,
+		BackendEndpoint:     backendEndpoint,
+		VSLRPC:              vslRPC,
+		VSLClient:           vslClient,
+		VSLNonceManager:     vsl.NewNonceManager(vslClient),
+		VSLSubmitterAddress: vslSubmitterAddress,
+		VSLSubmitterSigner:  vslSubmitterSigner,
+		VSLSubmissionPolicy: vslSubmissionPolicy,
+		VSLClaimTracker:     vsl.NewClaimTracker(vslClient, vslSubmitterAddress, vsl.DefaultTrackerInterval),
+		VSLFunder:           vslFunder,
+		SourceRPC:           sourceRPC,
+		SourceCache:         sourceCache,
+		EthWSClient:         ethWSClient,
 	}, nil
 }
@@ -1,36 +1,56 @@
 package utils
 
 import (
+	"base/pkg/ethrpc"
 	"context"
 	"encoding/json"
 	"fmt"
 	"generation-block-processing-evm/pkg/generation"
+	generationModels "generation-block-processing-evm/pkg/models"
 	"log"
+	"math/big"
 	"mirroring-geth-claim-submitter/models"
+	"sort"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
 )
 
+// maxBlockAttempts is the number of heads on which a block the source RPC endpoints did not reach or
+// agree on is tried, before reporting it to the backend
+const maxBlockAttempts = 8
+
 func ObserveBlocks(app *models.App) error {
 	ctx := context.Background()
 
 	// Get chain ID
-	chainIdBig, err := app.EthRPCClient.ChainID(ctx)
+	chainIdBig, err := app.SourceRPC.ChainID(ctx)
 	if err != nil {
 		log.Fatalf("Failed to get chain ID: %+v", err)
 	}
 	chainId := hexutil.EncodeBig(chainIdBig)
 	log.Printf("Chain ID: %s\n", chainId)
 
-	// Subscribe to new logs
-	headerChannel := make(chan *types.Header)
-	headerSubscribe, err := app.EthWSClient.SubscribeNewHead(ctx, headerChannel)
-	if err != nil {
-		log.Printf("Error subscribing to new headers: %+v", err)
-		return err
+	// Fail over between the source RPC endpoints as their health changes
+	go app.SourceRPC.Run(ctx)
+
+	// Follow the submitted claims until they are settled or expire
+	go app.VSLClaimTracker.Run(ctx)
+	go TrackClaims(app)
+	if app.VSLFunder != nil {
+		go app.VSLFunder.Run(ctx)
 	}
 
+	// Subscribe to new heads, resubscribing over the next websocket endpoint when one fails
+	headerChannel := make(chan *types.Header)
+	headerSubscribe := app.EthWSClient.SubscribeNewHead(headerChannel)
+	defer headerSubscribe.Unsubscribe()
+
+	// Blocks the source RPC endpoints did not reach or agree on yet, with their number of attempts
+	pending := map[uint64]int{}
 	for {
 		select {
 		case err := <-headerSubscribe.Err():
@@ -39,59 +59,111 @@ func ObserveBlocks(app *models.App) error {
 		case header := <-headerChannel:
 			log.Printf("New header detected: %s", header.Number.String())
 
-			// Generate block processing claim
-			claim, verCtx, err := generation.Generate(app.EthRPCClient, header.Number)
-			if err != nil {
-				errString := fmt.Sprintf("Error generating block claim: %+v", err)
-				log.Print(errString)
-				err = SubmitClaimToBackend(app, header.Number.Uint64(), nil, &errString)
-				if err != nil {
-					log.Printf("Error submitting claim to backend: %+v", err)
-				}
-				continue
+			// Retry the pending blocks first, in order
+			numbers := []uint64{}
+			for number := range pending {
+				numbers = append(numbers, number)
 			}
-
-			// Marshall claim and verification context for validation
-			_, err = json.Marshal(claim)
-			if err != nil {
-				errString := fmt.Sprintf("Error marshalling claim: %+v", err)
-				log.Print(errString)
-				err = SubmitClaimToBackend(app, header.Number.Uint64(), nil, &errString)
-				if err != nil {
-					log.Printf("Error submitting claim to backend: %+v", err)
-				}
-				continue
+			sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+			if _, ok := pending[header.Number.Uint64()]; !ok {
+				numbers = append(numbers, header.Number.Uint64())
 			}
 
-			_, err = json.Marshal(verCtx)
-			if err != nil {
-				errString := fmt.Sprintf("Error marshalling verification context: %+v", err)
+			for _, number := range numbers {
+				err := claimBlock(ctx, app, new(big.Int).SetUint64(number))
+				if err == nil {
+					delete(pending, number)
+					continue
+				}
+				pending[number]++
+				if pending[number] < maxBlockAttempts {
+					log.Printf("Block %d not claimable yet, retrying on the next head: %v", number, err)
+					continue
+				}
+				delete(pending, number)
+				errString := fmt.Sprintf("Error generating block claim: %+v", err)
 				log.Print(errString)
-				err = SubmitClaimToBackend(app, header.Number.Uint64(), nil, &errString)
+				err = SubmitClaimToBackend(app, number, nil, &errString)
 				if err != nil {
 					log.Printf("Error submitting claim to backend: %+v", err)
 				}
-				continue
 			}
+		}
+	}
+}
 
-			claimId, err := SubmitClaimToVSL(app, header.Number.Uint64(), claim, verCtx, nil)
-			if err != nil {
-				errString := fmt.Sprintf("Error submitting claim to VSL: %+v", err)
-				err = SubmitClaimToBackend(app, header.Number.Uint64(), nil, &errString)
-				if err != nil {
-					log.Printf("Error submitting claim to backend: %+v", err)
-				}
-				continue
-			}
-			log.Printf("Successfully submitted claim for block %s to VSL with ID %s", header.Number.String(), *claimId)
-
-			// Submit block processing claim to remote RPC (for verifier to fetch)
-			err = SubmitClaimToBackend(app, header.Number.Uint64(), claimId, nil)
-			if err != nil {
-				log.Printf("Error submitting claim to backend: %+v", err)
-			} else {
-				log.Printf("Successfully submitted claim for block %s to backend", header.Number.String())
-			}
+// claimBlock generates the claim of a block and submits it to VSL and the backend. It returns the
+// errors of the blocks the source RPC endpoints did not reach or agree on yet, to be retried, and
+// reports the other errors to the backend.
+func claimBlock(ctx context.Context, app *models.App, number *big.Int) error {
+	// Generate block processing claim
+	claim, verCtx, err := generation.GenerateWithCache(app.SourceRPC.EthClient(), number, app.SourceCache)
+	if err == nil {
+		err = verifyCanonical(ctx, app, claim)
+	}
+	if errors.Is(err, ethrpc.ErrNoQuorum) || errors.Is(err, ethereum.NotFound) {
+		return err
+	}
+	if err != nil {
+		errString := fmt.Sprintf("Error generating block claim: %+v", err)
+		log.Print(errString)
+		err = SubmitClaimToBackend(app, number.Uint64(), nil, &errString)
+		if err != nil {
+			log.Printf("Error submitting claim to backend: %+v", err)
 		}
+		return nil
+	}
+
+	// Marshall claim and verification context for validation
+	_, err = json.Marshal(claim)
+	if err != nil {
+		errString := fmt.Sprintf("Error marshalling claim: %+v", err)
+		log.Print(errString)
+		err = SubmitClaimToBackend(app, number.Uint64(), nil, &errString)
+		if err != nil {
+			log.Printf("Error submitting claim to backend: %+v", err)
+		}
+		return nil
+	}
+
+	_, err = json.Marshal(verCtx)
+	if err != nil {
+		errString := fmt.Sprintf("Error marshalling verification context: %+v", err)
+		log.Print(errString)
+		err = SubmitClaimToBackend(app, number.Uint64(), nil, &errString)
+		if err != nil {
+			log.Printf("Error submitting claim to backend: %+v", err)
+		}
+		return nil
+	}
+
+	claimId, err := SubmitClaimToVSL(ctx, app, number.Uint64(), claim, verCtx, nil)
+	if err != nil {
+		errString := fmt.Sprintf("Error submitting claim to VSL: %+v", err)
+		err = SubmitClaimToBackend(app, number.Uint64(), nil, &errString)
+		if err != nil {
+			log.Printf("Error submitting claim to backend: %+v", err)
+		}
+		return nil
+	}
+	log.Printf("Successfully submitted claim for block %s to VSL with ID %s", number.String(), *claimId)
+
+	// Submit block processing claim to remote RPC (for verifier to fetch)
+	err = SubmitClaimToBackend(app, number.Uint64(), claimId, nil)
+	if err != nil {
+		log.Printf("Error submitting claim to backend: %+v", err)
+	} else {
+		log.Printf("Successfully submitted claim for block %s to backend", number.String())
+	}
+	return nil
+}
+
+// verifyCanonical checks that the quorum of source RPC endpoints agree that the claimed block is canonical
+func verifyCanonical(ctx context.Context, app *models.App, claim *generationModels.EVMBlockProcessingClaim) error {
+	var block types.Block
+	err := rlp.DecodeBytes(claim.Result, &block)
+	if err != nil {
+		return errors.WithStack(err)
 	}
+	return app.SourceRPC.VerifyBlockHash(ctx, block.Number(), block.Hash())
 }
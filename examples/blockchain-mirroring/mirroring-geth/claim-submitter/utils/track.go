@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"mirroring-geth-claim-submitter/models"
+
+	"base/pkg/vsl"
+)
+
+// TrackClaims reports the lifecycle events of the submitted claims, and records the claims that
+// expired before being settled as failed in the backend
+func TrackClaims(app *models.App) {
+	for event := range app.VSLClaimTracker.Events() {
+		switch event.Type {
+		case vsl.ClaimQuorumReached:
+			blockNumber, _ := app.VSLClaimBlocks.Load(event.ClaimId)
+			log.Printf("Claim %s of block %v reached its quorum with %d verifiers", event.ClaimId, blockNumber, len(event.SettledClaim.Data.Verifiers))
+		case vsl.ClaimSettled:
+			blockNumber, _ := app.VSLClaimBlocks.LoadAndDelete(event.ClaimId)
+			log.Printf("Claim %s of block %v settled at %d", event.ClaimId, blockNumber, event.Timestamp.Seconds)
+		case vsl.ClaimExpired:
+			blockNumber, ok := app.VSLClaimBlocks.LoadAndDelete(event.ClaimId)
+			log.Printf("Claim %s of block %v expired before being settled", event.ClaimId, blockNumber)
+			if !ok {
+				continue
+			}
+			errString := fmt.Sprintf("Claim expired at %d before being settled", event.Timestamp.Seconds)
+			err := SubmitClaimToBackend(app, blockNumber.(uint64), &event.ClaimId, &errString)
+			if err != nil {
+				log.Printf("Error submitting claim to backend: %+v", err)
+			}
+		}
+	}
+}
@@ -1,24 +1,21 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	generationModels "generation-block-processing-evm/pkg/models"
 	"log"
-	"math/big"
 	"mirroring-geth-claim-submitter/models"
-	"time"
 
-	"base/pkg/abstract_types"
 	"base/pkg/vsl"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gofiber/fiber"
 	"github.com/gofiber/fiber/v3/client"
 )
 
-func SubmitClaimToVSL(app *models.App, blockNumber uint64, claim *generationModels.EVMBlockProcessingClaim, verificationContext *generationModels.EVMBlockProcessingClaimVerificationContext, errString *string) (*string, error) {
+func SubmitClaimToVSL(ctx context.Context, app *models.App, blockNumber uint64, claim *generationModels.EVMBlockProcessingClaim, verificationContext *generationModels.EVMBlockProcessingClaimVerificationContext, errString *string) (*string, error) {
 	claimJSON, err := json.Marshal(claim)
 	if err != nil {
 		errString := fmt.Sprintf("Error marshalling claim: %+v", err)
@@ -32,33 +29,39 @@ func SubmitClaimToVSL(app *models.App, blockNumber uint64, claim *generationMode
 		return nil, errors.New(errString)
 	}
 
-	nonce, err := app.VSLClient.GetAccountNonce(vsl.GetAccountNonceParams{
-		AccountId: app.VSLSubmitterAddress,
-	})
+	terms, err := app.VSLSubmissionPolicy.Terms("MirroringGeth", len(verificationContextJSON))
 	if err != nil {
-		errString := fmt.Sprintf("Error getting account nonce: %+v", err)
+		errString := fmt.Sprintf("Error submitting claim to VSL: %+v", err)
 		log.Println(errString)
 		return nil, errors.New(errString)
 	}
-	claimId, err := app.VSLClient.SubmitClaim(vsl.SubmitClaimParams{
-		Claim:     string(claimJSON),
-		ClaimType: "MirroringGeth",
-		Proof:     string(verificationContextJSON),
-		Nonce:     fmt.Sprintf("%d", *nonce),
-		To:        []string{app.VSLVerifierAddress},
-		Quorum:    1,
-		From:      app.VSLSubmitterAddress,
-		Expires: abstract_types.Timestamp{
-			Seconds: uint64(time.Now().Unix() + 600), // 10 minutes,
-			Nanos:   0,
-		},
-		Fee: hexutil.EncodeBig(big.NewInt(1)),
+
+	var claimId *string
+	err = app.VSLNonceManager.Do(ctx, app.VSLSubmitterAddress, func(nonce uint64) error {
+		claimId, err = app.VSLClient.SubmitClaim(ctx, vsl.SubmitClaimParams{
+			Claim:     string(claimJSON),
+			ClaimType: "MirroringGeth",
+			Proof:     string(verificationContextJSON),
+			Nonce:     fmt.Sprintf("%d", nonce),
+			To:        terms.To,
+			Quorum:    terms.Quorum,
+			From:      app.VSLSubmitterAddress,
+			Expires:   terms.Expires,
+			Fee:       terms.Fee,
+		})
+		return err
 	})
 	if err != nil {
+		// A consumed nonce means the claim was probably applied and its fee charged
+		if !errors.Is(err, vsl.ErrNonceConsumed) {
+			app.VSLSubmissionPolicy.Release(terms)
+		}
 		errString := fmt.Sprintf("Error submitting claim to VSL: %+v", err)
 		log.Println(errString)
 		return nil, errors.New(errString)
 	}
+	app.VSLClaimTracker.Track(*claimId, terms.Quorum, terms.Expires)
+	app.VSLClaimBlocks.Store(*claimId, blockNumber)
 
 	return claimId, nil
 }
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"context"
+	generationModels "generation-block-processing-evm/pkg/models"
+	"math/big"
+	"mirroring-geth-claim-submitter/models"
+	"testing"
+
+	"base/pkg/evm"
+	"base/pkg/vsl"
+	"base/pkg/vsl/vsltest"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSubmitClaimToVSL(t *testing.T) {
+	ctx := context.Background()
+	node := vsltest.NewNode(t, nil)
+
+	submitterKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	verifierKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	submitterSigner := evm.NewPrivateKeySigner(submitterKey)
+	verifierAddress := crypto.PubkeyToAddress(verifierKey.PublicKey).Hex()
+	node.Fund(submitterSigner.Address().Hex(), big.NewInt(10))
+
+	vslClient := node.Client(submitterSigner)
+	app := &models.App{
+		VSLRPC:              node.URL,
+		VSLSubmitterAddress: submitterSigner.Address().Hex(),
+		VSLSubmitterSigner:  submitterSigner,
+		VSLSubmissionPolicy: vsl.DefaultSubmissionPolicy(verifierAddress),
+		VSLClient:           vslClient,
+		VSLNonceManager:     vsl.NewNonceManager(vslClient),
+		VSLClaimTracker:     vsl.NewClaimTracker(vslClient, submitterSigner.Address().Hex(), vsl.DefaultTrackerInterval),
+	}
+
+	// Consecutive submissions use consecutive nonces
+	for blockNumber := uint64(1); blockNumber <= 2; blockNumber++ {
+		_, err = SubmitClaimToVSL(ctx, app, blockNumber, &generationModels.EVMBlockProcessingClaim{}, &generationModels.EVMBlockProcessingClaimVerificationContext{}, nil)
+		if err != nil {
+			t.Fatalf("Failed to submit claim of block %d: %v", blockNumber, err)
+		}
+	}
+
+	claims, err := node.Client(nil).ListSubmittedClaimsForReceiver(ctx, vsl.ListSubmittedClaimsForReceiverParams{
+		Address: verifierAddress,
+	})
+	if err != nil {
+		t.Fatalf("Failed to list submitted claims: %v", err)
+	}
+	if len(claims) != 2 || claims[0].Data.ClaimType != ExecutionClient {
+		t.Fatalf("Unexpected submitted claims %+v", claims)
+	}
+	if len(app.VSLClaimTracker.Pending()) != 2 {
+		t.Fatalf("Expected the submitted claims to be tracked")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"base/pkg/abstract_types"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -16,8 +17,28 @@ import (
 	"base/pkg/vsl"
 
 	generationModels "generation-block-processing-evm/pkg/models"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// diagnose re-runs a claim which failed verification with tracing, comparing its receipts with the
+// receipts of the source node when available
+func diagnose(ctx context.Context, app *models.App, claim *generationModels.EVMBlockProcessingClaim, proof *generationModels.EVMBlockProcessingClaimVerificationContext, verificationErr error) string {
+	var expectedReceipts []*types.Receipt
+	var block *types.Block
+	if app.SourceRPCClient != nil && rlp.DecodeBytes(claim.Result, &block) == nil {
+		receipts, err := app.SourceRPCClient.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+		if err != nil {
+			log.Printf("Error getting receipts for diagnostics: %v", err)
+		} else {
+			expectedReceipts = receipts
+		}
+	}
+	return verification.Diagnose(claim, proof, expectedReceipts, verificationErr).String()
+}
+
 func main() {
 	err := godotenv.Load()
 	if err != nil && !os.IsNotExist(err) { // Ignore if .env file does not exist
@@ -25,6 +46,7 @@ func main() {
 	}
 
 	app := models.NewApp()
+	ctx := context.Background()
 
 	fmt.Println("Start verifier for VSL(", app.VSLRPC, ") with verifier address: ", app.VerifierAddress)
 
@@ -36,7 +58,7 @@ func main() {
 	for {
 		fmt.Println("Since: seconds: ", since.Seconds, "nanos: ", since.Nanos)
 
-		claims, err := app.VSLRPCClient.ListSubmittedClaimsForReceiver(vsl.ListSubmittedClaimsForReceiverParams{
+		claims, err := app.VSLRPCClient.ListSubmittedClaimsForReceiver(ctx, vsl.ListSubmittedClaimsForReceiverParams{
 			Since:   since,
 			Address: app.VerifierAddress,
 		})
@@ -46,14 +68,14 @@ func main() {
 		}
 
 		for _, claim := range claims {
-			claimId := claim.Get("id").String()
-			claimInformations := claim.Get("data")
+			claimId := claim.Id
+			claimInformations := claim.Data
 
-			claimTimestampSeconds := claim.Get("timestamp").Get("seconds").Uint()
-			claimTimestampNanos := claim.Get("timestamp").Get("nanos").Uint()
+			claimTimestampSeconds := claim.Timestamp.Seconds
+			claimTimestampNanos := claim.Timestamp.Nanos
 
 			// Unmarshal claim
-			claimBytesString := claimInformations.Get("claim").String()
+			claimBytesString := claimInformations.Claim
 			var claim generationModels.EVMBlockProcessingClaim
 			err := json.Unmarshal([]byte(claimBytesString), &claim)
 			if err != nil {
@@ -68,7 +90,7 @@ func main() {
 			}
 
 			// Unmarshal proof
-			proofBytesString := claimInformations.Get("proof").String()
+			proofBytesString := claimInformations.Proof
 			var proof generationModels.EVMBlockProcessingClaimVerificationContext
 			err = json.Unmarshal([]byte(proofBytesString), &proof)
 			if err != nil {
@@ -88,6 +110,9 @@ func main() {
 			err = verification.Verify(&claim, &proof)
 			if err != nil {
 				errString := fmt.Sprintf("Error verifying claim: %v", err)
+				if app.Diagnostics {
+					errString = fmt.Sprintf("%s\nDiagnostics: %s", errString, diagnose(ctx, app, &claim, &proof, err))
+				}
 				log.Println(errString)
 				err = utils.SubmitClaimToBackend(app, &claimId, nil, &errString)
 				if err != nil {
@@ -99,7 +124,7 @@ func main() {
 			verificationTime = uint64(time.Since(verificationTimeStart).Microseconds())
 
 			// Settle claim to VSL
-			settledClaimId, err := utils.SettleClaimToVSL(app, claimId)
+			settledClaimId, err := utils.SettleClaimToVSL(ctx, app, claimId)
 			if err != nil {
 				errString := fmt.Sprintf("Error settling claim: %v", err)
 				log.Println(errString)
@@ -121,7 +146,7 @@ func main() {
 
 			if claimTimestampSeconds >= since.Seconds {
 				since.Seconds = claimTimestampSeconds
-				if uint32(claimTimestampNanos) > since.Nanos {
+				if claimTimestampNanos > since.Nanos {
 					since.Nanos = uint32(claimTimestampNanos)
 				}
 				since.Tick()
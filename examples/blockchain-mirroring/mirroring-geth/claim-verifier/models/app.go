@@ -1,16 +1,26 @@
 package models
 
 import (
+	"base/pkg/evm"
 	"base/pkg/vsl"
+	"context"
+	"log"
 	"os"
+
+	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type App struct {
-	BackendEndpoint    string
-	VerifierAddress    string
-	VerifierPrivateKey string
-	VSLRPC             string
-	VSLRPCClient       *vsl.VSLRPCClient
+	BackendEndpoint string
+	VerifierAddress string
+	VerifierSigner  evm.Signer
+	VSLRPC          string
+	VSLRPCClient    *vsl.VSLRPCClient
+	VSLNonceManager *vsl.NonceManager
+	// Diagnostics re-runs the claims failing verification with tracing, enabled by VERIFICATION_DIAGNOSTICS
+	Diagnostics bool
+	// SourceRPCClient fetches the receipts compared by the diagnostics, nil without SOURCE_RPC_ENDPOINT
+	SourceRPCClient *ethclient.Client
 }
 
 func NewApp() *App {
@@ -19,15 +29,36 @@ func NewApp() *App {
 	// VSL
 	vslRPC := os.Getenv("VSL_RPC")
 	vslVerifierAddress := os.Getenv("VSL_VERIFIER_ADDRESS")
-	vslVerifierPrivateKey := os.Getenv("VSL_VERIFIER_PRIVATE_KEY")
-	vslRPCClient := vsl.NewVSLRPCClient(vslRPC, vslVerifierPrivateKey)
+
+	// The verifier key is loaded from VSL_VERIFIER_PRIVATE_KEY, VSL_VERIFIER_KEYSTORE or VSL_VERIFIER_REMOTE_SIGNER_URL
+	vslVerifierSigner, err := evm.NewSigner(context.Background(), evm.SignerConfigFromEnv("VSL_VERIFIER"))
+	if err != nil {
+		log.Fatalf("Failed to create VSL verifier signer: %+v", err)
+	}
+	if vslVerifierAddress == "" {
+		vslVerifierAddress = vslVerifierSigner.Address().Hex()
+	}
+	vslRPCClient := vsl.NewVSLRPCClient(vslRPC, vslVerifierSigner)
+
+	// Optional diagnostics of the claims failing verification
+	diagnostics := os.Getenv("VERIFICATION_DIAGNOSTICS") == "true"
+	var sourceRPCClient *ethclient.Client
+	if sourceRPCEndpoint := os.Getenv("SOURCE_RPC_ENDPOINT"); diagnostics && sourceRPCEndpoint != "" {
+		sourceRPCClient, err = ethclient.Dial(sourceRPCEndpoint)
+		if err != nil {
+			log.Fatalf("Failed to create RPC client: %+v", err)
+		}
+	}
 
 	app := &App{
-		BackendEndpoint:    backendEndpoint,
-		VerifierAddress:    vslVerifierAddress,
-		VerifierPrivateKey: vslVerifierPrivateKey,
-		VSLRPC:             vslRPC,
-		VSLRPCClient:       vslRPCClient,
+		BackendEndpoint: backendEndpoint,
+		VerifierAddress: vslVerifierAddress,
+		VerifierSigner:  vslVerifierSigner,
+		VSLRPC:          vslRPC,
+		VSLRPCClient:    vslRPCClient,
+		VSLNonceManager: vsl.NewNonceManager(vslRPCClient),
+		Diagnostics:     diagnostics,
+		SourceRPCClient: sourceRPCClient,
 	}
 
 	return app
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -16,19 +17,16 @@ const (
 	ExecutionClient = "MirroringGeth"
 )
 
-func SettleClaimToVSL(app *models.App, claimId string) (*string, error) {
-	nonce, err := app.VSLRPCClient.GetAccountNonce(vsl.GetAccountNonceParams{
-		AccountId: app.VerifierAddress,
-	})
-	if err != nil {
-		errString := fmt.Sprintf("Error getting account nonce: %+v", err)
-		log.Println(errString)
-		return nil, errors.New(errString)
-	}
-	settledClaimId, err := app.VSLRPCClient.SettleClaim(vsl.SettleClaimParams{
-		From:          app.VerifierAddress,
-		Nonce:         fmt.Sprintf("%d", *nonce),
-		TargetClaimId: claimId,
+func SettleClaimToVSL(ctx context.Context, app *models.App, claimId string) (*string, error) {
+	var settledClaimId *string
+	err := app.VSLNonceManager.Do(ctx, app.VerifierAddress, func(nonce uint64) error {
+		var err error
+		settledClaimId, err = app.VSLRPCClient.SettleClaim(ctx, vsl.SettleClaimParams{
+			From:          app.VerifierAddress,
+			Nonce:         fmt.Sprintf("%d", nonce),
+			TargetClaimId: claimId,
+		})
+		return err
 	})
 	if err != nil {
 		errString := fmt.Sprintf("Error submitting claim to VSL: %+v", err)
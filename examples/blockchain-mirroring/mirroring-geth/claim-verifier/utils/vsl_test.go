@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"context"
+	"math/big"
+	"mirroring-geth-claim-verifier/models"
+	"testing"
+	"time"
+
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+	"base/pkg/vsl"
+	"base/pkg/vsl/vsltest"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSettleClaimToVSL(t *testing.T) {
+	ctx := context.Background()
+	node := vsltest.NewNode(t, nil)
+
+	submitterKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	verifierKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	submitterSigner := evm.NewPrivateKeySigner(submitterKey)
+	verifierSigner := evm.NewPrivateKeySigner(verifierKey)
+	node.Fund(submitterSigner.Address().Hex(), big.NewInt(10))
+
+	claimId, err := node.Client(submitterSigner).SubmitClaim(ctx, vsl.SubmitClaimParams{
+		Claim:     "{}",
+		ClaimType: ExecutionClient,
+		Proof:     "{}",
+		Nonce:     "0",
+		To:        []string{verifierSigner.Address().Hex()},
+		Quorum:    1,
+		From:      submitterSigner.Address().Hex(),
+		Expires:   abstract_types.Timestamp{Seconds: uint64(time.Now().Add(time.Minute).Unix())},
+		Fee:       "0x1",
+	})
+	if err != nil {
+		t.Fatalf("Failed to submit claim: %v", err)
+	}
+
+	vslClient := node.Client(verifierSigner)
+	app := &models.App{
+		VerifierAddress: verifierSigner.Address().Hex(),
+		VerifierSigner:  verifierSigner,
+		VSLRPC:          node.URL,
+		VSLRPCClient:    vslClient,
+		VSLNonceManager: vsl.NewNonceManager(vslClient),
+	}
+	_, err = SettleClaimToVSL(ctx, app, *claimId)
+	if err != nil {
+		t.Fatalf("Failed to settle claim: %v", err)
+	}
+
+	settled, err := vslClient.GetSettledClaimById(ctx, vsl.GetSettledClaimByIdParams{ClaimId: *claimId})
+	if err != nil {
+		t.Fatalf("Failed to get settled claim: %v", err)
+	}
+	if settled.Data.VerifiedClaim.ClaimOwner != submitterSigner.Address().Hex() {
+		t.Fatalf("Unexpected settled claim %+v", settled)
+	}
+	if node.Balance(verifierSigner.Address().Hex()).Int64() != 1 {
+		t.Fatalf("Expected the fee to be paid to the verifier")
+	}
+}
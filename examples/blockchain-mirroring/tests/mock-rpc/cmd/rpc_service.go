@@ -57,6 +57,17 @@ func (s *MockRPCService) GetBlockByNumber(ctx context.Context, blockNumber rpc.B
 	return s.MockBlock, nil
 }
 
+// BlockNumber is the RPC method handler for eth_blockNumber, the mock block being the head.
+func (s *MockRPCService) BlockNumber() (hexutil.Uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.MockBlockHeader.Number == nil {
+		return 0, fmt.Errorf("mock block header has no number")
+	}
+	return hexutil.Uint64(s.MockBlockHeader.Number.Uint64()), nil
+}
+
 // ChainId is the RPC method handler for eth_chainId.
 func (s *MockRPCService) ChainId() (*hexutil.Big, error) {
 	// No lock needed as ChainID is read-only after init
@@ -21,6 +21,7 @@ type UpsertClaimParameter struct {
 	ClaimId                    string `json:"claim_id" form:"claim_id" query:"claim_id"`
 	Claim                      string `json:"claim" form:"claim" query:"claim"`
 	ClaimJSON                  string `json:"claim_json" form:"claim_json" query:"claim_json"`
+	Error                      string `json:"error" form:"error" query:"error"`
 }
 
 func RegisterClaimAPI(app *clients.App) {
@@ -165,4 +166,7 @@ func upsertClaim(claim *models.ClaimRecord, upsertClaimParams *UpsertClaimParame
 	if upsertClaimParams.ClaimJSON != "" {
 		claim.ClaimJSON = upsertClaimParams.ClaimJSON
 	}
+	if upsertClaimParams.Error != "" {
+		claim.Error = upsertClaimParams.Error
+	}
 }
@@ -10,4 +10,5 @@ type ClaimRecord struct {
 	ClaimJSON                  string `json:"claim_json" gorm:"column:claim_json"`
 	SourceTransactionHash      string `json:"source_transaction_hash" gorm:"column:source_transaction_hash"`
 	DestinationTransactionHash string `json:"destination_transaction_hash" gorm:"column:destination_transaction_hash"`
+	Error                      string `json:"error" gorm:"column:error"`
 }
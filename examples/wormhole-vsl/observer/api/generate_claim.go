@@ -26,19 +26,19 @@ func RegisterGenerateClaimAPI(app *models.App) {
 			return c.Status(400).SendString("transaction_hash is required")
 		}
 
-		tx, err := app.EthRPCClient.TransactionReceipt(ctx, common.HexToHash(transactionHash))
+		tx, err := app.SourceRPC.EthClient().TransactionReceipt(ctx, common.HexToHash(transactionHash))
 		if err != nil {
 			return c.Status(400).SendString("failed to get transaction")
 		}
 		for _, l := range tx.Logs {
 			for _, t := range l.Topics {
 				if t.Cmp(crypto.Keccak256Hash([]byte(app.SourceVSLContractFunction))) == 0 {
-					claim, verCtx, err := generation.Generate(app.EthRPCClient, *l, *app.SourceVSLContractAddress, app.SourceVSLContractABIJSON)
+					claim, verCtx, err := generation.GenerateWithCache(app.SourceRPC.EthClient(), *l, *app.SourceVSLContractAddress, app.SourceVSLContractABIJSON, 0, app.SourceCache)
 					if err != nil {
 						log.Printf("Failed to generate claim\nError: %+v", err)
 						return c.Status(400).SendString("failed to generate claim")
 					}
-					claimId, claimHex, _, err := utils.SubmitClaimToVSL(app, claim, verCtx)
+					claimId, claimHex, _, err := utils.SubmitClaimToVSL(ctx, app, claim, verCtx)
 					if err != nil {
 						log.Printf("Failed to submit claim to VSL\nError: %+v", err)
 						return c.Status(400).SendString("failed to submit claim")
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"observer/api"
@@ -18,6 +19,14 @@ func main() {
 	}
 
 	app := models.NewApp()
+	go app.SourceRPC.Run(context.Background())
+	if app.VSLFunder != nil {
+		go app.VSLFunder.Run(context.Background())
+	}
+
+	// Follow the submitted claims until they are settled or expire
+	go app.VSLClaimTracker.Run(context.Background())
+	go utils.TrackClaims(app)
 
 	mode := os.Getenv("MODE")
 
@@ -6,42 +6,39 @@ import (
 	"math/big"
 	"os"
 
+	"base/pkg/ethrpc"
+	"base/pkg/evm"
 	"base/pkg/vsl"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/ethclient/gethclient"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/cors"
 )
 
 type App struct {
-	API                          *fiber.App
-	Port                         string
-	BackendAPIEndpoint           string
-	SourceChainRPCEndpoint       string
-	SourceChainWebsocketEndpoint string
-	SourceVSLContractFunction    string
-	SourceVSLContractAddress     *common.Address
-	SourceVSLContractABIJSON     string
-	RPCClient                    *rpc.Client
-	EthRPCClient                 *ethclient.Client
-	EthWSClient                  *ethclient.Client
-	ChainId                      *big.Int
-	GethClient                   *gethclient.Client
-	VSLRPC                       string
-	VSLRPCClient                 *vsl.VSLRPCClient
-	VSLClientAddress             string
-	VSLClientPrivateKey          string
-	VSLVerifierAddress           string
-	VSLVerifierPrivateKey        string
+	API                           *fiber.App
+	Port                          string
+	BackendAPIEndpoint            string
+	SourceChainWebsocketEndpoints []string
+	SourceVSLContractFunction     string
+	SourceVSLContractAddress      *common.Address
+	SourceVSLContractABIJSON      string
+	SourceRPC                     *ethrpc.MultiClient
+	SourceCache                   *ethrpc.Cache
+	EthWSClient                   *ethrpc.WSClient
+	ChainId                       *big.Int
+	VSLRPC                        string
+	VSLRPCClient                  *vsl.VSLRPCClient
+	VSLNonceManager               *vsl.NonceManager
+	VSLClientAddress              string
+	VSLClientSigner               evm.Signer
+	VSLSubmissionPolicy           *vsl.SubmissionPolicy
+	VSLClaimTracker               *vsl.ClaimTracker
+	VSLFunder                     *vsl.Funder
 }
 
 func NewApp() *App {
 	port := os.Getenv("PORT")
-	sourceChainRPCEndpoint := os.Getenv("SOURCE_RPC_ENDPOINT")
-	sourceChainWebsocketEndpoint := os.Getenv("SOURCE_WEBSOCKET_ENDPOINT")
 	backendAPIEndpoint := os.Getenv("BACKEND_API_ENDPOINT")
 
 	log.Printf("Start observing chain for state query claims")
@@ -53,54 +50,89 @@ func NewApp() *App {
 	// VSL
 	vslRPC := os.Getenv("VSL_RPC")
 	vslClientAddress := os.Getenv("VSL_CLIENT_ADDRESS")
-	vslClientPrivateKey := os.Getenv("VSL_CLIENT_PRIVATE_KEY")
-	vslVerifierAddress := os.Getenv("VSL_VERIFIER_ADDRESS")
-	vslVerifierPrivateKey := os.Getenv("VSL_VERIFIER_PRIVATE_KEY")
 
-	rpcClient, err := rpc.Dial(sourceChainRPCEndpoint)
+	ctx := context.Background()
+
+	// The source chain endpoints are loaded from SOURCE_RPC_ENDPOINTS or SOURCE_RPC_ENDPOINT, with SOURCE_RPC_QUORUM
+	sourceRPCConfig, err := ethrpc.MultiClientConfigFromEnv("SOURCE")
+	if err != nil {
+		log.Fatalf("Failed to load source RPC config: %+v", err)
+	}
+	sourceRPC, err := ethrpc.DialMultiClient(ctx, sourceRPCConfig)
 	if err != nil {
 		log.Fatalf("Failed to create RPC client: %+v", err)
 	}
 
-	ethRPCClient := ethclient.NewClient(rpcClient)
-	ethWSClient, err := ethclient.Dial(sourceChainWebsocketEndpoint)
+	// The proofs and code fetched for the claims are cached in memory, up to SOURCE_RPC_CACHE_MB, and in SOURCE_RPC_CACHE_DIR if set
+	sourceCacheConfig, err := ethrpc.CacheConfigFromEnv("SOURCE")
+	if err != nil {
+		log.Fatalf("Failed to load source cache config: %+v", err)
+	}
+	sourceCache, err := ethrpc.NewCache(sourceCacheConfig)
+	if err != nil {
+		log.Fatalf("Failed to create source cache: %+v", err)
+	}
+
+	// The source chain is observed over SOURCE_WEBSOCKET_ENDPOINTS or SOURCE_WEBSOCKET_ENDPOINT, failing over between them
+	sourceChainWebsocketEndpoints := ethrpc.WSEndpointsFromEnv("SOURCE")
+	ethWSClient, err := ethrpc.DialWSClient(ctx, sourceChainWebsocketEndpoints)
 	if err != nil {
 		log.Fatalf("Failed to create WS client: %+v", err)
 	}
 
-	gethClient := gethclient.New(rpcClient)
+	// The client key is loaded from VSL_CLIENT_PRIVATE_KEY, VSL_CLIENT_KEYSTORE or VSL_CLIENT_REMOTE_SIGNER_URL
+	vslClientSigner, err := evm.NewSigner(ctx, evm.SignerConfigFromEnv("VSL_CLIENT"))
+	if err != nil {
+		log.Fatalf("Failed to create VSL client signer: %+v", err)
+	}
+	if vslClientAddress == "" {
+		vslClientAddress = vslClientSigner.Address().Hex()
+	}
 
-	ctx := context.Background()
-	chainId, err := ethRPCClient.ChainID(ctx)
+	chainId, err := sourceRPC.ChainID(ctx)
 	if err != nil {
 		log.Fatalf("Failed to get chain ID: %+v", err)
 	}
 
+	// The claim fee, expiry and verifiers are loaded from VSL_SUBMISSION_POLICY, or default to VSL_VERIFIER_ADDRESS
+	vslSubmissionPolicy, err := vsl.SubmissionPolicyFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load VSL submission policy: %+v", err)
+	}
+
+	// Optional balance monitoring and top-up of the VSL accounts, configured by VSL_FUNDING_CONFIG
+	vslFunder, err := vsl.FunderFromEnv(ctx, vslRPC)
+	if err != nil {
+		log.Fatalf("Failed to create VSL funder: %+v", err)
+	}
+
+	vslRPCClient := vsl.NewVSLRPCClient(vslRPC, vslClientSigner)
+
 	fiberApp := fiber.New()
 	fiberApp.Use(cors.New(cors.Config{
 		AllowOrigins: []string{"*"},
 	}))
 
 	app := &App{
-		API:                          fiberApp,
-		Port:                         port,
-		BackendAPIEndpoint:           backendAPIEndpoint,
-		SourceChainRPCEndpoint:       sourceChainRPCEndpoint,
-		SourceChainWebsocketEndpoint: sourceChainWebsocketEndpoint,
-		SourceVSLContractFunction:    sourceVSLContractFunction,
-		SourceVSLContractAddress:     &sourceVSLContractAddress,
-		SourceVSLContractABIJSON:     sourceVSLContractABIJSON,
-		RPCClient:                    rpcClient,
-		EthRPCClient:                 ethRPCClient,
-		EthWSClient:                  ethWSClient,
-		GethClient:                   gethClient,
-		ChainId:                      chainId,
-		VSLRPC:                       vslRPC,
-		VSLRPCClient:                 vsl.NewVSLRPCClient(vslRPC, vslClientPrivateKey),
-		VSLClientAddress:             vslClientAddress,
-		VSLClientPrivateKey:          vslClientPrivateKey,
-		VSLVerifierAddress:           vslVerifierAddress,
-		VSLVerifierPrivateKey:        vslVerifierPrivateKey,
+		API:                           fiberApp,
+		Port:                          port,
+		BackendAPIEndpoint:            backendAPIEndpoint,
+		SourceChainWebsocketEndpoints: sourceChainWebsocketEndpoints,
+		SourceVSLContractFunction:     sourceVSLContractFunction,
+		SourceVSLContractAddress:      &sourceVSLContractAddress,
+		SourceVSLContractABIJSON:      sourceVSLContractABIJSON,
+		SourceRPC:                     sourceRPC,
+		SourceCache:                   sourceCache,
+		EthWSClient:                   ethWSClient,
+		ChainId:                       chainId,
+		VSLRPC:                        vslRPC,
+		VSLRPCClient:                  vslRPCClient,
+		VSLNonceManager:               vsl.NewNonceManager(vslRPCClient),
+		VSLClientAddress:              vslClientAddress,
+		VSLClientSigner:               vslClientSigner,
+		VSLSubmissionPolicy:           vslSubmissionPolicy,
+		VSLClaimTracker:               vsl.NewClaimTracker(vslRPCClient, vslClientAddress, vsl.DefaultTrackerInterval),
+		VSLFunder:                     vslFunder,
 	}
 
 	return app
@@ -9,16 +9,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/big"
 	"observer/models"
 	"strings"
-	"time"
 
 	generationModels "generation-view-fn-evm/pkg/models"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/gofiber/fiber"
 	"github.com/gofiber/fiber/v3/client"
 	"github.com/pkg/errors"
@@ -42,22 +39,22 @@ type SignedSubmitClaimParams struct {
 }
 
 // SubmitClaimToPod will sends the claim to the USL API
-func SubmitClaimToVSL(app *models.App, claim interface{}, verificationContext interface{}) (*string, *string, *string, error) {
+func SubmitClaimToVSL(ctx context.Context, app *models.App, claim *generationModels.EVMViewFnClaim, verificationContext *generationModels.EVMViewFnClaimVerificationContext) (*string, *string, *string, error) {
 	log.Printf("Submitting claim to VSL to url %s", app.VSLRPC)
 
-	claimBytes, err := claim.(*generationModels.EVMViewFnClaim).AbiEncode()
+	// Only claim blocks which the quorum of source RPC endpoints agree are canonical
+	assumptions := claim.Assumptions
+	err := app.SourceRPC.VerifyBlockHash(ctx, assumptions.Number, assumptions.Hash)
 	if err != nil {
 		return nil, nil, nil, errors.WithStack(err)
 	}
 
-	proofBytes, err := verificationContext.(*generationModels.EVMViewFnClaimVerificationContext).AbiEncode()
+	claimBytes, err := claim.AbiEncode()
 	if err != nil {
 		return nil, nil, nil, errors.WithStack(err)
 	}
 
-	clientNonce, err := app.VSLRPCClient.GetAccountNonce(vsl.GetAccountNonceParams{
-		AccountId: app.VSLClientAddress,
-	})
+	proofBytes, err := verificationContext.AbiEncode()
 	if err != nil {
 		return nil, nil, nil, errors.WithStack(err)
 	}
@@ -65,37 +62,44 @@ func SubmitClaimToVSL(app *models.App, claim interface{}, verificationContext in
 	claimHex := hexutil.Encode(claimBytes)
 	proofHex := hexutil.Encode(proofBytes)
 
-	claimId, err := app.VSLRPCClient.SubmitClaim(vsl.SubmitClaimParams{
-		Claim:     claimHex,
-		ClaimType: "EVMViewFn",
-		Proof:     proofHex,
-		To:        []string{app.VSLVerifierAddress},
-		Quorum:    1,
-		From:      app.VSLClientAddress,
-		Nonce:     fmt.Sprintf("%d", *clientNonce),
-		Expires: abstract_types.Timestamp{
-			Seconds: uint64(time.Now().Unix() + 600), // 10 minutes
-			Nanos:   0,
-		},
-		Fee: hexutil.EncodeBig(big.NewInt(1)),
-	})
+	terms, err := app.VSLSubmissionPolicy.Terms("EVMViewFn", len(proofBytes))
+	if err != nil {
+		log.Printf("Failed to submit claim: %s", err)
+		return nil, nil, nil, errors.WithStack(err)
+	}
 
+	var claimId *string
+	err = app.VSLNonceManager.Do(ctx, app.VSLClientAddress, func(clientNonce uint64) error {
+		claimId, err = app.VSLRPCClient.SubmitClaim(ctx, vsl.SubmitClaimParams{
+			Claim:     claimHex,
+			ClaimType: "EVMViewFn",
+			Proof:     proofHex,
+			To:        terms.To,
+			Quorum:    terms.Quorum,
+			From:      app.VSLClientAddress,
+			Nonce:     fmt.Sprintf("%d", clientNonce),
+			Expires:   terms.Expires,
+			Fee:       terms.Fee,
+		})
+		return err
+	})
 	if err != nil {
+		// A consumed nonce means the claim was probably applied and its fee charged
+		if !errors.Is(err, vsl.ErrNonceConsumed) {
+			app.VSLSubmissionPolicy.Release(terms)
+		}
 		log.Printf("Failed to submit claim: %s", err)
 		return nil, nil, nil, errors.WithStack(err)
 	}
 
 	log.Printf("Claim submitted to VSL with id %s", *claimId)
+	app.VSLClaimTracker.Track(*claimId, terms.Quorum, terms.Expires)
 
 	return claimId, &claimHex, &proofHex, nil
 }
 
 func SubmitClaimToBackend(app *models.App, sourceChainTransactionHex string, claimId string, claim any, claimHex string) error {
-	srcChainRPCClient, err := rpc.Dial(app.SourceChainRPCEndpoint)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	srcChainTx, err := ethrpc.GetTransactionByHash(srcChainRPCClient, context.Background(), common.HexToHash(sourceChainTransactionHex).String())
+	srcChainTx, err := ethrpc.GetTransactionByHash(app.SourceRPC.Client(), context.Background(), common.HexToHash(sourceChainTransactionHex).String())
 	if err != nil {
 		return errors.WithStack(err)
 	}
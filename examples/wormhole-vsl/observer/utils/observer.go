@@ -20,7 +20,7 @@ import (
 func ObserveViewFnAutoMode(app *models.App) error {
 	ctx := context.Background()
 
-	chainIdBig, err := app.EthRPCClient.ChainID(ctx)
+	chainIdBig, err := app.SourceRPC.ChainID(ctx)
 	if err != nil {
 		return err
 	}
@@ -31,15 +31,13 @@ func ObserveViewFnAutoMode(app *models.App) error {
 	log.Printf("Source contract function: %s\n", app.SourceVSLContractFunction)
 	log.Printf("Source contract address: %s\n", app.SourceVSLContractAddress)
 
-	// Subscribe to new logs
+	// Subscribe to new logs, resubscribing over the next websocket endpoint when one fails
 	newLogsChannel := make(chan types.Log)
-	newLogsSubscribe, err := app.EthWSClient.SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+	newLogsSubscribe := app.EthWSClient.SubscribeFilterLogs(ethereum.FilterQuery{
 		Addresses: []common.Address{*app.SourceVSLContractAddress},
 		Topics:    [][]common.Hash{{crypto.Keccak256Hash([]byte(app.SourceVSLContractFunction))}},
 	}, newLogsChannel)
-	if err != nil {
-		return err
-	}
+	defer newLogsSubscribe.Unsubscribe()
 
 	for {
 		select {
@@ -49,14 +47,14 @@ func ObserveViewFnAutoMode(app *models.App) error {
 			log.Printf("New log detected")
 
 			// Generate claim
-			claim, verCtx, err := generation.Generate(app.EthRPCClient, newLog, *app.SourceVSLContractAddress, app.SourceVSLContractABIJSON)
+			claim, verCtx, err := generation.GenerateWithCache(app.SourceRPC.EthClient(), newLog, *app.SourceVSLContractAddress, app.SourceVSLContractABIJSON, 0, app.SourceCache)
 			if err != nil {
 				log.Printf("Failed to generate state query claim\nError: %+v", err)
 				continue
 			}
 
 			// Submit claim to VSL
-			claimId, claimHex, _, err := SubmitClaimToVSL(app, claim, verCtx)
+			claimId, claimHex, _, err := SubmitClaimToVSL(ctx, app, claim, verCtx)
 			if err != nil {
 				log.Printf("%v", err)
 				continue
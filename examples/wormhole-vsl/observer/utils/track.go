@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"observer/models"
+
+	"base/pkg/vsl"
+
+	"github.com/gofiber/fiber"
+	"github.com/gofiber/fiber/v3/client"
+	"github.com/pkg/errors"
+)
+
+// TrackClaims reports the lifecycle events of the submitted claims, and records the claims that
+// expired before being settled as failed in the backend
+func TrackClaims(app *models.App) {
+	for event := range app.VSLClaimTracker.Events() {
+		switch event.Type {
+		case vsl.ClaimQuorumReached:
+			log.Printf("Claim %s reached its quorum with %d verifiers", event.ClaimId, len(event.SettledClaim.Data.Verifiers))
+		case vsl.ClaimSettled:
+			log.Printf("Claim %s settled at %d", event.ClaimId, event.Timestamp.Seconds)
+		case vsl.ClaimExpired:
+			log.Printf("Claim %s expired before being settled", event.ClaimId)
+			errString := fmt.Sprintf("Claim expired at %d before being settled", event.Timestamp.Seconds)
+			err := SubmitClaimErrorToBackend(app, event.ClaimId, errString)
+			if err != nil {
+				log.Printf("Error submitting claim error to backend: %+v", err)
+			}
+		}
+	}
+}
+
+// SubmitClaimErrorToBackend records the error of a submitted claim in the backend
+func SubmitClaimErrorToBackend(app *models.App, claimId string, errString string) error {
+	apiClient := client.New()
+	resp, err := apiClient.Put(app.BackendAPIEndpoint+"/claim/"+claimId, client.Config{
+		Body: fiber.Map{
+			"error": errString,
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("failed to submit claim error\nError: %s", resp.Body())
+	}
+
+	return nil
+}
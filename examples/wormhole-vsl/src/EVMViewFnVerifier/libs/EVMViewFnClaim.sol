@@ -51,6 +51,15 @@ library EVMViewFnClaim {
         bytes extra;
         bytes32 mixDigest;
         bytes8 nonce;
+        // Fields added by later forks, zero before their fork
+        uint256 baseFee;
+        bytes32 withdrawalsHash;
+        uint256 blobGasUsed;
+        uint256 excessBlobGas;
+        bytes32 parentBeaconRoot;
+        bytes32 requestsHash;
+        // Hash of the block the header hashes to
+        bytes32 hash;
     }
 
     // Bitmask for the first callData in the proof.
@@ -43,7 +43,14 @@ contract ViewFnClaimTest is Test {
             time: block.timestamp,
             extra: new bytes(0),
             mixDigest: bytes32(uint256(6)),
-            nonce: bytes8(uint64(123456))
+            nonce: bytes8(uint64(123456)),
+            baseFee: 0,
+            withdrawalsHash: bytes32(0),
+            blobGasUsed: 0,
+            excessBlobGas: 0,
+            parentBeaconRoot: bytes32(0),
+            requestsHash: bytes32(0),
+            hash: bytes32(0)
         });
 
         EVMViewFnClaim.EVMCall memory evmCall = EVMViewFnClaim.EVMCall({
@@ -146,7 +153,14 @@ contract ViewFnClaimTest is Test {
             time: 0,
             extra: new bytes(0),
             mixDigest: bytes32(0),
-            nonce: bytes8(0)
+            nonce: bytes8(0),
+            baseFee: 0,
+            withdrawalsHash: bytes32(0),
+            blobGasUsed: 0,
+            excessBlobGas: 0,
+            parentBeaconRoot: bytes32(0),
+            requestsHash: bytes32(0),
+            hash: bytes32(0)
         });
 
         EVMViewFnClaim.EVMCall memory evmCall = EVMViewFnClaim.EVMCall({
@@ -213,7 +227,14 @@ contract ViewFnClaimTest is Test {
             time: 0,
             extra: new bytes(0),
             mixDigest: bytes32(uint256(6)),
-            nonce: bytes8(uint64(123456))
+            nonce: bytes8(uint64(123456)),
+            baseFee: 0,
+            withdrawalsHash: bytes32(0),
+            blobGasUsed: 0,
+            excessBlobGas: 0,
+            parentBeaconRoot: bytes32(0),
+            requestsHash: bytes32(0),
+            hash: bytes32(0)
         });
 
         EVMViewFnClaim.EVMCall memory evmCall = EVMViewFnClaim.EVMCall({
@@ -3,6 +3,7 @@ package main
 import (
 	"base/pkg/abstract_types"
 	"base/pkg/evm"
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -24,14 +25,20 @@ func main() {
 	}
 
 	vslRPC := os.Getenv("VSL_RPC")
+	// Optional diagnostic report attached to the error of the claims failing verification
+	diagnostics := os.Getenv("VERIFICATION_DIAGNOSTICS") == "true"
 
-	vslVerifierPrivateKey := os.Getenv("VSL_VERIFIER_PRIVATE_KEY")
-	verifierAddress, err := evm.AddressFromPrivateKey(vslVerifierPrivateKey)
+	ctx := context.Background()
+
+	// The verifier key is loaded from VSL_VERIFIER_PRIVATE_KEY, VSL_VERIFIER_KEYSTORE or VSL_VERIFIER_REMOTE_SIGNER_URL
+	verifierSigner, err := evm.NewSigner(ctx, evm.SignerConfigFromEnv("VSL_VERIFIER"))
 	if err != nil {
-		log.Fatalf("Error getting verifier address: %v", err)
+		log.Fatalf("Error creating verifier signer: %v", err)
 	}
+	verifierAddress := verifierSigner.Address()
 
-	vslRPCClient := vsl.NewVSLRPCClient(vslRPC, vslVerifierPrivateKey)
+	vslRPCClient := vsl.NewVSLRPCClient(vslRPC, verifierSigner)
+	vslNonceManager := vsl.NewNonceManager(vslRPCClient)
 
 	fmt.Println("Start observing VSL(", vslRPC, ") for verifier address: ", verifierAddress)
 
@@ -43,7 +50,7 @@ func main() {
 	for {
 		fmt.Println("Since: seconds: ", since.Seconds, "nanos: ", since.Nanos)
 
-		claims, err := vslRPCClient.ListSubmittedClaimsForReceiver(vsl.ListSubmittedClaimsForReceiverParams{
+		claims, err := vslRPCClient.ListSubmittedClaimsForReceiver(ctx, vsl.ListSubmittedClaimsForReceiverParams{
 			Since:   since,
 			Address: verifierAddress.Hex(),
 		})
@@ -53,48 +60,53 @@ func main() {
 		}
 
 		for _, claim := range claims {
-			claimId := claim.Get("id").String()
-			claimInformations := claim.Get("data")
+			claimId := claim.Id
+			claimInformations := claim.Data
 
-			claimTimestampSeconds := claim.Get("timestamp").Get("seconds").Uint()
-			claimTimestampNanos := claim.Get("timestamp").Get("nanos").Uint()
+			claimTimestampSeconds := claim.Timestamp.Seconds
+			claimTimestampNanos := claim.Timestamp.Nanos
 
-			claimBytesString := claimInformations.Get("claim").String()
-			claimBytes := hexutil.MustDecode(claimBytesString)
+			claimBytes, err := hexutil.Decode(claimInformations.Claim)
+			if err != nil {
+				log.Printf("Error decoding claim hex: %v", err)
+				continue
+			}
 			claim, err := generationModels.AbiDecodeEVMViewFnClaim(claimBytes)
 			if err != nil {
 				log.Printf("Error decoding claim: %v", err)
 				continue
 			}
 
-			proofBytesString := claimInformations.Get("proof").String()
-			proofBytes := hexutil.MustDecode(proofBytesString)
-			proof, err := generationModels.AbiDecodeEVMViewFnClaimVerificationContext(proofBytes)
+			proofBytes, err := hexutil.Decode(claimInformations.Proof)
 			if err != nil {
-				log.Printf("Error decoding proof: %v", err)
+				log.Printf("Error decoding proof hex: %v", err)
 				continue
 			}
-
-			err = verification.Verify(claim, proof)
+			proof, err := generationModels.AbiDecodeEVMViewFnClaimVerificationContext(proofBytes)
 			if err != nil {
-				log.Printf("Error verifying claim: %v", err)
+				log.Printf("Error decoding proof: %v", err)
 				continue
 			}
 
-			nonce, err := vslRPCClient.GetAccountNonce(vsl.GetAccountNonceParams{
-				AccountId: verifierAddress.Hex(),
-			})
+			err = verification.Verify(claim, proof)
 			if err != nil {
-				log.Printf("Error getting nonce: %v", err)
+				errString := fmt.Sprintf("Error verifying claim %s: %v", claimId, err)
+				if diagnostics {
+					errString = fmt.Sprintf("%s\nDiagnostics: %s", errString, verification.Diagnose(claim, proof, err))
+				}
+				log.Println(errString)
 				continue
 			}
 
-			settledClaimId, err := vslRPCClient.SettleClaim(vsl.SettleClaimParams{
-				From:          verifierAddress.Hex(),
-				Nonce:         fmt.Sprintf("%d", *nonce),
-				TargetClaimId: claimId,
+			var settledClaimId *string
+			err = vslNonceManager.Do(ctx, verifierAddress.Hex(), func(nonce uint64) error {
+				settledClaimId, err = vslRPCClient.SettleClaim(ctx, vsl.SettleClaimParams{
+					From:          verifierAddress.Hex(),
+					Nonce:         fmt.Sprintf("%d", nonce),
+					TargetClaimId: claimId,
+				})
+				return err
 			})
-
 			if err != nil {
 				log.Printf("Error settling claim: %v", err)
 				continue
@@ -104,7 +116,7 @@ func main() {
 
 			if claimTimestampSeconds >= since.Seconds {
 				since.Seconds = claimTimestampSeconds
-				if uint32(claimTimestampNanos) > since.Nanos {
+				if claimTimestampNanos > since.Nanos {
 					since.Nanos = uint32(claimTimestampNanos)
 				}
 				since.Tick()
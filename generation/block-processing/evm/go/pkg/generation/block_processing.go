@@ -6,7 +6,7 @@ import (
 	"math/big"
 
 	"base/pkg/abstract_types"
-	basemodels "base/pkg/models"
+	"base/pkg/ethrpc"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -19,6 +19,12 @@ import (
 // - ethClient: The eth client instance
 // - blockNumber: The block number
 func Generate(ethClient *ethclient.Client, blockNumber *big.Int) (*models.EVMBlockProcessingClaim, *models.EVMBlockProcessingClaimVerificationContext, error) {
+	return GenerateWithCache(ethClient, blockNumber, nil)
+}
+
+// GenerateWithCache generates a block claim like Generate, reusing the execution witness of the
+// block held by the cache and adding the fetched one to it
+func GenerateWithCache(ethClient *ethclient.Client, blockNumber *big.Int, cache *ethrpc.Cache) (*models.EVMBlockProcessingClaim, *models.EVMBlockProcessingClaimVerificationContext, error) {
 	ctx := context.Background()
 	chainId, err := ethClient.ChainID(ctx)
 	if err != nil {
@@ -44,8 +50,7 @@ func Generate(ethClient *ethclient.Client, blockNumber *big.Int) (*models.EVMBlo
 	// witness := rethWitness.ToStatelessWitness(previousBlockHeader, block.Header())
 
 	// Geth witness
-	var gethWitness *basemodels.GethWitness
-	err = ethClient.Client().CallContext(ctx, &gethWitness, "debug_executionWitness", block.Hash())
+	gethWitness, err := ethrpc.GetExecutionWitness(ethClient.Client(), ctx, block.Hash(), cache)
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
 	}
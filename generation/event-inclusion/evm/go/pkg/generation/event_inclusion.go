@@ -0,0 +1,89 @@
+package generation
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/ethrpc"
+	"base/pkg/evm"
+	basemodels "base/pkg/models"
+	"context"
+	"generation-event-inclusion-evm/pkg/models"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+)
+
+// Generate generates an event inclusion claim for an event, proving the receipt of its transaction
+// in the receipts trie of its block
+//
+// Parameters:
+// - ethClient: The eth client instance
+// - event: The event, as returned by a log filter or a transaction receipt
+func Generate(ethClient *ethclient.Client, event types.Log) (*models.EVMEventInclusionClaim, *models.EVMEventInclusionClaimVerificationContext, error) {
+	ctx := context.Background()
+
+	if event.Removed {
+		return nil, nil, errors.Errorf("event %d of block %s was removed by a reorganization", event.Index, event.BlockHash)
+	}
+
+	chainId, err := ethClient.ChainID(ctx)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	blockHeader, err := ethClient.HeaderByHash(ctx, event.BlockHash)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	evmReceipts, err := ethrpc.GetBlockReceipts(ethClient.Client(), ctx, event.BlockHash.Hex())
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	receipts := basemodels.ToGethReceipts(evmReceipts)
+	if event.TxIndex >= uint(len(receipts)) {
+		return nil, nil, errors.Errorf("transaction %d is out of the %d transactions of block %s", event.TxIndex, len(receipts), event.BlockHash)
+	}
+	receipt := receipts[event.TxIndex]
+	if receipt.TxHash != event.TxHash {
+		return nil, nil, errors.Errorf("transaction %d of block %s is %s instead of %s", event.TxIndex, event.BlockHash, receipt.TxHash, event.TxHash)
+	}
+
+	// Find the log in the receipt from its index in the block
+	logIndex := -1
+	for i, log := range receipt.Logs {
+		if log.Index == event.Index {
+			logIndex = i
+			break
+		}
+	}
+	if logIndex < 0 {
+		return nil, nil, errors.Errorf("event %d is not in the receipt of transaction %s", event.Index, event.TxHash)
+	}
+
+	receiptsRoot, receiptProof, err := evm.ProveListItem(receipts, int(event.TxIndex))
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if receiptsRoot != blockHeader.ReceiptHash {
+		return nil, nil, errors.Errorf("receipts of block %s hash to %s instead of its receipts root %s", event.BlockHash, receiptsRoot, blockHeader.ReceiptHash)
+	}
+
+	log := receipt.Logs[logIndex]
+	claim := &models.EVMEventInclusionClaim{
+		ClaimType:   models.EVMEventInclusionClaimType,
+		Assumptions: abstract_types.NewHeader(blockHeader),
+		Action: &models.EVMEvent{
+			TxIndex:  uint64(event.TxIndex),
+			LogIndex: uint64(logIndex),
+			Address:  log.Address,
+			Topics:   log.Topics,
+			Data:     log.Data,
+		},
+		Metadata: abstract_types.EVMMetadata{
+			ChainId: chainId,
+		},
+	}
+	return claim, &models.EVMEventInclusionClaimVerificationContext{
+		ReceiptProof: receiptProof,
+	}, nil
+}
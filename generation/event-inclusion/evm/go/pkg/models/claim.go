@@ -0,0 +1,153 @@
+package models
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	EVMEventInclusionClaimEncodeAbiJSON = `[{"type":"function","name":"encode","inputs":[{"name":"","type":"tuple","internalType":"structEVMEventInclusionClaimVerifier.EVMEventInclusionClaim","components":[{"name":"claimType","type":"string","internalType":"string"},{"name":"trustBaseSpec","type":"string","internalType":"string"},{"name":"assumptions","type":"tuple","internalType":"structEVMEventInclusionClaimVerifier.Header","components":[{"name":"parentHash","type":"bytes32","internalType":"bytes32"},{"name":"uncleHash","type":"bytes32","internalType":"bytes32"},{"name":"coinbase","type":"address","internalType":"address"},{"name":"root","type":"bytes32","internalType":"bytes32"},{"name":"txHash","type":"bytes32","internalType":"bytes32"},{"name":"receiptHash","type":"bytes32","internalType":"bytes32"},{"name":"bloom","type":"bytes","internalType":"bytes"},{"name":"difficulty","type":"uint256","internalType":"uint256"},{"name":"number","type":"uint256","internalType":"uint256"},{"name":"gasLimit","type":"uint256","internalType":"uint256"},{"name":"gasUsed","type":"uint256","internalType":"uint256"},{"name":"time","type":"uint256","internalType":"uint256"},{"name":"extra","type":"bytes","internalType":"bytes"},{"name":"mixDigest","type":"bytes32","internalType":"bytes32"},{"name":"nonce","type":"bytes8","internalType":"bytes8"},{"name":"baseFee","type":"uint256","internalType":"uint256"},{"name":"withdrawalsHash","type":"bytes32","internalType":"bytes32"},{"name":"blobGasUsed","type":"uint256","internalType":"uint256"},{"name":"excessBlobGas","type":"uint256","internalType":"uint256"},{"name":"parentBeaconRoot","type":"bytes32","internalType":"bytes32"},{"name":"requestsHash","type":"bytes32","internalType":"bytes32"},{"name":"hash","type":"bytes32","internalType":"bytes32"}]},{"name":"action","type":"tuple","internalType":"structEVMEventInclusionClaimVerifier.EVMEvent","components":[{"name":"txIndex","type":"uint64","internalType":"uint64"},{"name":"logIndex","type":"uint64","internalType":"uint64"},{"name":"address","type":"address","internalType":"address"},{"name":"topics","type":"bytes32[]","internalType":"bytes32[]"},{"name":"data","type":"bytes","internalType":"bytes"}]},{"name":"metadata","type":"tuple","internalType":"structEVMEventInclusionClaimVerifier.EVMMetadata","components":[{"name":"chainId","type":"uint256","internalType":"uint256"}]}]},{"name":"","type":"tuple","internalType":"structEVMEventInclusionClaimVerifier.EVMEventInclusionClaimVerificationData","components":[{"name":"receiptProof","type":"bytes[]","internalType":"bytes[]"}]}],"outputs":[{"name":"","type":"bool","internalType":"bool"}],"stateMutability":"pure"}]`
+)
+
+// EVMEventInclusionClaimType is the claim type of EVMEventInclusionClaim
+const EVMEventInclusionClaimType = "EVMEventInclusion"
+
+// EVMEvent is a log emitted by a transaction of a block
+type EVMEvent struct {
+	// TxIndex is the index of the transaction in the block
+	TxIndex uint64 `json:"txIndex"`
+	// LogIndex is the index of the log in the receipt of the transaction, not in the block
+	LogIndex uint64         `json:"logIndex"`
+	Address  common.Address `json:"address"`
+	Topics   []common.Hash  `json:"topics"`
+	Data     []byte         `json:"data"`
+}
+
+// EVMEventInclusionClaim claims that a contract emitted an event in the block of the assumptions
+type EVMEventInclusionClaim struct {
+	ClaimType     string                     `json:"type"`
+	TrustBaseSpec string                     `json:"trustBaseSpec"`
+	Assumptions   *abstract_types.Header     `json:"assumptions"`
+	Action        *EVMEvent                  `json:"action"`
+	Metadata      abstract_types.EVMMetadata `json:"metadata"`
+}
+
+func (c *EVMEventInclusionClaim) GetId() (*string, error) {
+	return abstract_types.JSONClaimId(c)
+}
+
+// VerificationContext for EVMEventInclusionClaim: the proof of the receipt of the transaction in
+// the receipts trie of the block, from the root node down
+type EVMEventInclusionClaimVerificationContext struct {
+	ReceiptProof [][]byte `json:"receiptProof"`
+}
+
+func GetAbi() abi.ABI {
+	contractAbi, err := abi.JSON(strings.NewReader(EVMEventInclusionClaimEncodeAbiJSON))
+	if err != nil {
+		panic(err)
+	}
+
+	return contractAbi
+}
+
+// abiClaim is the ABI encoding of EVMEventInclusionClaim
+type abiClaim struct {
+	EVMEventInclusionClaim
+	Assumptions abstract_types.AbiHeader
+}
+
+func (c *EVMEventInclusionClaim) AbiEncode() ([]byte, error) {
+	encodeAbi := GetAbi()
+	method := encodeAbi.Methods["encode"]
+	encoded, err := method.Inputs[:1].Pack(abiClaim{EVMEventInclusionClaim: *c, Assumptions: abstract_types.NewAbiHeader(c.Assumptions)})
+	if err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+func AbiDecodeEVMEventInclusionClaim(claimData []byte) (*EVMEventInclusionClaim, error) {
+	abi := GetAbi()
+	claim_abi := abi.Methods["encode"].Inputs[:1]
+	values, err := claim_abi.UnpackValues(claimData)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, errors.Errorf("decoded %d values instead of the claim", len(values))
+	}
+
+	decodedClaim := reflect.ValueOf(values[0])
+
+	// Action
+	action := decodedClaim.FieldByName("Action")
+	decodedTopics := action.FieldByName("Topics").Interface().([][32]uint8)
+	topics := make([]common.Hash, len(decodedTopics))
+	for i, topic := range decodedTopics {
+		topics[i] = common.BytesToHash(topic[:])
+	}
+
+	// Metadata
+	metadata := decodedClaim.FieldByName("Metadata")
+	chainId := metadata.FieldByName("ChainId").Interface().(*big.Int)
+
+	chainConfig, err := evm.ChainConfig(chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct claim
+	claim := EVMEventInclusionClaim{
+		ClaimType:     decodedClaim.FieldByName("ClaimType").String(),
+		TrustBaseSpec: decodedClaim.FieldByName("TrustBaseSpec").String(),
+		Assumptions:   abstract_types.AbiDecodeHeader(decodedClaim.FieldByName("Assumptions"), chainConfig),
+		Action: &EVMEvent{
+			TxIndex:  action.FieldByName("TxIndex").Uint(),
+			LogIndex: action.FieldByName("LogIndex").Uint(),
+			Address:  action.FieldByName("Address").Interface().(common.Address),
+			Topics:   topics,
+			Data:     action.FieldByName("Data").Bytes(),
+		},
+		Metadata: abstract_types.EVMMetadata{
+			ChainId: chainId,
+		},
+	}
+
+	return &claim, nil
+}
+
+func (c *EVMEventInclusionClaimVerificationContext) AbiEncode() ([]byte, error) {
+	encodeAbi := GetAbi()
+	method := encodeAbi.Methods["encode"]
+	encoded, err := method.Inputs[1:].Pack(c)
+	if err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+func AbiDecodeEVMEventInclusionClaimVerificationContext(verificationData []byte) (*EVMEventInclusionClaimVerificationContext, error) {
+	abi := GetAbi()
+	verification_abi := abi.Methods["encode"].Inputs[1:]
+	values, err := verification_abi.UnpackValues(verificationData)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, errors.Errorf("decoded %d values instead of the verification context", len(values))
+	}
+
+	decodedVerData := reflect.ValueOf(values[0])
+	return &EVMEventInclusionClaimVerificationContext{
+		ReceiptProof: decodedVerData.FieldByName("ReceiptProof").Interface().([][]byte),
+	}, nil
+}
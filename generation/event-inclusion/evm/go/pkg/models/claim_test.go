@@ -0,0 +1,72 @@
+package models
+
+import (
+	"base/pkg/abstract_types"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestAbiEncodeClaim(t *testing.T) {
+	header := &types.Header{
+		ParentHash:      common.HexToHash("0x01"),
+		UncleHash:       types.EmptyUncleHash,
+		Root:            common.HexToHash("0x02"),
+		ReceiptHash:     common.HexToHash("0x03"),
+		Difficulty:      big.NewInt(0),
+		Number:          big.NewInt(5000000),
+		GasLimit:        30000000,
+		GasUsed:         21000,
+		Time:            1700000000,
+		Extra:           []byte("geth"),
+		BaseFee:         big.NewInt(1000),
+		WithdrawalsHash: &types.EmptyWithdrawalsHash,
+	}
+	claim := &EVMEventInclusionClaim{
+		ClaimType:     EVMEventInclusionClaimType,
+		TrustBaseSpec: "spec",
+		Assumptions:   abstract_types.NewHeader(header),
+		Action: &EVMEvent{
+			TxIndex:  7,
+			LogIndex: 2,
+			Address:  common.HexToAddress("0x04"),
+			Topics:   []common.Hash{common.HexToHash("0x05"), common.HexToHash("0x06")},
+			Data:     []byte{7, 8},
+		},
+		Metadata: abstract_types.EVMMetadata{ChainId: big.NewInt(11155111)},
+	}
+
+	encoded, err := claim.AbiEncode()
+	if err != nil {
+		t.Fatalf("Failed to encode claim: %v", err)
+	}
+	decoded, err := AbiDecodeEVMEventInclusionClaim(encoded)
+	if err != nil {
+		t.Fatalf("Failed to decode claim: %v", err)
+	}
+	decodedJSON, _ := json.Marshal(decoded)
+	expectedJSON, _ := json.Marshal(claim)
+	if string(decodedJSON) != string(expectedJSON) {
+		t.Fatalf("Decoded claim %s does not match %s", decodedJSON, expectedJSON)
+	}
+	if decoded.Assumptions.VerifyHash() != nil {
+		t.Fatalf("Decoded header does not hash to the block hash")
+	}
+
+	verificationContext := &EVMEventInclusionClaimVerificationContext{ReceiptProof: [][]byte{{1, 2}, {3}}}
+	encoded, err = verificationContext.AbiEncode()
+	if err != nil {
+		t.Fatalf("Failed to encode verification context: %v", err)
+	}
+	decodedContext, err := AbiDecodeEVMEventInclusionClaimVerificationContext(encoded)
+	if err != nil {
+		t.Fatalf("Failed to decode verification context: %v", err)
+	}
+	if !reflect.DeepEqual(decodedContext, verificationContext) {
+		t.Fatalf("Decoded verification context %v does not match %v", decodedContext, verificationContext)
+	}
+}
@@ -0,0 +1,76 @@
+package generation
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+	"context"
+	"generation-transaction-inclusion-evm/pkg/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+)
+
+// Generate generates a transaction inclusion claim for a mined transaction, proving it in the
+// transactions trie of its block
+//
+// Parameters:
+// - ethClient: The eth client instance
+// - txHash: The hash of the signed transaction
+func Generate(ethClient *ethclient.Client, txHash common.Hash) (*models.EVMTransactionInclusionClaim, *models.EVMTransactionInclusionClaimVerificationContext, error) {
+	ctx := context.Background()
+
+	chainId, err := ethClient.ChainID(ctx)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	// Find the block and index of the transaction
+	receipt, err := ethClient.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	block, err := ethClient.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	transactions := block.Transactions()
+	if receipt.TransactionIndex >= uint(len(transactions)) {
+		return nil, nil, errors.Errorf("transaction %d is out of the %d transactions of block %s", receipt.TransactionIndex, len(transactions), receipt.BlockHash)
+	}
+	tx := transactions[receipt.TransactionIndex]
+	if tx.Hash() != txHash {
+		return nil, nil, errors.Errorf("transaction %d of block %s is %s instead of %s", receipt.TransactionIndex, receipt.BlockHash, tx.Hash(), txHash)
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(chainId), tx)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	txRoot, txProof, err := evm.ProveListItem(transactions, int(receipt.TransactionIndex))
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	blockHeader := block.Header()
+	if txRoot != blockHeader.TxHash {
+		return nil, nil, errors.Errorf("transactions of block %s hash to %s instead of its transactions root %s", receipt.BlockHash, txRoot, blockHeader.TxHash)
+	}
+
+	claim := &models.EVMTransactionInclusionClaim{
+		ClaimType:   models.EVMTransactionInclusionClaimType,
+		Assumptions: abstract_types.NewHeader(blockHeader),
+		Action: &models.EVMTransactionInclusion{
+			TxHash:  txHash,
+			TxIndex: uint64(receipt.TransactionIndex),
+			From:    from,
+		},
+		Metadata: abstract_types.EVMMetadata{
+			ChainId: chainId,
+		},
+	}
+	return claim, &models.EVMTransactionInclusionClaimVerificationContext{
+		TransactionProof: txProof,
+	}, nil
+}
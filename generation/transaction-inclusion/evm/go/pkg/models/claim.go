@@ -0,0 +1,147 @@
+package models
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	EVMTransactionInclusionClaimEncodeAbiJSON = `[{"type":"function","name":"encode","inputs":[{"name":"","type":"tuple","internalType":"structEVMTransactionInclusionClaimVerifier.EVMTransactionInclusionClaim","components":[{"name":"claimType","type":"string","internalType":"string"},{"name":"trustBaseSpec","type":"string","internalType":"string"},{"name":"assumptions","type":"tuple","internalType":"structEVMTransactionInclusionClaimVerifier.Header","components":[{"name":"parentHash","type":"bytes32","internalType":"bytes32"},{"name":"uncleHash","type":"bytes32","internalType":"bytes32"},{"name":"coinbase","type":"address","internalType":"address"},{"name":"root","type":"bytes32","internalType":"bytes32"},{"name":"txHash","type":"bytes32","internalType":"bytes32"},{"name":"receiptHash","type":"bytes32","internalType":"bytes32"},{"name":"bloom","type":"bytes","internalType":"bytes"},{"name":"difficulty","type":"uint256","internalType":"uint256"},{"name":"number","type":"uint256","internalType":"uint256"},{"name":"gasLimit","type":"uint256","internalType":"uint256"},{"name":"gasUsed","type":"uint256","internalType":"uint256"},{"name":"time","type":"uint256","internalType":"uint256"},{"name":"extra","type":"bytes","internalType":"bytes"},{"name":"mixDigest","type":"bytes32","internalType":"bytes32"},{"name":"nonce","type":"bytes8","internalType":"bytes8"},{"name":"baseFee","type":"uint256","internalType":"uint256"},{"name":"withdrawalsHash","type":"bytes32","internalType":"bytes32"},{"name":"blobGasUsed","type":"uint256","internalType":"uint256"},{"name":"excessBlobGas","type":"uint256","internalType":"uint256"},{"name":"parentBeaconRoot","type":"bytes32","internalType":"bytes32"},{"name":"requestsHash","type":"bytes32","internalType":"bytes32"},{"name":"hash","type":"bytes32","internalType":"bytes32"}]},{"name":"action","type":"tuple","internalType":"structEVMTransactionInclusionClaimVerifier.EVMTransactionInclusion","components":[{"name":"txHash","type":"bytes32","internalType":"bytes32"},{"name":"txIndex","type":"uint64","internalType":"uint64"},{"name":"from","type":"address","internalType":"address"}]},{"name":"metadata","type":"tuple","internalType":"structEVMTransactionInclusionClaimVerifier.EVMMetadata","components":[{"name":"chainId","type":"uint256","internalType":"uint256"}]}]},{"name":"","type":"tuple","internalType":"structEVMTransactionInclusionClaimVerifier.EVMTransactionInclusionClaimVerificationData","components":[{"name":"transactionProof","type":"bytes[]","internalType":"bytes[]"}]}],"outputs":[{"name":"","type":"bool","internalType":"bool"}],"stateMutability":"pure"}]`
+)
+
+// EVMTransactionInclusionClaimType is the claim type of EVMTransactionInclusionClaim
+const EVMTransactionInclusionClaimType = "EVMTransactionInclusion"
+
+// EVMTransactionInclusion is a signed transaction included in a block
+type EVMTransactionInclusion struct {
+	// TxHash is the hash of the signed transaction
+	TxHash common.Hash `json:"txHash"`
+	// TxIndex is the index of the transaction in the block
+	TxIndex uint64 `json:"txIndex"`
+	// From is the sender recovered from the signature of the transaction
+	From common.Address `json:"from"`
+}
+
+// EVMTransactionInclusionClaim claims that a signed transaction is included at an index of the
+// block of the assumptions
+type EVMTransactionInclusionClaim struct {
+	ClaimType     string                     `json:"type"`
+	TrustBaseSpec string                     `json:"trustBaseSpec"`
+	Assumptions   *abstract_types.Header     `json:"assumptions"`
+	Action        *EVMTransactionInclusion   `json:"action"`
+	Metadata      abstract_types.EVMMetadata `json:"metadata"`
+}
+
+func (c *EVMTransactionInclusionClaim) GetId() (*string, error) {
+	return abstract_types.JSONClaimId(c)
+}
+
+// VerificationContext for EVMTransactionInclusionClaim: the proof of the transaction in the
+// transactions trie of the block, from the root node down
+type EVMTransactionInclusionClaimVerificationContext struct {
+	TransactionProof [][]byte `json:"transactionProof"`
+}
+
+func GetAbi() abi.ABI {
+	contractAbi, err := abi.JSON(strings.NewReader(EVMTransactionInclusionClaimEncodeAbiJSON))
+	if err != nil {
+		panic(err)
+	}
+
+	return contractAbi
+}
+
+// abiClaim is the ABI encoding of EVMTransactionInclusionClaim
+type abiClaim struct {
+	EVMTransactionInclusionClaim
+	Assumptions abstract_types.AbiHeader
+}
+
+func (c *EVMTransactionInclusionClaim) AbiEncode() ([]byte, error) {
+	encodeAbi := GetAbi()
+	method := encodeAbi.Methods["encode"]
+	encoded, err := method.Inputs[:1].Pack(abiClaim{EVMTransactionInclusionClaim: *c, Assumptions: abstract_types.NewAbiHeader(c.Assumptions)})
+	if err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+func AbiDecodeEVMTransactionInclusionClaim(claimData []byte) (*EVMTransactionInclusionClaim, error) {
+	abi := GetAbi()
+	claim_abi := abi.Methods["encode"].Inputs[:1]
+	values, err := claim_abi.UnpackValues(claimData)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, errors.Errorf("decoded %d values instead of the claim", len(values))
+	}
+
+	decodedClaim := reflect.ValueOf(values[0])
+
+	// Action
+	action := decodedClaim.FieldByName("Action")
+	txHash := action.FieldByName("TxHash").Interface().([32]uint8)
+
+	// Metadata
+	metadata := decodedClaim.FieldByName("Metadata")
+	chainId := metadata.FieldByName("ChainId").Interface().(*big.Int)
+
+	chainConfig, err := evm.ChainConfig(chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct claim
+	claim := EVMTransactionInclusionClaim{
+		ClaimType:     decodedClaim.FieldByName("ClaimType").String(),
+		TrustBaseSpec: decodedClaim.FieldByName("TrustBaseSpec").String(),
+		Assumptions:   abstract_types.AbiDecodeHeader(decodedClaim.FieldByName("Assumptions"), chainConfig),
+		Action: &EVMTransactionInclusion{
+			TxHash:  common.BytesToHash(txHash[:]),
+			TxIndex: action.FieldByName("TxIndex").Uint(),
+			From:    action.FieldByName("From").Interface().(common.Address),
+		},
+		Metadata: abstract_types.EVMMetadata{
+			ChainId: chainId,
+		},
+	}
+
+	return &claim, nil
+}
+
+func (c *EVMTransactionInclusionClaimVerificationContext) AbiEncode() ([]byte, error) {
+	encodeAbi := GetAbi()
+	method := encodeAbi.Methods["encode"]
+	encoded, err := method.Inputs[1:].Pack(c)
+	if err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+func AbiDecodeEVMTransactionInclusionClaimVerificationContext(verificationData []byte) (*EVMTransactionInclusionClaimVerificationContext, error) {
+	abi := GetAbi()
+	verification_abi := abi.Methods["encode"].Inputs[1:]
+	values, err := verification_abi.UnpackValues(verificationData)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, errors.Errorf("decoded %d values instead of the verification context", len(values))
+	}
+
+	decodedVerData := reflect.ValueOf(values[0])
+	return &EVMTransactionInclusionClaimVerificationContext{
+		TransactionProof: decodedVerData.FieldByName("TransactionProof").Interface().([][]byte),
+	}, nil
+}
@@ -5,10 +5,8 @@ import (
 	basemodels "base/pkg/models"
 	"context"
 	"generation-view-fn-evm/pkg/models"
-	"log"
 	"math/big"
 	"strings"
-	"time"
 
 	"base/pkg/ethrpc"
 
@@ -27,6 +25,18 @@ import (
 // - ethClient: The eth client instance
 // - event: The event of the bridge transaction
 func Generate(ethClient *ethclient.Client, event types.Log, sourceUslContractAddress common.Address, sourceUslContractABIJSON string) (*models.EVMViewFnClaim, *models.EVMViewFnClaimVerificationContext, error) {
+	return GenerateWithAncestors(ethClient, event, sourceUslContractAddress, sourceUslContractABIJSON, 0)
+}
+
+// GenerateWithAncestors generates a view function claim whose verification context holds the
+// headers of the given number of blocks before the claimed block, for calls using BLOCKHASH
+func GenerateWithAncestors(ethClient *ethclient.Client, event types.Log, sourceUslContractAddress common.Address, sourceUslContractABIJSON string, ancestorCount int) (*models.EVMViewFnClaim, *models.EVMViewFnClaimVerificationContext, error) {
+	return GenerateWithCache(ethClient, event, sourceUslContractAddress, sourceUslContractABIJSON, ancestorCount, nil)
+}
+
+// GenerateWithCache generates a view function claim like GenerateWithAncestors, reusing the account
+// proofs and code held by the cache and adding the fetched ones to it
+func GenerateWithCache(ethClient *ethclient.Client, event types.Log, sourceUslContractAddress common.Address, sourceUslContractABIJSON string, ancestorCount int, cache *ethrpc.Cache) (*models.EVMViewFnClaim, *models.EVMViewFnClaimVerificationContext, error) {
 	ctx := context.Background()
 
 	blockNumberBigInt := new(big.Int).SetUint64(event.BlockNumber)
@@ -105,44 +115,22 @@ func Generate(ethClient *ethclient.Client, event types.Log, sourceUslContractAdd
 	}
 	accessList = append(accessList, basemodels.EVMAccessList{Address: eventTxFrom, StorageKeys: []string{}})
 
-	// Get the account proofs with retry logic
-	var account []abstract_types.Account
-	waitTime := time.Duration(1) * time.Second
-	maxRetries := 10
-	for i := range maxRetries {
-		account, err = ethrpc.GetProofsByAccessList(ethClient.Client(), ctx, accessList, blockNumberBigInt)
-		if err == nil {
-			break
-		}
-		log.Printf("GetProofsByAccessList failed, retrying... (attempt %d/%d)", i+1, maxRetries)
-		time.Sleep(waitTime)
-	}
-
+	// Get the account proofs at the block as a multiproof, the failed calls are retried
+	multiProof, err := ethrpc.GetMultiProofByAccessListAtHash(ethClient.Client(), ctx, accessList, block.Hash(), cache)
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
 	}
 
 	blockHeader := block.Header()
 
+	ancestors, err := GetAncestorHeaders(ctx, ethClient, blockHeader, ancestorCount)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
 	return &models.EVMViewFnClaim{
 			ClaimType: "EVMViewFn",
-			Assumptions: &abstract_types.Header{
-				ParentHash:  blockHeader.ParentHash,
-				UncleHash:   blockHeader.UncleHash,
-				Coinbase:    blockHeader.Coinbase,
-				Root:        blockHeader.Root,
-				TxHash:      blockHeader.TxHash,
-				ReceiptHash: blockHeader.ReceiptHash,
-				Bloom:       blockHeader.Bloom[:],
-				Difficulty:  blockHeader.Difficulty,
-				Number:      blockHeader.Number,
-				GasLimit:    big.NewInt(int64(blockHeader.GasLimit)),
-				GasUsed:     big.NewInt(int64(blockHeader.GasUsed)),
-				Time:        big.NewInt(int64(blockHeader.Time)),
-				Extra:       blockHeader.Extra,
-				MixDigest:   blockHeader.MixDigest,
-				Nonce:       blockHeader.Nonce,
-			},
+			Assumptions: abstract_types.NewHeader(blockHeader),
 			Action: &abstract_types.EVMCall{
 				From:  eventTxFrom,
 				To:    sourceUslContractAddress,
@@ -153,6 +141,99 @@ func Generate(ethClient *ethclient.Client, event types.Log, sourceUslContractAdd
 				ChainId: chainId,
 			},
 		}, &models.EVMViewFnClaimVerificationContext{
-			Accounts: account,
+			Accounts:  multiProof.Accounts,
+			Nodes:     multiProof.Nodes,
+			Ancestors: ancestors,
 		}, nil
 }
+
+// GenerateWithOverrides generates a claim of a view function call at a block under an eth_call
+// state and block override set. The verification context proves the state touched by the call,
+// except the storage slots set by the overrides.
+//
+// Parameters:
+// - ethClient: The eth client instance, of a node serving debug_traceCall
+// - call: The view function call
+// - blockNumber: The number of the block to call at
+// - stateOverrides: The accounts overridden in the state, nil for none
+// - blockOverrides: The fields overridden in the block context, nil for none
+func GenerateWithOverrides(ethClient *ethclient.Client, call *abstract_types.EVMCall, blockNumber *big.Int, stateOverrides abstract_types.StateOverride, blockOverrides *abstract_types.BlockOverrides) (*models.EVMViewFnOverrideClaim, *models.EVMViewFnClaimVerificationContext, error) {
+	ctx := context.Background()
+
+	chainId, err := ethClient.ChainID(ctx)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	blockHeader, err := ethClient.HeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	tx := map[string]interface{}{
+		"from":  call.From.Hex(),
+		"to":    call.To.Hex(),
+		"input": hexutil.Encode(call.Input),
+	}
+	result, err := ethrpc.CallWithOverrides(ethClient.Client(), ctx, tx, blockNumber, stateOverrides, blockOverrides)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	// Prove the touched state which is not replaced by the overrides
+	accessList, err := ethrpc.CreateAccessListWithOverrides(ethClient.Client(), ctx, tx, blockNumber, stateOverrides, blockOverrides)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	for i, access := range accessList {
+		override, ok := stateOverrides[access.Address]
+		if !ok {
+			continue
+		}
+		storageKeys := []string{}
+		for _, storageKey := range access.StorageKeys {
+			_, overridden := override.StateDiff[common.HexToHash(storageKey)]
+			if override.State == nil && !overridden {
+				storageKeys = append(storageKeys, storageKey)
+			}
+		}
+		accessList[i].StorageKeys = storageKeys
+	}
+
+	multiProof, err := ethrpc.GetMultiProofByAccessList(ethClient.Client(), ctx, accessList, blockNumber)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	claim := &models.EVMViewFnOverrideClaim{
+		ClaimType: models.EVMViewFnOverrideClaimType,
+		Assumptions: &models.EVMViewFnOverrideAssumptions{
+			Header:         abstract_types.NewHeader(blockHeader),
+			StateOverrides: stateOverrides,
+			BlockOverrides: blockOverrides,
+		},
+		Action: call,
+		Result: result,
+		Metadata: abstract_types.EVMMetadata{
+			ChainId: chainId,
+		},
+	}
+	return claim, &models.EVMViewFnClaimVerificationContext{
+		Accounts: multiProof.Accounts,
+		Nodes:    multiProof.Nodes,
+	}, nil
+}
+
+// GetAncestorHeaders fetches the headers of up to count blocks before a block, from its parent down
+func GetAncestorHeaders(ctx context.Context, ethClient *ethclient.Client, header *types.Header, count int) ([]*types.Header, error) {
+	ancestors := []*types.Header{}
+	parentHash := header.ParentHash
+	for number := header.Number.Uint64(); number > 0 && len(ancestors) < count; number-- {
+		ancestor, err := ethClient.HeaderByHash(ctx, parentHash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the header of block %d", number-1)
+		}
+		ancestors = append(ancestors, ancestor)
+		parentHash = ancestor.ParentHash
+	}
+	return ancestors, nil
+}
@@ -10,14 +10,17 @@ import (
 	"strings"
 
 	"base/pkg/abstract_types"
+	"base/pkg/evm"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 	"golang.org/x/crypto/sha3"
 )
 
 const (
-	EVMViewFnClaimEncodeAbiJSON = `[{"type":"function","name":"encode","inputs":[{"name":"","type":"tuple","internalType":"structEVMViewFnClaimVerifier.EVMViewFnClaim","components":[{"name":"claimType","type":"string","internalType":"string"},{"name":"trustBaseSpec","type":"string","internalType":"string"},{"name":"assumptions","type":"tuple","internalType":"structEVMViewFnClaimVerifier.Header","components":[{"name":"parentHash","type":"bytes32","internalType":"bytes32"},{"name":"uncleHash","type":"bytes32","internalType":"bytes32"},{"name":"coinbase","type":"address","internalType":"address"},{"name":"root","type":"bytes32","internalType":"bytes32"},{"name":"txHash","type":"bytes32","internalType":"bytes32"},{"name":"receiptHash","type":"bytes32","internalType":"bytes32"},{"name":"bloom","type":"bytes","internalType":"bytes"},{"name":"difficulty","type":"uint256","internalType":"uint256"},{"name":"number","type":"uint256","internalType":"uint256"},{"name":"gasLimit","type":"uint256","internalType":"uint256"},{"name":"gasUsed","type":"uint256","internalType":"uint256"},{"name":"time","type":"uint256","internalType":"uint256"},{"name":"extra","type":"bytes","internalType":"bytes"},{"name":"mixDigest","type":"bytes32","internalType":"bytes32"},{"name":"nonce","type":"bytes8","internalType":"bytes8"}]},{"name":"action","type":"tuple","internalType":"structEVMViewFnClaimVerifier.EVMCall","components":[{"name":"from","type":"address","internalType":"address"},{"name":"to","type":"address","internalType":"address"},{"name":"input","type":"bytes","internalType":"bytes"}]},{"name":"result","type":"bytes","internalType":"bytes"},{"name":"metadata","type":"tuple","internalType":"structEVMViewFnClaimVerifier.EVMMetadata","components":[{"name":"chainId","type":"uint256","internalType":"uint256"}]}]},{"name":"","type":"tuple","internalType":"structEVMViewFnClaimVerifier.EVMViewFnClaimVerificationData","components":[{"name":"accounts","type":"tuple[]","internalType":"structEVMViewFnClaimVerifier.Account[]","components":[{"name":"proof","type":"tuple","internalType":"structEVMViewFnClaimVerifier.AccountProof","components":[{"name":"addr","type":"address","internalType":"address"},{"name":"accountProof","type":"bytes[]","internalType":"bytes[]"},{"name":"balance","type":"uint256","internalType":"uint256"},{"name":"codeHash","type":"bytes32","internalType":"bytes32"},{"name":"nonce","type":"uint256","internalType":"uint256"},{"name":"storageHash","type":"bytes32","internalType":"bytes32"},{"name":"storageProof","type":"tuple[]","internalType":"structEVMViewFnClaimVerifier.StorageProof[]","components":[{"name":"key","type":"bytes32","internalType":"bytes32"},{"name":"value","type":"bytes32","internalType":"bytes32"},{"name":"proof","type":"bytes[]","internalType":"bytes[]"}]}]},{"name":"code","type":"bytes","internalType":"bytes"}]}]}],"outputs":[{"name":"","type":"bool","internalType":"bool"}],"stateMutability":"pure"}]`
+	EVMViewFnClaimEncodeAbiJSON = `[{"type":"function","name":"encode","inputs":[{"name":"","type":"tuple","internalType":"structEVMViewFnClaimVerifier.EVMViewFnClaim","components":[{"name":"claimType","type":"string","internalType":"string"},{"name":"trustBaseSpec","type":"string","internalType":"string"},{"name":"assumptions","type":"tuple","internalType":"structEVMViewFnClaimVerifier.Header","components":[{"name":"parentHash","type":"bytes32","internalType":"bytes32"},{"name":"uncleHash","type":"bytes32","internalType":"bytes32"},{"name":"coinbase","type":"address","internalType":"address"},{"name":"root","type":"bytes32","internalType":"bytes32"},{"name":"txHash","type":"bytes32","internalType":"bytes32"},{"name":"receiptHash","type":"bytes32","internalType":"bytes32"},{"name":"bloom","type":"bytes","internalType":"bytes"},{"name":"difficulty","type":"uint256","internalType":"uint256"},{"name":"number","type":"uint256","internalType":"uint256"},{"name":"gasLimit","type":"uint256","internalType":"uint256"},{"name":"gasUsed","type":"uint256","internalType":"uint256"},{"name":"time","type":"uint256","internalType":"uint256"},{"name":"extra","type":"bytes","internalType":"bytes"},{"name":"mixDigest","type":"bytes32","internalType":"bytes32"},{"name":"nonce","type":"bytes8","internalType":"bytes8"},{"name":"baseFee","type":"uint256","internalType":"uint256"},{"name":"withdrawalsHash","type":"bytes32","internalType":"bytes32"},{"name":"blobGasUsed","type":"uint256","internalType":"uint256"},{"name":"excessBlobGas","type":"uint256","internalType":"uint256"},{"name":"parentBeaconRoot","type":"bytes32","internalType":"bytes32"},{"name":"requestsHash","type":"bytes32","internalType":"bytes32"},{"name":"hash","type":"bytes32","internalType":"bytes32"}]},{"name":"action","type":"tuple","internalType":"structEVMViewFnClaimVerifier.EVMCall","components":[{"name":"from","type":"address","internalType":"address"},{"name":"to","type":"address","internalType":"address"},{"name":"input","type":"bytes","internalType":"bytes"}]},{"name":"result","type":"bytes","internalType":"bytes"},{"name":"metadata","type":"tuple","internalType":"structEVMViewFnClaimVerifier.EVMMetadata","components":[{"name":"chainId","type":"uint256","internalType":"uint256"}]}]},{"name":"","type":"tuple","internalType":"structEVMViewFnClaimVerifier.EVMViewFnClaimVerificationData","components":[{"name":"accounts","type":"tuple[]","internalType":"structEVMViewFnClaimVerifier.Account[]","components":[{"name":"proof","type":"tuple","internalType":"structEVMViewFnClaimVerifier.AccountProof","components":[{"name":"addr","type":"address","internalType":"address"},{"name":"accountProof","type":"bytes[]","internalType":"bytes[]"},{"name":"balance","type":"uint256","internalType":"uint256"},{"name":"codeHash","type":"bytes32","internalType":"bytes32"},{"name":"nonce","type":"uint256","internalType":"uint256"},{"name":"storageHash","type":"bytes32","internalType":"bytes32"},{"name":"storageProof","type":"tuple[]","internalType":"structEVMViewFnClaimVerifier.StorageProof[]","components":[{"name":"key","type":"bytes32","internalType":"bytes32"},{"name":"value","type":"bytes32","internalType":"bytes32"},{"name":"proof","type":"bytes[]","internalType":"bytes[]"}]}]},{"name":"code","type":"bytes","internalType":"bytes"}]},{"name":"nodes","type":"bytes[]","internalType":"bytes[]"},{"name":"ancestors","type":"bytes[]","internalType":"bytes[]"}]}],"outputs":[{"name":"","type":"bool","internalType":"bool"}],"stateMutability":"pure"}]`
 )
 
 type EVMViewFnClaim struct {
@@ -46,6 +49,12 @@ func (c *EVMViewFnClaim) GetId() (*string, error) {
 // VerificationContext for EVMViewFnClaim: the account proofs of the pre-state
 type EVMViewFnClaimVerificationContext struct {
 	Accounts []abstract_types.Account `json:"accounts"`
+	// Nodes are the trie nodes shared by the account proofs when they are encoded as a multiproof,
+	// see abstract_types.MultiProof
+	Nodes [][]byte `json:"nodes,omitempty"`
+	// Ancestors are the headers of the last blocks before the assumptions, from the parent down,
+	// serving BLOCKHASH to the call. They are optional, and RLP encoded in the ABI encoding.
+	Ancestors []*types.Header `json:"ancestors,omitempty"`
 }
 
 func GetAbi() abi.ABI {
@@ -57,6 +66,12 @@ func GetAbi() abi.ABI {
 	return contractAbi
 }
 
+// abiClaim is the ABI encoding of EVMViewFnClaim
+type abiClaim struct {
+	EVMViewFnClaim
+	Assumptions abstract_types.AbiHeader
+}
+
 func (c *EVMViewFnClaim) AbiEncode() ([]byte, error) {
 	encodeAbi, err := abi.JSON(strings.NewReader(EVMViewFnClaimEncodeAbiJSON))
 	if err != nil {
@@ -64,7 +79,7 @@ func (c *EVMViewFnClaim) AbiEncode() ([]byte, error) {
 	}
 
 	method := encodeAbi.Methods["encode"]
-	encoded, err := method.Inputs[:1].Pack(c)
+	encoded, err := method.Inputs[:1].Pack(abiClaim{EVMViewFnClaim: *c, Assumptions: abstract_types.NewAbiHeader(c.Assumptions)})
 	if err != nil {
 		return nil, err
 	}
@@ -84,16 +99,6 @@ func AbiDecodeEVMViewFnClaim(claimData []byte) (*EVMViewFnClaim, error) {
 
 	decodedClaim := reflect.ValueOf(values[0])
 
-	// Assumptions
-	assumptions := decodedClaim.FieldByName("Assumptions")
-	parentHash := assumptions.FieldByName("ParentHash").Interface().([32]uint8)
-	uncleHash := assumptions.FieldByName("UncleHash").Interface().([32]uint8)
-	nonce := assumptions.FieldByName("Nonce").Interface().([8]uint8)
-	root := assumptions.FieldByName("Root").Interface().([32]uint8)
-	miner := assumptions.FieldByName("Coinbase").Interface().(common.Address)
-	txHash := assumptions.FieldByName("TxHash").Interface().([32]uint8)
-	receiptHash := assumptions.FieldByName("ReceiptHash").Interface().([32]uint8)
-
 	// Action
 	action := decodedClaim.FieldByName("Action")
 	from := action.FieldByName("From").Interface().(common.Address)
@@ -107,27 +112,16 @@ func AbiDecodeEVMViewFnClaim(claimData []byte) (*EVMViewFnClaim, error) {
 	metadata := decodedClaim.FieldByName("Metadata")
 	chainId := metadata.FieldByName("ChainId").Interface().(*big.Int)
 
+	chainConfig, err := evm.ChainConfig(chainId)
+	if err != nil {
+		return nil, err
+	}
+
 	// Construct claim
 	claim := EVMViewFnClaim{
 		ClaimType:     "EVMViewFn",
 		TrustBaseSpec: decodedClaim.FieldByName("TrustBaseSpec").String(),
-		Assumptions: &abstract_types.Header{
-			ParentHash:  common.BytesToHash(parentHash[:]),
-			UncleHash:   common.BytesToHash(uncleHash[:]),
-			Coinbase:    miner,
-			Root:        common.BytesToHash(root[:]),
-			TxHash:      common.BytesToHash(txHash[:]),
-			ReceiptHash: common.BytesToHash(receiptHash[:]),
-			Bloom:       assumptions.FieldByName("Bloom").Bytes(),
-			Difficulty:  assumptions.FieldByName("Difficulty").Interface().(*big.Int),
-			Number:      assumptions.FieldByName("Number").Interface().(*big.Int),
-			GasLimit:    assumptions.FieldByName("GasLimit").Interface().(*big.Int),
-			GasUsed:     assumptions.FieldByName("GasUsed").Interface().(*big.Int),
-			Time:        assumptions.FieldByName("Time").Interface().(*big.Int),
-			Extra:       assumptions.FieldByName("Extra").Bytes(),
-			MixDigest:   common.HexToHash(assumptions.FieldByName("MixDigest").String()),
-			Nonce:       nonce,
-		},
+		Assumptions:   abstract_types.AbiDecodeHeader(decodedClaim.FieldByName("Assumptions"), chainConfig),
 		Action: &abstract_types.EVMCall{
 			From:  from,
 			To:    to,
@@ -141,14 +135,31 @@ func AbiDecodeEVMViewFnClaim(claimData []byte) (*EVMViewFnClaim, error) {
 
 	return &claim, nil
 }
+
+// abiVerificationContext is the ABI encoding of EVMViewFnClaimVerificationContext
+type abiVerificationContext struct {
+	Accounts  []abstract_types.Account
+	Nodes     [][]byte
+	Ancestors [][]byte
+}
+
 func (c *EVMViewFnClaimVerificationContext) AbiEncode() ([]byte, error) {
 	encodeAbi, err := abi.JSON(strings.NewReader(EVMViewFnClaimEncodeAbiJSON))
 	if err != nil {
 		panic(err)
 	}
 
+	verificationContext := abiVerificationContext{Accounts: c.Accounts, Nodes: c.Nodes}
+	for _, ancestor := range c.Ancestors {
+		encodedAncestor, err := rlp.EncodeToBytes(ancestor)
+		if err != nil {
+			return nil, err
+		}
+		verificationContext.Ancestors = append(verificationContext.Ancestors, encodedAncestor)
+	}
+
 	method := encodeAbi.Methods["encode"]
-	encoded, err := method.Inputs[1:].Pack(c) // Slice the second input argument and pack it
+	encoded, err := method.Inputs[1:].Pack(verificationContext) // Slice the second input argument and pack it
 	if err != nil {
 		return nil, err
 	}
@@ -211,5 +222,19 @@ func AbiDecodeEVMViewFnClaimVerificationContext(verificationData []byte) (*EVMVi
 		})
 	}
 
-	return &EVMViewFnClaimVerificationContext{Accounts: accounts}, nil
+	ancestors := []*types.Header{}
+	for _, encodedAncestor := range decodedVerData.FieldByName("Ancestors").Interface().([][]byte) {
+		var ancestor types.Header
+		err := rlp.DecodeBytes(encodedAncestor, &ancestor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode ancestor header: %w", err)
+		}
+		ancestors = append(ancestors, &ancestor)
+	}
+
+	return &EVMViewFnClaimVerificationContext{
+		Accounts:  accounts,
+		Nodes:     decodedVerData.FieldByName("Nodes").Interface().([][]byte),
+		Ancestors: ancestors,
+	}, nil
 }
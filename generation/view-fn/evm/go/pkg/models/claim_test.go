@@ -0,0 +1,121 @@
+package models
+
+import (
+	"base/pkg/abstract_types"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func newTestHeader(number int64, parentHash common.Hash) *types.Header {
+	blobGasUsed := uint64(131072)
+	excessBlobGas := uint64(0)
+	return &types.Header{
+		ParentHash:       parentHash,
+		UncleHash:        types.EmptyUncleHash,
+		Root:             common.HexToHash("0x02"),
+		Difficulty:       big.NewInt(0),
+		Number:           big.NewInt(number),
+		GasLimit:         35964810,
+		GasUsed:          7830794,
+		Time:             1744256940,
+		Extra:            []byte("geth"),
+		MixDigest:        common.HexToHash("0x03"),
+		BaseFee:          big.NewInt(1000),
+		WithdrawalsHash:  &types.EmptyWithdrawalsHash,
+		BlobGasUsed:      &blobGasUsed,
+		ExcessBlobGas:    &excessBlobGas,
+		ParentBeaconRoot: &common.Hash{4},
+		RequestsHash:     &types.EmptyRequestsHash,
+	}
+}
+
+func TestAbiEncodeClaim(t *testing.T) {
+	header := newTestHeader(8088302, common.HexToHash("0x01"))
+	claim := &EVMViewFnClaim{
+		ClaimType:   "EVMViewFn",
+		Assumptions: abstract_types.NewHeader(header),
+		Action: &abstract_types.EVMCall{
+			From:  common.HexToAddress("0x05"),
+			To:    common.HexToAddress("0x06"),
+			Input: []byte{1, 2, 3},
+		},
+		Result:   []byte{4, 5, 6},
+		Metadata: abstract_types.EVMMetadata{ChainId: big.NewInt(11155111)},
+	}
+
+	encoded, err := claim.AbiEncode()
+	if err != nil {
+		t.Fatalf("Failed to encode claim: %v", err)
+	}
+	decoded, err := AbiDecodeEVMViewFnClaim(encoded)
+	if err != nil {
+		t.Fatalf("Failed to decode claim: %v", err)
+	}
+	decodedJSON, _ := json.Marshal(decoded.Assumptions)
+	expectedJSON, _ := json.Marshal(claim.Assumptions)
+	if string(decodedJSON) != string(expectedJSON) {
+		t.Fatalf("Decoded header %s does not match %s", decodedJSON, expectedJSON)
+	}
+	if decoded.Assumptions.ToGethHeader().Hash() != header.Hash() {
+		t.Fatalf("Decoded header does not hash to the block hash")
+	}
+}
+
+func TestAbiEncodeVerificationContext(t *testing.T) {
+	grandparent := newTestHeader(8088300, common.HexToHash("0x01"))
+	parent := newTestHeader(8088301, grandparent.Hash())
+	verificationContext := &EVMViewFnClaimVerificationContext{
+		Accounts: []abstract_types.Account{{
+			Proof: abstract_types.AccountProof{
+				Addr:         common.HexToAddress("0x06"),
+				AccountProof: [][]byte{{1, 2}},
+				Balance:      big.NewInt(7),
+				CodeHash:     common.HexToHash("0x08"),
+				Nonce:        big.NewInt(9),
+				StorageHash:  common.HexToHash("0x0a"),
+				StorageProof: []abstract_types.StorageProof{{
+					Key:   common.HexToHash("0x0b"),
+					Value: common.HexToHash("0x0c"),
+					Proof: [][]byte{{3}},
+				}},
+			},
+			Code: []byte{0x60, 0x00},
+		}},
+		Nodes:     [][]byte{{4, 5}},
+		Ancestors: []*types.Header{parent, grandparent},
+	}
+
+	encoded, err := verificationContext.AbiEncode()
+	if err != nil {
+		t.Fatalf("Failed to encode verification context: %v", err)
+	}
+	decoded, err := AbiDecodeEVMViewFnClaimVerificationContext(encoded)
+	if err != nil {
+		t.Fatalf("Failed to decode verification context: %v", err)
+	}
+	decodedJSON, _ := json.Marshal(decoded)
+	expectedJSON, _ := json.Marshal(verificationContext)
+	if string(decodedJSON) != string(expectedJSON) {
+		t.Fatalf("Decoded verification context %s does not match %s", decodedJSON, expectedJSON)
+	}
+	for i, ancestor := range decoded.Ancestors {
+		if ancestor.Hash() != verificationContext.Ancestors[i].Hash() {
+			t.Fatalf("Decoded ancestor %d does not hash to its block hash", i)
+		}
+	}
+
+	// Without ancestors, the verification context still round-trips
+	verificationContext.Ancestors = nil
+	encoded, err = verificationContext.AbiEncode()
+	if err != nil {
+		t.Fatalf("Failed to encode verification context: %v", err)
+	}
+	decoded, err = AbiDecodeEVMViewFnClaimVerificationContext(encoded)
+	if err != nil || len(decoded.Ancestors) != 0 {
+		t.Fatalf("Expected no ancestors, got %v, %v", decoded, err)
+	}
+}
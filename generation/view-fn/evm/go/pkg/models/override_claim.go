@@ -0,0 +1,31 @@
+package models
+
+import (
+	"base/pkg/abstract_types"
+)
+
+// EVMViewFnOverrideClaimType is the claim type of EVMViewFnOverrideClaim
+const EVMViewFnOverrideClaimType = "EVMViewFnOverride"
+
+// EVMViewFnOverrideAssumptions are the header of the claimed block and the overrides applied to
+// its state and block context before the call
+type EVMViewFnOverrideAssumptions struct {
+	Header         *abstract_types.Header         `json:"header"`
+	StateOverrides abstract_types.StateOverride   `json:"stateOverrides,omitempty"`
+	BlockOverrides *abstract_types.BlockOverrides `json:"blockOverrides,omitempty"`
+}
+
+// EVMViewFnOverrideClaim claims the result of a view function call under an eth_call override set.
+// It is verified with an EVMViewFnClaimVerificationContext, and is JSON encoded only.
+type EVMViewFnOverrideClaim struct {
+	ClaimType     string                        `json:"type"`
+	TrustBaseSpec string                        `json:"trustBaseSpec"`
+	Assumptions   *EVMViewFnOverrideAssumptions `json:"assumptions"`
+	Action        *abstract_types.EVMCall       `json:"action"`
+	Result        []byte                        `json:"result"`
+	Metadata      abstract_types.EVMMetadata    `json:"metadata"`
+}
+
+func (c *EVMViewFnOverrideClaim) GetId() (*string, error) {
+	return abstract_types.JSONClaimId(c)
+}
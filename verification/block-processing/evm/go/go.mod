@@ -3,6 +3,7 @@ module verification-block-processing-evm
 go 1.23.1
 
 require (
+	base v0.1.0
 	generation-block-processing-evm v0.1.0
 	github.com/ethereum/go-ethereum v1.15.10
 	github.com/pkg/errors v0.9.1
@@ -13,7 +14,6 @@ replace base => ../../../../base/go
 replace generation-block-processing-evm => ../../../../generation/block-processing/evm/go
 
 require (
-	base v0.1.0 // indirect
 	github.com/Microsoft/go-winio v0.6.2 // indirect
 	github.com/StackExchange/wmi v1.2.1 // indirect
 	github.com/VictoriaMetrics/fastcache v1.12.2 // indirect
@@ -27,9 +27,11 @@ require (
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
 	github.com/ethereum/c-kzg-4844 v1.0.0 // indirect
 	github.com/ethereum/go-verkle v0.2.2 // indirect
+	github.com/fsnotify/fsnotify v1.6.0 // indirect
 	github.com/go-ole/go-ole v1.3.0 // indirect
 	github.com/gofrs/flock v0.8.1 // indirect
 	github.com/golang/snappy v0.0.5-0.20220116011046-fa5810519dcb // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/holiman/bloomfilter/v2 v2.0.3 // indirect
 	github.com/holiman/uint256 v1.3.2 // indirect
@@ -1,13 +1,13 @@
 package verification
 
 import (
+	"base/pkg/evm"
 	"generation-block-processing-evm/pkg/models"
 
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
-	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/pkg/errors"
 )
@@ -18,9 +18,15 @@ import (
 // - claim: The block processing claim to verify
 // - verificationContext: The verification context for the claim
 func Verify(claim *models.EVMBlockProcessingClaim, verificationContext *models.EVMBlockProcessingClaimVerificationContext) error {
+	// Execute the block under the fork schedule of the claim's chain
+	chainConfig, err := evm.ChainConfig(claim.Metadata.ChainId)
+	if err != nil {
+		return err
+	}
+
 	// Deserialize the witness from bytes with RLP
 	var witness *stateless.Witness
-	err := rlp.DecodeBytes(verificationContext.Witness, &witness)
+	err = rlp.DecodeBytes(verificationContext.Witness, &witness)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -38,7 +44,7 @@ func Verify(claim *models.EVMBlockProcessingClaim, verificationContext *models.E
 	}
 
 	// Execute the block and get the post-state root and receipt root
-	postStateRoot, postReceiptRoot, err := core.ExecuteStateless(params.MainnetChainConfig, vm.Config{}, block, witness)
+	postStateRoot, postReceiptRoot, err := core.ExecuteStateless(chainConfig, vm.Config{}, block, witness)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -55,3 +61,56 @@ func Verify(claim *models.EVMBlockProcessingClaim, verificationContext *models.E
 
 	return nil
 }
+
+// Diagnose re-runs a block processing claim which failed verification with tracing hooks, and
+// reports the claimed and computed roots, the first receipt diverging from the expected receipts,
+// the state touched but missing from the witness and a summary of the opcodes run
+//
+// Parameters:
+// - claim: The block processing claim which failed verification
+// - verificationContext: The verification context for the claim
+// - expectedReceipts: The receipts of the block, nil when unknown
+// - verificationErr: The error of the verification
+func Diagnose(claim *models.EVMBlockProcessingClaim, verificationContext *models.EVMBlockProcessingClaimVerificationContext, expectedReceipts []*types.Receipt, verificationErr error) *evm.Diagnostics {
+	diagnostics := &evm.Diagnostics{}
+	if verificationErr != nil {
+		diagnostics.Error = verificationErr.Error()
+	}
+
+	chainConfig, err := evm.ChainConfig(claim.Metadata.ChainId)
+	if err != nil {
+		diagnostics.ExecutionError = err.Error()
+		return diagnostics
+	}
+	var witness *stateless.Witness
+	err = rlp.DecodeBytes(verificationContext.Witness, &witness)
+	if err != nil {
+		diagnostics.ExecutionError = err.Error()
+		return diagnostics
+	}
+	var block *types.Block
+	err = rlp.DecodeBytes(claim.Result, &block)
+	if err != nil {
+		diagnostics.ExecutionError = err.Error()
+		return diagnostics
+	}
+	expectedStateRoot := block.Header().Root
+	expectedReceiptsRoot := block.Header().ReceiptHash
+	diagnostics.ExpectedStateRoot = &expectedStateRoot
+	diagnostics.ExpectedReceiptsRoot = &expectedReceiptsRoot
+
+	tracer := evm.NewDiagnosticTracer()
+	postStateRoot, postReceiptRoot, err := core.ExecuteStateless(chainConfig, vm.Config{Tracer: tracer.Hooks()}, block, witness)
+	if err != nil {
+		diagnostics.ExecutionError = err.Error()
+	} else {
+		diagnostics.ActualStateRoot = &postStateRoot
+		diagnostics.ActualReceiptsRoot = &postReceiptRoot
+	}
+	if expectedReceipts != nil {
+		diagnostics.FirstDivergingReceipt = evm.CompareReceipts(expectedReceipts, tracer.Receipts())
+	}
+	diagnostics.Trace = tracer.Summary()
+	diagnostics.MissingAccounts, diagnostics.MissingStorage = tracer.MissingState(witness.Root(), witness.MakeHashDB())
+	return diagnostics
+}
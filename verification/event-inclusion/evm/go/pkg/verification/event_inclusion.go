@@ -0,0 +1,60 @@
+package verification
+
+import (
+	"base/pkg/evm"
+	"bytes"
+	"generation-event-inclusion-evm/pkg/models"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Verify validates an event inclusion claim: the receipt of the transaction is proven against the
+// receipts root of the header of the assumptions, and holds the claimed log
+func Verify(claim *models.EVMEventInclusionClaim, verificationContext *models.EVMEventInclusionClaimVerificationContext) error {
+	if claim.ClaimType != models.EVMEventInclusionClaimType {
+		return errors.Errorf("claim type %q is not %q", claim.ClaimType, models.EVMEventInclusionClaimType)
+	}
+
+	// Check the assumptions are the header of the claimed block
+	header := claim.Assumptions
+	err := header.VerifyHash()
+	if err != nil {
+		return err
+	}
+
+	event := claim.Action
+	receiptBytes, err := evm.VerifyListItemProof(header.ReceiptHash, event.TxIndex, verificationContext.ReceiptProof)
+	if err != nil {
+		return errors.Wrapf(err, "receipt of transaction %d", event.TxIndex)
+	}
+	receipt := new(types.Receipt)
+	err = receipt.UnmarshalBinary(receiptBytes)
+	if err != nil {
+		return errors.Wrapf(err, "invalid encoding of the receipt of transaction %d", event.TxIndex)
+	}
+
+	if event.LogIndex >= uint64(len(receipt.Logs)) {
+		return errors.Errorf("log %d is out of the %d logs of transaction %d", event.LogIndex, len(receipt.Logs), event.TxIndex)
+	}
+	return compareLog(receipt.Logs[event.LogIndex], event)
+}
+
+// compareLog compares a proven log with the claimed event
+func compareLog(log *types.Log, event *models.EVMEvent) error {
+	if log.Address != event.Address {
+		return errors.Errorf("log is emitted by %s instead of %s", log.Address, event.Address)
+	}
+	if len(log.Topics) != len(event.Topics) {
+		return errors.Errorf("log has %d topics instead of %d", len(log.Topics), len(event.Topics))
+	}
+	for i, topic := range log.Topics {
+		if topic != event.Topics[i] {
+			return errors.Errorf("topic %d of the log is %s instead of %s", i, topic, event.Topics[i])
+		}
+	}
+	if !bytes.Equal(log.Data, event.Data) {
+		return errors.New("log data does not match the claimed data")
+	}
+	return nil
+}
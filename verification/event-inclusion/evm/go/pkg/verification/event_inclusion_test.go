@@ -0,0 +1,100 @@
+package verification
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+	"generation-event-inclusion-evm/pkg/models"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	testContract = common.HexToAddress("0x1000")
+	testTopic    = common.HexToHash("0xdd")
+)
+
+// newTestClaim returns a claim of the log of the transaction at txIndex of a block of three
+// transactions, each emitting one log
+func newTestClaim(t *testing.T, txIndex int) (*models.EVMEventInclusionClaim, *models.EVMEventInclusionClaimVerificationContext) {
+	receipts := types.Receipts{}
+	for i := 0; i < 3; i++ {
+		receipt := &types.Receipt{
+			Type:              types.DynamicFeeTxType,
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(30000 * (i + 1)),
+			Logs: []*types.Log{{
+				Address: testContract,
+				Topics:  []common.Hash{testTopic, common.BigToHash(big.NewInt(int64(i)))},
+				Data:    []byte{byte(i), 1, 2},
+			}},
+		}
+		receipt.Bloom = types.CreateBloom(receipt)
+		receipts = append(receipts, receipt)
+	}
+	header := &types.Header{
+		Number:      big.NewInt(10),
+		Difficulty:  big.NewInt(0),
+		GasLimit:    30000000,
+		GasUsed:     90000,
+		Time:        1700000000,
+		BaseFee:     big.NewInt(7),
+		ReceiptHash: types.DeriveSha(receipts, trie.NewStackTrie(nil)),
+	}
+	_, receiptProof, err := evm.ProveListItem(receipts, txIndex)
+	if err != nil {
+		t.Fatalf("Failed to prove receipt: %v", err)
+	}
+
+	log := receipts[txIndex].Logs[0]
+	claim := &models.EVMEventInclusionClaim{
+		ClaimType:   models.EVMEventInclusionClaimType,
+		Assumptions: abstract_types.NewHeader(header),
+		Action: &models.EVMEvent{
+			TxIndex:  uint64(txIndex),
+			LogIndex: 0,
+			Address:  log.Address,
+			Topics:   log.Topics,
+			Data:     log.Data,
+		},
+		Metadata: abstract_types.EVMMetadata{ChainId: big.NewInt(1)},
+	}
+	return claim, &models.EVMEventInclusionClaimVerificationContext{
+		ReceiptProof: receiptProof,
+	}
+}
+
+func TestVerify(t *testing.T) {
+	claim, verificationContext := newTestClaim(t, 1)
+	err := Verify(claim, verificationContext)
+	if err != nil {
+		t.Fatalf("Failed to verify claim: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(claim *models.EVMEventInclusionClaim)
+		err    string
+	}{
+		{"log data", func(claim *models.EVMEventInclusionClaim) { claim.Action.Data = []byte{0, 1, 2} }, "log data"},
+		{"topic", func(claim *models.EVMEventInclusionClaim) { claim.Action.Topics[1] = common.Hash{} }, "topic 1"},
+		{"emitter", func(claim *models.EVMEventInclusionClaim) { claim.Action.Address = common.HexToAddress("0x01") }, "emitted by"},
+		{"receipt of another transaction", func(claim *models.EVMEventInclusionClaim) { claim.Action.TxIndex = 2 }, "receipt of transaction 2"},
+		{"log out of the receipt", func(claim *models.EVMEventInclusionClaim) { claim.Action.LogIndex = 1 }, "out of the 1 logs"},
+		{"receipts root", func(claim *models.EVMEventInclusionClaim) { claim.Assumptions.ReceiptHash = common.HexToHash("0x01") }, "header hashes"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claim, verificationContext := newTestClaim(t, 1)
+			test.tamper(claim)
+			err := Verify(claim, verificationContext)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("Expected the tampered claim to be rejected with %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package verification
+
+import (
+	"base/pkg/evm"
+	"generation-transaction-inclusion-evm/pkg/models"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Verify validates a transaction inclusion claim: the transaction is proven against the
+// transactions root of the header of the assumptions, has the claimed hash and is signed by the
+// claimed sender
+func Verify(claim *models.EVMTransactionInclusionClaim, verificationContext *models.EVMTransactionInclusionClaimVerificationContext) error {
+	if claim.ClaimType != models.EVMTransactionInclusionClaimType {
+		return errors.Errorf("claim type %q is not %q", claim.ClaimType, models.EVMTransactionInclusionClaimType)
+	}
+
+	// Check the assumptions are the header of the claimed block
+	header := claim.Assumptions
+	err := header.VerifyHash()
+	if err != nil {
+		return err
+	}
+
+	inclusion := claim.Action
+	txBytes, err := evm.VerifyListItemProof(header.TxHash, inclusion.TxIndex, verificationContext.TransactionProof)
+	if err != nil {
+		return errors.Wrapf(err, "transaction %d", inclusion.TxIndex)
+	}
+	tx := new(types.Transaction)
+	err = tx.UnmarshalBinary(txBytes)
+	if err != nil {
+		return errors.Wrapf(err, "invalid encoding of transaction %d", inclusion.TxIndex)
+	}
+	if tx.Hash() != inclusion.TxHash {
+		return errors.Errorf("transaction %d is %s instead of %s", inclusion.TxIndex, tx.Hash(), inclusion.TxHash)
+	}
+
+	// Recover the sender
+	from, err := types.Sender(types.LatestSignerForChainID(claim.Metadata.ChainId), tx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if from != inclusion.From {
+		return errors.Errorf("transaction is signed by %s instead of %s", from, inclusion.From)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package verification
+
+import (
+	"base/pkg/abstract_types"
+	"base/pkg/evm"
+	"generation-transaction-inclusion-evm/pkg/models"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var testChainId = big.NewInt(11155111)
+
+// newTestClaim returns a claim of the transaction at txIndex of a block of three transactions,
+// signed with a legacy, an access list and a dynamic fee transaction type
+func newTestClaim(t *testing.T, txIndex int) (*models.EVMTransactionInclusionClaim, *models.EVMTransactionInclusionClaimVerificationContext) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	to := common.HexToAddress("0x1000")
+	txs := []types.TxData{
+		&types.LegacyTx{Nonce: 0, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(1)},
+		&types.AccessListTx{ChainID: testChainId, Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(2)},
+		&types.DynamicFeeTx{ChainID: testChainId, Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(3)},
+	}
+	signer := types.LatestSignerForChainID(testChainId)
+	transactions := types.Transactions{}
+	for _, txData := range txs {
+		tx, err := types.SignNewTx(key, signer, txData)
+		if err != nil {
+			t.Fatalf("Failed to sign transaction: %v", err)
+		}
+		transactions = append(transactions, tx)
+	}
+
+	header := &types.Header{
+		Number:     big.NewInt(10),
+		Difficulty: big.NewInt(0),
+		GasLimit:   30000000,
+		GasUsed:    63000,
+		Time:       1700000000,
+		BaseFee:    big.NewInt(7),
+		TxHash:     types.DeriveSha(transactions, trie.NewStackTrie(nil)),
+	}
+	_, txProof, err := evm.ProveListItem(transactions, txIndex)
+	if err != nil {
+		t.Fatalf("Failed to prove transaction: %v", err)
+	}
+
+	claim := &models.EVMTransactionInclusionClaim{
+		ClaimType:   models.EVMTransactionInclusionClaimType,
+		Assumptions: abstract_types.NewHeader(header),
+		Action: &models.EVMTransactionInclusion{
+			TxHash:  transactions[txIndex].Hash(),
+			TxIndex: uint64(txIndex),
+			From:    crypto.PubkeyToAddress(key.PublicKey),
+		},
+		Metadata: abstract_types.EVMMetadata{ChainId: testChainId},
+	}
+	return claim, &models.EVMTransactionInclusionClaimVerificationContext{
+		TransactionProof: txProof,
+	}
+}
+
+func TestVerify(t *testing.T) {
+	for txIndex := 0; txIndex < 3; txIndex++ {
+		claim, verificationContext := newTestClaim(t, txIndex)
+		err := Verify(claim, verificationContext)
+		if err != nil {
+			t.Fatalf("Failed to verify the claim of transaction %d: %v", txIndex, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(claim *models.EVMTransactionInclusionClaim)
+		err    string
+	}{
+		{"sender", func(claim *models.EVMTransactionInclusionClaim) { claim.Action.From = common.HexToAddress("0x01") }, "signed by"},
+		{"transaction hash", func(claim *models.EVMTransactionInclusionClaim) { claim.Action.TxHash = common.HexToHash("0x01") }, "transaction 2 is"},
+		{"proof of another transaction", func(claim *models.EVMTransactionInclusionClaim) { claim.Action.TxIndex = 1 }, "transaction 1"},
+		{"chain", func(claim *models.EVMTransactionInclusionClaim) { claim.Metadata.ChainId = big.NewInt(1) }, "invalid chain id"},
+		{"transactions root", func(claim *models.EVMTransactionInclusionClaim) { claim.Assumptions.TxHash = common.HexToHash("0x01") }, "header hashes"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claim, verificationContext := newTestClaim(t, 2)
+			test.tamper(claim)
+			err := Verify(claim, verificationContext)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("Expected the tampered claim to be rejected with %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -12,17 +12,49 @@ import (
 
 // Verify validates a view function claim
 func Verify(claim *models.EVMViewFnClaim, verificationContext *models.EVMViewFnClaimVerificationContext) error {
-	header := claim.Assumptions
-	evmCall := claim.Action
-	result := claim.Result
+	return verifyCall(claim.Assumptions, claim.Action, claim.Result, claim.Metadata, verificationContext, nil, nil)
+}
+
+// VerifyWithOverrides validates a view function claim made under an eth_call override set, applying
+// the overrides of the assumptions to the proven state and block before the call
+func VerifyWithOverrides(claim *models.EVMViewFnOverrideClaim, verificationContext *models.EVMViewFnClaimVerificationContext) error {
+	if claim.ClaimType != models.EVMViewFnOverrideClaimType {
+		return errors.Errorf("claim type %q is not %q", claim.ClaimType, models.EVMViewFnOverrideClaimType)
+	}
+	assumptions := claim.Assumptions
+	return verifyCall(assumptions.Header, claim.Action, claim.Result, claim.Metadata, verificationContext, assumptions.StateOverrides, assumptions.BlockOverrides)
+}
 
-	evm, _, err := evm.CreateEVM(claim.Metadata.ChainId, header.Root, header.ToGethHeader(), verificationContext.Accounts, nil)
+// verifyCall validates the result of a call at the block of a header, under optional overrides
+func verifyCall(header *abstract_types.Header, evmCall *abstract_types.EVMCall, result []byte, metadata abstract_types.EVMMetadata, verificationContext *models.EVMViewFnClaimVerificationContext, stateOverrides abstract_types.StateOverride, blockOverrides *abstract_types.BlockOverrides) error {
+	// Check the assumptions are the header of the claimed block
+	err := header.VerifyHash()
+	if err != nil {
+		return err
+	}
+	gethHeader := header.ToGethHeader()
+
+	// Serve BLOCKHASH from the ancestor headers linked to the assumptions. The ancestors are keyed by
+	// the number of the assumptions, which a block number override would move away from them.
+	if blockOverrides != nil && blockOverrides.Number != nil && len(verificationContext.Ancestors) > 0 {
+		return errors.New("a block number override can't be combined with ancestor headers")
+	}
+	ancestorHashes, err := evm.NewAncestorHashes(header.Number.Uint64(), header.ParentHash, verificationContext.Ancestors)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	evm, _, err := evm.CreateEVMWithOverrides(metadata.ChainId, header.Root, gethHeader, verificationContext.Accounts, verificationContext.Nodes, ancestorHashes.GetHash, stateOverrides, blockOverrides)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
 	// Apply query
-	localOutput, err := callQuery(evm, evmCall, header.GasLimit.Uint64())
+	localOutput, err := callQuery(evm, evmCall, evm.Context.GasLimit)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = ancestorHashes.Err()
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -57,3 +89,40 @@ func compareOutput(output, expectedOutput []byte) error {
 	}
 	return errors.New("output does not match expected output")
 }
+
+// Diagnose re-runs a view function claim which failed verification with tracing hooks, and
+// reports the claimed and computed outputs, the state touched but missing from the account
+// proofs and a summary of the opcodes run
+func Diagnose(claim *models.EVMViewFnClaim, verificationContext *models.EVMViewFnClaimVerificationContext, verificationErr error) *evm.Diagnostics {
+	header := claim.Assumptions
+	diagnostics := &evm.Diagnostics{ExpectedOutput: claim.Result}
+	if verificationErr != nil {
+		diagnostics.Error = verificationErr.Error()
+	}
+
+	ancestorHashes, err := evm.NewAncestorHashes(header.Number.Uint64(), header.ParentHash, verificationContext.Ancestors)
+	if err != nil {
+		diagnostics.ExecutionError = err.Error()
+		return diagnostics
+	}
+	localEVM, _, err := evm.CreateEVM(claim.Metadata.ChainId, header.Root, header.ToGethHeader(), verificationContext.Accounts, verificationContext.Nodes, ancestorHashes.GetHash)
+	if err != nil {
+		diagnostics.ExecutionError = err.Error()
+		return diagnostics
+	}
+
+	tracer := evm.NewDiagnosticTracer()
+	localEVM.Config.Tracer = tracer.Hooks()
+	localOutput, err := callQuery(localEVM, claim.Action, header.GasLimit.Uint64())
+	if err != nil {
+		diagnostics.ExecutionError = err.Error()
+	}
+	diagnostics.ActualOutput = localOutput
+	diagnostics.Trace = tracer.Summary()
+
+	db, err := evm.GenerateMultiProofDB(&abstract_types.MultiProof{Accounts: verificationContext.Accounts, Nodes: verificationContext.Nodes})
+	if err == nil {
+		diagnostics.MissingAccounts, diagnostics.MissingStorage = tracer.MissingState(header.Root, db)
+	}
+	return diagnostics
+}
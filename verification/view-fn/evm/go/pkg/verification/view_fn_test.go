@@ -1,11 +1,24 @@
 package verification
 
 import (
+	"base/pkg/abstract_types"
+	"bytes"
 	"encoding/json"
 	"generation-view-fn-evm/pkg/models"
 	"io"
+	"math/big"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/triedb"
 )
 
 func TestVerify(t *testing.T) {
@@ -50,3 +63,272 @@ func TestVerify(t *testing.T) {
 		t.Fatalf("Failed to validate view function claim: %v", err)
 	}
 }
+
+// blockHashCode returns BLOCKHASH(NUMBER - 2)
+var blockHashCode = hexutil.MustDecode("0x43600290034060005260206000f3")
+
+// blockFeesCode returns BASEFEE and BLOBBASEFEE
+var blockFeesCode = hexutil.MustDecode("0x486000524a60205260406000f3")
+
+// storageCode returns the storage slot 0
+var storageCode = hexutil.MustDecode("0x60005460005260206000f3")
+
+// testHeader returns the header of a Cancun block 100 of a devnet
+func testHeader(parentHash common.Hash) *types.Header {
+	zero := uint64(0)
+	excessBlobGas := uint64(10000000)
+	return &types.Header{
+		ParentHash:       parentHash,
+		UncleHash:        types.EmptyUncleHash,
+		Difficulty:       big.NewInt(0),
+		Number:           big.NewInt(100),
+		GasLimit:         30000000,
+		Time:             1700000000,
+		BaseFee:          big.NewInt(7),
+		WithdrawalsHash:  &types.EmptyWithdrawalsHash,
+		BlobGasUsed:      &zero,
+		ExcessBlobGas:    &excessBlobGas,
+		ParentBeaconRoot: &common.Hash{},
+	}
+}
+
+// newCodeClaim creates a claim calling a contract with the given code at the given header
+func newCodeClaim(t *testing.T, code []byte, header *types.Header, ancestors []*types.Header, result []byte) (*models.EVMViewFnClaim, *models.EVMViewFnClaimVerificationContext) {
+	contract := common.HexToAddress("0x1000")
+	stateDB, err := state.New(types.EmptyRootHash, state.NewDatabase(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil), nil))
+	if err != nil {
+		t.Fatalf("Failed to create state: %v", err)
+	}
+	stateDB.SetCode(contract, code)
+	root, err := stateDB.Commit(0, false, false)
+	if err != nil {
+		t.Fatalf("Failed to commit state: %v", err)
+	}
+	trie, err := stateDB.Database().OpenTrie(root)
+	if err != nil {
+		t.Fatalf("Failed to open state trie: %v", err)
+	}
+	proofDB := memorydb.New()
+	err = trie.Prove(contract.Bytes(), proofDB)
+	if err != nil {
+		t.Fatalf("Failed to prove contract: %v", err)
+	}
+	accountProof := [][]byte{}
+	iterator := proofDB.NewIterator(nil, nil)
+	for iterator.Next() {
+		accountProof = append(accountProof, common.CopyBytes(iterator.Value()))
+	}
+	iterator.Release()
+
+	header.Root = root
+	return &models.EVMViewFnClaim{
+		ClaimType:   "EVMViewFn",
+		Assumptions: abstract_types.NewHeader(header),
+		Action:      &abstract_types.EVMCall{To: contract},
+		Result:      result,
+		Metadata:    abstract_types.EVMMetadata{ChainId: big.NewInt(31337)},
+	}, &models.EVMViewFnClaimVerificationContext{
+		Accounts: []abstract_types.Account{{
+			Proof: abstract_types.AccountProof{
+				Addr:         contract,
+				AccountProof: accountProof,
+				Balance:      big.NewInt(0),
+				Nonce:        big.NewInt(0),
+				CodeHash:     crypto.Keccak256Hash(code),
+				StorageHash:  types.EmptyRootHash,
+			},
+			Code: code,
+		}},
+		Ancestors: ancestors,
+	}
+}
+
+func TestVerifyBlockHash(t *testing.T) {
+	parent := &types.Header{ParentHash: common.HexToHash("0x98"), Number: big.NewInt(99), Difficulty: big.NewInt(0)}
+
+	claim, verificationContext := newCodeClaim(t, blockHashCode, testHeader(parent.Hash()), []*types.Header{parent}, parent.ParentHash.Bytes())
+	err := Verify(claim, verificationContext)
+	if err != nil {
+		t.Fatalf("Failed to verify a claim using BLOCKHASH: %v", err)
+	}
+
+	// Wrong block hash
+	claim, verificationContext = newCodeClaim(t, blockHashCode, testHeader(parent.Hash()), []*types.Header{parent}, make([]byte, 32))
+	if Verify(claim, verificationContext) == nil {
+		t.Fatalf("Expected a wrong block hash to be rejected")
+	}
+	// Ancestor not linked to the assumptions
+	claim, verificationContext = newCodeClaim(t, blockHashCode, testHeader(common.HexToHash("0x99")), []*types.Header{parent}, parent.ParentHash.Bytes())
+	if Verify(claim, verificationContext) == nil {
+		t.Fatalf("Expected an ancestor not linked to the assumptions to be rejected")
+	}
+	// Block hash not covered by the ancestors
+	claim, verificationContext = newCodeClaim(t, blockHashCode, testHeader(parent.Hash()), nil, make([]byte, 32))
+	if Verify(claim, verificationContext) == nil {
+		t.Fatalf("Expected a block hash not covered by the ancestors to be rejected")
+	}
+}
+
+func TestVerifyBlockFees(t *testing.T) {
+	header := testHeader(common.HexToHash("0x99"))
+	// The blob base fee of an excess blob gas of 10000000 under the Cancun update fraction
+	expected := append(common.BigToHash(big.NewInt(7)).Bytes(), common.BigToHash(big.NewInt(19)).Bytes()...)
+
+	claim, verificationContext := newCodeClaim(t, blockFeesCode, header, nil, expected)
+	err := Verify(claim, verificationContext)
+	if err != nil {
+		t.Fatalf("Failed to verify a claim using BASEFEE and BLOBBASEFEE: %v", err)
+	}
+
+	// Header not matching the claimed block hash
+	claim, verificationContext = newCodeClaim(t, blockFeesCode, header, nil, expected)
+	claim.Assumptions.BaseFee = big.NewInt(8)
+	claim.Result = append(common.BigToHash(big.NewInt(8)).Bytes(), expected[32:]...)
+	if Verify(claim, verificationContext) == nil {
+		t.Fatalf("Expected a header not matching the claimed block hash to be rejected")
+	}
+}
+
+func TestVerifyMultiProof(t *testing.T) {
+	var claim models.EVMViewFnClaim
+	var verificationContext models.EVMViewFnClaimVerificationContext
+	for path, value := range map[string]any{
+		"./view_fn_test_mock_claim.json":                &claim,
+		"./view_fn_test_mock_verification_context.json": &verificationContext,
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		err = json.Unmarshal(data, value)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", path, err)
+		}
+	}
+
+	// The multiproof survives the ABI encoding, and is smaller than the full proofs
+	fullEncoding, err := verificationContext.AbiEncode()
+	if err != nil {
+		t.Fatalf("Failed to encode verification context: %v", err)
+	}
+	multiProof := abstract_types.NewMultiProof(verificationContext.Accounts)
+	compact := &models.EVMViewFnClaimVerificationContext{Accounts: multiProof.Accounts, Nodes: multiProof.Nodes}
+	compactEncoding, err := compact.AbiEncode()
+	if err != nil {
+		t.Fatalf("Failed to encode multiproof: %v", err)
+	}
+	if len(compactEncoding) >= len(fullEncoding) {
+		t.Fatalf("Expected the multiproof encoding to be smaller, got %d bytes instead of %d", len(compactEncoding), len(fullEncoding))
+	}
+	decoded, err := models.AbiDecodeEVMViewFnClaimVerificationContext(compactEncoding)
+	if err != nil {
+		t.Fatalf("Failed to decode multiproof: %v", err)
+	}
+
+	err = Verify(&claim, decoded)
+	if err != nil {
+		t.Fatalf("Failed to validate view function claim with a multiproof: %v", err)
+	}
+	decoded.Nodes = decoded.Nodes[1:]
+	if Verify(&claim, decoded) == nil {
+		t.Fatalf("Expected a multiproof missing a node to be rejected")
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	claimed := common.HexToHash("0x01").Bytes()
+	claim, verificationContext := newCodeClaim(t, storageCode, testHeader(common.HexToHash("0x99")), nil, claimed)
+	err := Verify(claim, verificationContext)
+	if err == nil {
+		t.Fatalf("Expected a wrong output to be rejected")
+	}
+
+	diagnostics := Diagnose(claim, verificationContext, err)
+	if diagnostics.Error != err.Error() || diagnostics.ExecutionError != "" {
+		t.Fatalf("Unexpected errors in diagnostics: %s", diagnostics)
+	}
+	if !bytes.Equal(diagnostics.ExpectedOutput, claimed) || !bytes.Equal(diagnostics.ActualOutput, make([]byte, 32)) {
+		t.Fatalf("Unexpected outputs in diagnostics: %s", diagnostics)
+	}
+	if diagnostics.Trace.Calls != 1 || diagnostics.Trace.Opcodes["SLOAD"] != 1 || diagnostics.Trace.Tail[len(diagnostics.Trace.Tail)-1] != "1:10:RETURN" {
+		t.Fatalf("Unexpected trace in diagnostics: %s", diagnostics)
+	}
+	if len(diagnostics.MissingAccounts) != 0 || len(diagnostics.MissingStorage) != 0 {
+		t.Fatalf("Expected the touched state to be proven: %s", diagnostics)
+	}
+}
+
+func TestVerifyWithOverrides(t *testing.T) {
+	overridden := common.HexToHash("0x2a")
+	claim, verificationContext := newCodeClaim(t, storageCode, testHeader(common.HexToHash("0x99")), nil, overridden.Bytes())
+	contract := claim.Action.To
+	overrideClaim := &models.EVMViewFnOverrideClaim{
+		ClaimType: models.EVMViewFnOverrideClaimType,
+		Assumptions: &models.EVMViewFnOverrideAssumptions{
+			Header: claim.Assumptions,
+			StateOverrides: abstract_types.StateOverride{
+				contract: {StateDiff: map[common.Hash]common.Hash{{}: overridden}},
+			},
+		},
+		Action:   claim.Action,
+		Result:   claim.Result,
+		Metadata: claim.Metadata,
+	}
+	if Verify(claim, verificationContext) == nil {
+		t.Fatalf("Expected the overridden result to be rejected without the overrides")
+	}
+	err := VerifyWithOverrides(overrideClaim, verificationContext)
+	if err != nil {
+		t.Fatalf("Failed to verify a claim with a storage override: %v", err)
+	}
+	overrideClaim.Assumptions.StateOverrides = abstract_types.StateOverride{
+		contract: {State: map[common.Hash]common.Hash{{}: overridden}},
+	}
+	err = VerifyWithOverrides(overrideClaim, verificationContext)
+	if err != nil {
+		t.Fatalf("Failed to verify a claim with a replaced storage: %v", err)
+	}
+
+	// Replaced code and base fee
+	code := hexutil.Bytes(blockFeesCode)
+	baseFee := hexutil.Big(*big.NewInt(9))
+	overrideClaim.Assumptions.StateOverrides = abstract_types.StateOverride{contract: {Code: &code}}
+	overrideClaim.Assumptions.BlockOverrides = &abstract_types.BlockOverrides{BaseFeePerGas: &baseFee}
+	overrideClaim.Result = append(common.BigToHash(big.NewInt(9)).Bytes(), common.BigToHash(big.NewInt(19)).Bytes()...)
+	err = VerifyWithOverrides(overrideClaim, verificationContext)
+	if err != nil {
+		t.Fatalf("Failed to verify a claim with code and block overrides: %v", err)
+	}
+
+	overrideClaim.Assumptions.StateOverrides = abstract_types.StateOverride{contract: {
+		State:     map[common.Hash]common.Hash{},
+		StateDiff: map[common.Hash]common.Hash{},
+	}}
+	if VerifyWithOverrides(overrideClaim, verificationContext) == nil {
+		t.Fatalf("Expected an override of both state and stateDiff to be rejected")
+	}
+}
+
+func TestVerifyWithNumberOverride(t *testing.T) {
+	parent := &types.Header{ParentHash: common.HexToHash("0x98"), Number: big.NewInt(99), Difficulty: big.NewInt(0)}
+	claim, verificationContext := newCodeClaim(t, blockHashCode, testHeader(parent.Hash()), []*types.Header{parent}, parent.ParentHash.Bytes())
+	overrideClaim := &models.EVMViewFnOverrideClaim{
+		ClaimType:   models.EVMViewFnOverrideClaimType,
+		Assumptions: &models.EVMViewFnOverrideAssumptions{Header: claim.Assumptions},
+		Action:      claim.Action,
+		Result:      claim.Result,
+		Metadata:    claim.Metadata,
+	}
+	err := VerifyWithOverrides(overrideClaim, verificationContext)
+	if err != nil {
+		t.Fatalf("Failed to verify a claim using BLOCKHASH without overrides: %v", err)
+	}
+
+	// The ancestors are keyed by the number of the assumptions, not by an overridden number
+	number := hexutil.Big(*big.NewInt(101))
+	overrideClaim.Assumptions.BlockOverrides = &abstract_types.BlockOverrides{Number: &number}
+	err = VerifyWithOverrides(overrideClaim, verificationContext)
+	if err == nil || !strings.Contains(err.Error(), "block number override") {
+		t.Fatalf("Expected a block number override with ancestors to be rejected, got %v", err)
+	}
+}